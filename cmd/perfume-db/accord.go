@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+type createAccordRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description"`
+}
+
+type updateAccordRequest struct {
+	Name        Optional[string] `json:"name" validate:"omitnil,required,max=100"`
+	Description Optional[string] `json:"description"`
+}
+
+type noteAccordRequest struct {
+	AccordId string `json:"accord_id" validate:"required"`
+	Weight   int    `json:"weight" validate:"gte=1,lte=100"`
+}
+
+type setNoteAccordsRequest struct {
+	Accords []noteAccordRequest `json:"accords" validate:"dive"`
+}
+
+func (app *application) listAccordsHandler(w http.ResponseWriter, r *http.Request) {
+	cursor := r.URL.Query().Get("cursor")
+	var id = 0
+
+	if cursor != "" {
+		decrypted, err := app.Decrypt(cursor)
+		if err == nil {
+			id, _ = strconv.Atoi(string(decrypted))
+		}
+	}
+
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 || perPage > 100 {
+		perPage = 25
+	}
+
+	accords, err := app.services.Accord.List(id, perPage)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	var newCursor string
+	if len(accords) == perPage {
+		lastAccord := accords[len(accords)-1]
+		newCursor, _ = app.Encrypt([]byte(strconv.Itoa(lastAccord.ID)))
+	}
+
+	res := Paginated[internal.Accord]{
+		Data: accords,
+		Next: newCursor,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+func (app *application) showAccordBySlugHandler(w http.ResponseWriter, r *http.Request) {
+	accord, err := app.services.Accord.FindBySlug(r.PathValue("slug"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	app.JSONResponse(w, accord, http.StatusOK, nil)
+}
+
+func (app *application) createAccordHandler(w http.ResponseWriter, r *http.Request) {
+	var req createAccordRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	accord, err := app.factory.NewAccord(req.Name, req.Description)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	if err := app.services.Accord.Save(accord); err != nil {
+		app.accordSaveError(w, err)
+		return
+	}
+
+	app.JSONResponse(w, accord, http.StatusCreated, nil)
+}
+
+func (app *application) updateAccordHandler(w http.ResponseWriter, r *http.Request) {
+	var req updateAccordRequest
+
+	accord, err := app.services.Accord.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if !app.decodeMergePatch(w, r, &req) {
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	if req.Name.Set {
+		accord.Name = req.Name.Value
+		accord.Slug = internal.CreateSlug(req.Name.Value)
+	}
+
+	if req.Description.Set {
+		accord.Description = req.Description.Value
+	}
+
+	if err := app.services.Accord.Save(accord); err != nil {
+		app.accordSaveError(w, err)
+		return
+	}
+
+	app.JSONResponse(w, accord, http.StatusOK, nil)
+}
+
+func (app *application) accordSaveError(w http.ResponseWriter, err error) {
+	if errors.Is(err, postgresql.ErrAccordAlreadyExists) {
+		app.JSONResponse(w, ResponseMessage{Message: "Accord already exists.", StatusCode: http.StatusUnprocessableEntity}, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	app.logger.Error(err.Error())
+	app.ServerError(w)
+}
+
+func (app *application) deleteAccordHandler(w http.ResponseWriter, r *http.Request) {
+	err := app.services.Accord.Delete(r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrAccordNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) listNoteAccordsHandler(w http.ResponseWriter, r *http.Request) {
+	note, err := app.services.Note.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	accords, err := app.services.Accord.NoteAccords(note.PublicId)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	res := struct {
+		Data []internal.NoteAccord `json:"data"`
+	}{
+		Data: accords,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+// setNoteAccordsHandler replaces the accords a note expresses. The accord
+// profiles of the perfumes with the note are recomputed right away.
+func (app *application) setNoteAccordsHandler(w http.ResponseWriter, r *http.Request) {
+	var req setNoteAccordsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	seen := make(map[string]bool, len(req.Accords))
+	accords := make([]internal.NoteAccord, 0, len(req.Accords))
+	for _, accord := range req.Accords {
+		if seen[accord.AccordId] {
+			res := NewValidationErrors()
+			res.AddError("accords", "Each accord may only be given once.")
+			app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+			return
+		}
+
+		seen[accord.AccordId] = true
+		accords = append(accords, internal.NoteAccord{AccordId: accord.AccordId, Weight: accord.Weight})
+	}
+
+	err := app.services.Accord.SetNoteAccords(r.PathValue("publicId"), accords)
+
+	switch {
+	case err == nil:
+		app.listNoteAccordsHandler(w, r)
+	case errors.Is(err, postgresql.ErrNoteNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrAccordNotFound):
+		res := NewValidationErrors()
+		res.AddError("accords", "One or more accords do not exist.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
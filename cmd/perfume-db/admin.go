@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+type updateUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=viewer contributor editor admin"`
+}
+
+func (app *application) listUsersHandler(w http.ResponseWriter, r *http.Request) {
+	cursor := r.URL.Query().Get("cursor")
+	var id = 0
+
+	if cursor != "" {
+		decrypted, err := app.Decrypt(cursor)
+		if err == nil {
+			id, _ = strconv.Atoi(string(decrypted))
+		}
+	}
+
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 || perPage > 100 {
+		perPage = 25
+	}
+
+	users, err := app.services.User.List(id, perPage)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	var newCursor string
+	if len(users) == perPage {
+		lastUser := users[len(users)-1]
+		newCursor, _ = app.Encrypt([]byte(strconv.Itoa(lastUser.ID)))
+	}
+
+	res := Paginated[internal.User]{
+		Data: users,
+		Next: newCursor,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+func (app *application) updateUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var req updateUserRoleRequest
+
+	user, err := app.services.User.Find(r.PathValue("publicId"))
+	if err != nil {
+		if errors.Is(err, postgresql.ErrUserNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	// An admin demoting themselves could leave the catalogue without admins.
+	if user.ID == app.contextGetUser(r).ID {
+		app.JSONResponse(w, ResponseMessage{Message: "You cannot change your own role.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+		return
+	}
+
+	user.Role, _ = internal.RoleFromString(req.Role)
+
+	if err := app.services.User.Save(user); err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.JSONResponse(w, user, http.StatusOK, nil)
+}
+
+// promoteAdmin gives the user with the email the admin role. Registered
+// users start without it and role changes otherwise need an admin, so this is
+// the only way the first admin is made.
+func (app *application) promoteAdmin(email string) error {
+	user, err := app.services.User.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	user.Role = internal.RoleAdmin
+
+	if err := app.services.User.Save(user); err != nil {
+		return err
+	}
+
+	app.logger.Info("PROMOTED TO ADMIN", "USER", user.PublicId)
+
+	return nil
+}
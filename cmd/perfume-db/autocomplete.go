@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ej-agas/perfume-db/internal"
+)
+
+type autocompleteRequest struct {
+	Q         string  `validate:"required"`
+	Limit     int     `validate:"gte=1,lte=20"`
+	Threshold float64 `validate:"gte=0,lte=1"`
+}
+
+func (app *application) autocompleteHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	validationErrors := NewValidationErrors()
+
+	req := autocompleteRequest{Q: strings.TrimSpace(query.Get("q")), Limit: 5, Threshold: 0.3}
+
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		if req.Limit, err = strconv.Atoi(limit); err != nil {
+			validationErrors.AddError("limit", "The limit field must be a number.")
+		}
+	}
+
+	if threshold := query.Get("threshold"); threshold != "" {
+		var err error
+		if req.Threshold, err = strconv.ParseFloat(threshold, 64); err != nil {
+			validationErrors.AddError("threshold", "The threshold field must be a number.")
+		}
+	}
+
+	types := []internal.EntityType{
+		internal.PerfumeEntity,
+		internal.HouseEntity,
+		internal.PerfumerEntity,
+		internal.NoteEntity,
+		internal.NoteGroupEntity,
+	}
+
+	if rawTypes := query.Get("types"); rawTypes != "" {
+		types = types[:0]
+		for _, rawType := range strings.Split(rawTypes, ",") {
+			entityType, err := internal.EntityTypeFromString(strings.TrimSpace(rawType))
+			if err != nil {
+				validationErrors.AddError("types", "The selected types is invalid.")
+				break
+			}
+
+			types = append(types, entityType)
+		}
+	}
+
+	if len(validationErrors.Errors) > 0 {
+		app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	items, err := app.services.Autocomplete.Suggest(req.Q, types, req.Limit, req.Threshold)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	res := struct {
+		Data []internal.AutocompleteItem `json:"data"`
+	}{
+		Data: items,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
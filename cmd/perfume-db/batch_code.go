@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal/batchcode"
+)
+
+type decodeBatchCodeRequest struct {
+	Code string `json:"code" validate:"required,max=20"`
+}
+
+type decodedBatchCode struct {
+	HouseId    string              `json:"house_id"`
+	Code       string              `json:"code"`
+	ProducedOn string              `json:"produced_on"`
+	Precision  batchcode.Precision `json:"precision"`
+	Factory    string              `json:"factory"`
+}
+
+// decodeBatchCodeHandler dates a bottle of the house from its batch code,
+// using the decoder registered for the house.
+func (app *application) decodeBatchCodeHandler(w http.ResponseWriter, r *http.Request) {
+	var req decodeBatchCodeRequest
+
+	house, err := app.services.House.FindBySlug(r.PathValue("slug"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	production, err := app.batchCodes.Decode(house.PublicId, req.Code, time.Now().UTC())
+
+	switch {
+	case err == nil:
+		res := decodedBatchCode{
+			HouseId:    house.PublicId,
+			Code:       req.Code,
+			ProducedOn: production.Date.Format("2006-01-02"),
+			Precision:  production.Precision,
+			Factory:    production.Factory,
+		}
+
+		app.JSONResponse(w, res, http.StatusOK, nil)
+	case errors.Is(err, batchcode.ErrNoDecoder):
+		app.JSONResponse(w, ResponseMessage{Message: "Batch codes of this house cannot be decoded.", StatusCode: http.StatusNotFound}, http.StatusNotFound, nil)
+	case errors.Is(err, batchcode.ErrUnrecognizedCode):
+		res := NewValidationErrors()
+		res.AddError("code", "The code is not a batch code of this house.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
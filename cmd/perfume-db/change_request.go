@@ -0,0 +1,390 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+var errInvalidChangeRequest = errors.New("change request patch is invalid")
+
+type createChangeRequestRequest struct {
+	Patch  json.RawMessage `json:"patch" validate:"required"`
+	Reason string          `json:"reason" validate:"required"`
+}
+
+type rejectChangeRequestRequest struct {
+	Comment string `json:"comment" validate:"required"`
+}
+
+type changeRequestResponse struct {
+	*internal.ChangeRequest
+	Diff []internal.FieldChange `json:"diff"`
+}
+
+// decodeStoredPatch unmarshals and validates a merge patch that does not come
+// from the request body.
+func (app *application) decodeStoredPatch(patch json.RawMessage, dst any) *ValidationErrors {
+	if err := json.Unmarshal(patch, dst); err != nil {
+		res := NewValidationErrors()
+		res.AddError("patch", "The patch field must be a valid merge patch object.")
+		return res
+	}
+
+	if err := app.validator.Struct(dst); err != nil {
+		return CreateResponseFromErrors(err)
+	}
+
+	return nil
+}
+
+// patchEntity loads an entity and applies a merge patch to it through the same
+// code path as its PATCH endpoint. It returns the entity as stored and the
+// patched entity, which is not saved.
+func (app *application) patchEntity(services *postgresql.Services, entityType internal.EntityType, entityId string, patch json.RawMessage) (any, any, *ValidationErrors, error) {
+	switch entityType {
+	case internal.PerfumeEntity:
+		var req updatePerfumeRequest
+		if res := app.decodeStoredPatch(patch, &req); res != nil {
+			return nil, nil, res, nil
+		}
+
+		current, err := services.Perfume.Find(entityId)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		// The perfume is loaded twice since patching mutates its relations.
+		patched, err := services.Perfume.Find(entityId)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		validationErrors, err := app.applyPerfumePatch(services, patched, req)
+
+		return current, patched, validationErrors, err
+	case internal.HouseEntity:
+		var req updateHouseRequest
+		if res := app.decodeStoredPatch(patch, &req); res != nil {
+			return nil, nil, res, nil
+		}
+
+		current, err := services.House.Find(entityId)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		patched := *current
+		applyHousePatch(&patched, req)
+
+		return current, &patched, nil, nil
+	case internal.PerfumerEntity:
+		var req updatePerfumerRequest
+		if res := app.decodeStoredPatch(patch, &req); res != nil {
+			return nil, nil, res, nil
+		}
+
+		current, err := services.Perfumer.Find(entityId)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		patched := *current
+		validationErrors := applyPerfumerPatch(&patched, req)
+
+		return current, &patched, validationErrors, nil
+	}
+
+	return nil, nil, nil, fmt.Errorf("change requests are not supported for %s", entityType)
+}
+
+func isEntityNotFound(err error) bool {
+	return errors.Is(err, postgresql.ErrPerfumeNotFound) ||
+		errors.Is(err, postgresql.ErrHouseNotFound) ||
+		errors.Is(err, postgresql.ErrPerfumerNotFound)
+}
+
+func (app *application) createChangeRequestHandler(entityType internal.EntityType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req createChangeRequestRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			app.logger.Error(err.Error())
+			app.BadRequest(w)
+			return
+		}
+
+		if err := app.validator.Struct(req); err != nil {
+			res := CreateResponseFromErrors(err)
+			app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+			return
+		}
+
+		current, patched, validationErrors, err := app.patchEntity(app.services, entityType, r.PathValue("publicId"), req.Patch)
+		if validationErrors != nil {
+			app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
+			return
+		}
+
+		if err != nil {
+			if isEntityNotFound(err) {
+				app.NoContent(w, http.StatusNotFound)
+				return
+			}
+
+			app.logger.Error(err.Error())
+			app.ServerError(w)
+			return
+		}
+
+		diff, err := internal.Diff(current, patched)
+		if err != nil {
+			app.logger.Error(err.Error())
+			app.ServerError(w)
+			return
+		}
+
+		if len(diff) == 0 {
+			res := NewValidationErrors()
+			res.AddError("patch", "The patch field must change at least one field.")
+			app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+			return
+		}
+
+		changeRequest, err := app.factory.NewChangeRequest(entityType, r.PathValue("publicId"), req.Patch, req.Reason, app.contextGetUser(r))
+		if err != nil {
+			app.logger.Error(err.Error())
+			app.ServerError(w)
+			return
+		}
+
+		if err := app.services.ChangeRequest.Save(changeRequest); err != nil {
+			app.logger.Error(err.Error())
+			app.ServerError(w)
+			return
+		}
+
+		app.JSONResponse(w, changeRequestResponse{ChangeRequest: changeRequest, Diff: diff}, http.StatusCreated, nil)
+	}
+}
+
+func (app *application) listChangeRequests(w http.ResponseWriter, r *http.Request, filter internal.ChangeRequestFilter) {
+	cursor := r.URL.Query().Get("cursor")
+	var id = 0
+
+	if cursor != "" {
+		decrypted, err := app.Decrypt(cursor)
+		if err == nil {
+			id, _ = strconv.Atoi(string(decrypted))
+		}
+	}
+
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 || perPage > 100 {
+		perPage = 25
+	}
+
+	changeRequests, err := app.services.ChangeRequest.List(filter, id, perPage)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	var newCursor string
+	if len(changeRequests) == perPage {
+		lastChangeRequest := changeRequests[len(changeRequests)-1]
+		newCursor, _ = app.Encrypt([]byte(strconv.Itoa(lastChangeRequest.ID)))
+	}
+
+	res := Paginated[internal.ChangeRequest]{
+		Data: changeRequests,
+		Next: newCursor,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+// listChangeRequestsHandler is the editor queue. It lists pending change
+// requests unless another status is requested.
+func (app *application) listChangeRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	filter := internal.ChangeRequestFilter{Status: internal.ChangeRequestPending}
+
+	if value := r.URL.Query().Get("status"); value != "" {
+		status, err := internal.ChangeRequestStatusFromString(value)
+		if err != nil {
+			res := NewValidationErrors()
+			res.AddError("status", "The selected status is invalid.")
+			app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+			return
+		}
+
+		filter.Status = status
+	}
+
+	app.listChangeRequests(w, r, filter)
+}
+
+func (app *application) listEntityChangeRequestsHandler(entityType internal.EntityType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app.listChangeRequests(w, r, internal.ChangeRequestFilter{EntityType: entityType, EntityId: r.PathValue("publicId")})
+	}
+}
+
+func (app *application) listOwnChangeRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	app.listChangeRequests(w, r, internal.ChangeRequestFilter{ProposerId: app.contextGetUser(r).ID})
+}
+
+// showChangeRequestHandler shows a change request to its proposer and to
+// editors, with the field-level diff of the patch against the entity as it
+// is stored now.
+func (app *application) showChangeRequestHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	changeRequest, err := app.services.ChangeRequest.Find(r.PathValue("publicId"))
+	if err != nil || (changeRequest.ProposerId != user.ID && !user.Can(internal.RoleEditor)) {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	res := changeRequestResponse{ChangeRequest: changeRequest}
+
+	// Reviewed change requests have no meaningful diff against the current
+	// entity, and a pending one may no longer apply cleanly.
+	if changeRequest.Status == internal.ChangeRequestPending {
+		current, patched, validationErrors, err := app.patchEntity(app.services, changeRequest.EntityType, changeRequest.EntityId, changeRequest.Patch)
+		if err == nil && validationErrors == nil {
+			res.Diff, err = internal.Diff(current, patched)
+			if err != nil {
+				app.logger.Error(err.Error())
+			}
+		}
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+// acceptChangeRequestHandler applies the patch of a change request to the
+// current entity and saves it in the same transaction that marks the change
+// request as accepted.
+func (app *application) acceptChangeRequestHandler(w http.ResponseWriter, r *http.Request) {
+	var changeRequest *internal.ChangeRequest
+	var validationErrors *ValidationErrors
+
+	reviewer := app.contextGetUser(r)
+
+	err := app.services.Transaction(func(tx *postgresql.Services) error {
+		var err error
+
+		changeRequest, err = tx.ChangeRequest.Find(r.PathValue("publicId"))
+		if err != nil {
+			return err
+		}
+
+		if changeRequest.Status != internal.ChangeRequestPending {
+			return internal.ErrChangeRequestNotPending
+		}
+
+		var patched any
+		_, patched, validationErrors, err = app.patchEntity(tx, changeRequest.EntityType, changeRequest.EntityId, changeRequest.Patch)
+		if err != nil {
+			return err
+		}
+
+		if validationErrors != nil {
+			return errInvalidChangeRequest
+		}
+
+		meta := app.changeMeta(r)
+		if meta.Reason == "" {
+			meta.Reason = fmt.Sprintf("Change request %s by %s: %s", changeRequest.PublicId, changeRequest.Proposer, changeRequest.Reason)
+		}
+
+		switch entity := patched.(type) {
+		case *internal.Perfume:
+			err = tx.Perfume.Save(entity, meta)
+		case *internal.House:
+			err = tx.House.Save(entity, meta)
+		case *internal.Perfumer:
+			err = tx.Perfumer.Save(entity, meta)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := changeRequest.Accept(reviewer); err != nil {
+			return err
+		}
+
+		return tx.ChangeRequest.Review(changeRequest)
+	})
+
+	switch {
+	case err == nil:
+		app.JSONResponse(w, changeRequest, http.StatusOK, nil)
+	case errors.Is(err, postgresql.ErrChangeRequestNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, internal.ErrChangeRequestNotPending):
+		app.JSONResponse(w, ResponseMessage{Message: "Change request has already been reviewed.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	case errors.Is(err, errInvalidChangeRequest):
+		app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
+	case isEntityNotFound(err):
+		app.JSONResponse(w, ResponseMessage{Message: "The entity of the change request no longer exists.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	case errors.Is(err, postgresql.ErrPerfumeAlreadyExists),
+		errors.Is(err, postgresql.ErrHouseAlreadyExists),
+		errors.Is(err, postgresql.ErrPerfumerAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "The change request conflicts with an existing entity.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) rejectChangeRequestHandler(w http.ResponseWriter, r *http.Request) {
+	var req rejectChangeRequestRequest
+
+	changeRequest, err := app.services.ChangeRequest.Find(r.PathValue("publicId"))
+	if err != nil {
+		if errors.Is(err, postgresql.ErrChangeRequestNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	err = changeRequest.Reject(app.contextGetUser(r), req.Comment)
+	if err == nil {
+		err = app.services.ChangeRequest.Review(changeRequest)
+	}
+
+	switch {
+	case err == nil:
+		app.JSONResponse(w, changeRequest, http.StatusOK, nil)
+	case errors.Is(err, internal.ErrChangeRequestNotPending):
+		app.JSONResponse(w, ResponseMessage{Message: "Change request has already been reviewed.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
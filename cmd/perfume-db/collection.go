@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+type createCollectionItemRequest struct {
+	Shelf        string   `json:"shelf" validate:"required,oneof=owned wishlist tried sold"`
+	PerfumeId    string   `json:"perfume_id" validate:"required"`
+	BottleSizeMl *int     `json:"bottle_size_ml" validate:"omitnil,gte=1,lte=10000"`
+	PurchaseDate string   `json:"purchase_date" validate:"omitempty,ymd-date-format"`
+	Price        *float64 `json:"price" validate:"omitnil,gte=0"`
+	Currency     string   `json:"currency" validate:"omitempty,iso4217"`
+	Notes        string   `json:"notes" validate:"max=5000"`
+}
+
+type updateCollectionItemRequest struct {
+	Shelf        Optional[string]  `json:"shelf" validate:"omitnil,required,oneof=owned wishlist tried sold"`
+	BottleSizeMl Nullable[int]     `json:"bottle_size_ml" validate:"omitnil,gte=1,lte=10000"`
+	PurchaseDate Optional[string]  `json:"purchase_date" validate:"omitempty,ymd-date-format"`
+	Price        Optional[float64] `json:"price" validate:"omitempty,gte=0"`
+	Currency     Optional[string]  `json:"currency" validate:"omitempty,iso4217"`
+	Notes        Optional[string]  `json:"notes" validate:"omitempty,max=5000"`
+}
+
+type setShelfVisibilityRequest struct {
+	Public *bool `json:"public" validate:"required"`
+}
+
+// parseOptionalDate parses an already validated YYYY-MM-DD date, where an
+// empty value means no date.
+func parseOptionalDate(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+
+	date, _ := time.Parse("2006-01-02", value)
+	return &date
+}
+
+func (app *application) createCollectionItemHandler(w http.ResponseWriter, r *http.Request) {
+	var req createCollectionItemRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	shelf, _ := internal.ShelfFromString(req.Shelf)
+
+	perfume, err := app.services.Perfume.Find(req.PerfumeId)
+	if err != nil {
+		if errors.Is(err, postgresql.ErrPerfumeNotFound) {
+			res := NewValidationErrors()
+			res.AddError("perfume_id", "Perfume does not exist.")
+			app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	item, err := app.factory.NewCollectionItem(app.contextGetUser(r), shelf, perfume.PublicId)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	item.Perfume = perfume
+	item.BottleSizeMl = req.BottleSizeMl
+	item.PurchaseDate = parseOptionalDate(req.PurchaseDate)
+	item.Price = req.Price
+	item.Currency = req.Currency
+	item.Notes = req.Notes
+
+	if err := app.services.Collection.Save(item); err != nil {
+		app.collectionItemSaveError(w, err)
+		return
+	}
+
+	app.JSONResponse(w, item, http.StatusCreated, nil)
+}
+
+func (app *application) collectionItemSaveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, postgresql.ErrCollectionItemAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "Perfume is already on this shelf.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	case errors.Is(err, postgresql.ErrPerfumeNotFound):
+		res := NewValidationErrors()
+		res.AddError("perfume_id", "Perfume does not exist.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) showCollectionItemHandler(w http.ResponseWriter, r *http.Request) {
+	item, err := app.services.Collection.Find(app.contextGetUser(r).ID, r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	app.JSONResponse(w, item, http.StatusOK, nil)
+}
+
+func (app *application) updateCollectionItemHandler(w http.ResponseWriter, r *http.Request) {
+	var req updateCollectionItemRequest
+
+	item, err := app.services.Collection.Find(app.contextGetUser(r).ID, r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if !app.decodeMergePatch(w, r, &req) {
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	if req.Shelf.Set {
+		item.Shelf, _ = internal.ShelfFromString(req.Shelf.Value)
+	}
+
+	item.BottleSizeMl = optionalPointer(req.BottleSizeMl.Optional, item.BottleSizeMl)
+
+	if req.PurchaseDate.Set {
+		item.PurchaseDate = parseOptionalDate(req.PurchaseDate.Value)
+	}
+
+	item.Price = optionalPointer(req.Price, item.Price)
+
+	if req.Currency.Set {
+		item.Currency = req.Currency.Value
+	}
+
+	if req.Notes.Set {
+		item.Notes = req.Notes.Value
+	}
+
+	if err := app.services.Collection.Save(item); err != nil {
+		app.collectionItemSaveError(w, err)
+		return
+	}
+
+	app.JSONResponse(w, item, http.StatusOK, nil)
+}
+
+func (app *application) deleteCollectionItemHandler(w http.ResponseWriter, r *http.Request) {
+	item, err := app.services.Collection.Find(app.contextGetUser(r).ID, r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if err := app.services.Collection.Delete(item); err != nil {
+		if errors.Is(err, postgresql.ErrCollectionItemNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.NoContent(w, http.StatusNoContent)
+}
+
+// listCollection lists the collection of a user, optionally narrowed down to
+// a single shelf with the "shelf" query parameter.
+func (app *application) listCollection(w http.ResponseWriter, r *http.Request, filter internal.CollectionFilter) {
+	query := r.URL.Query()
+
+	if value := query.Get("shelf"); value != "" {
+		shelf, err := internal.ShelfFromString(value)
+		if err != nil {
+			res := NewValidationErrors()
+			res.AddError("shelf", "The selected shelf is invalid.")
+			app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+			return
+		}
+
+		filter.Shelf = shelf
+	}
+
+	cursor := query.Get("cursor")
+	var id = 0
+
+	if cursor != "" {
+		decrypted, err := app.Decrypt(cursor)
+		if err == nil {
+			id, _ = strconv.Atoi(string(decrypted))
+		}
+	}
+
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage <= 0 || perPage > 100 {
+		perPage = 25
+	}
+
+	items, err := app.services.Collection.List(filter, id, perPage)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	var newCursor string
+	if len(items) == perPage {
+		lastItem := items[len(items)-1]
+		newCursor, _ = app.Encrypt([]byte(strconv.Itoa(lastItem.ID)))
+	}
+
+	res := Paginated[internal.CollectionItem]{
+		Data: items,
+		Next: newCursor,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+func (app *application) listOwnCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	app.listCollection(w, r, internal.CollectionFilter{UserId: app.contextGetUser(r).ID})
+}
+
+// showUserCollectionHandler lists the public shelves of a user. Owners see
+// their private shelves as well.
+func (app *application) showUserCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	owner, err := app.services.User.FindByUsername(r.PathValue("username"))
+	if err != nil {
+		if errors.Is(err, postgresql.ErrUserNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.listCollection(w, r, internal.CollectionFilter{
+		UserId:     owner.ID,
+		PublicOnly: owner.ID != app.contextGetUser(r).ID,
+	})
+}
+
+func (app *application) listShelvesHandler(w http.ResponseWriter, r *http.Request) {
+	shelves, err := app.services.Collection.Shelves(app.contextGetUser(r).ID)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	res := struct {
+		Data []internal.ShelfSettings `json:"data"`
+	}{
+		Data: shelves,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+func (app *application) setShelfVisibilityHandler(w http.ResponseWriter, r *http.Request) {
+	var req setShelfVisibilityRequest
+
+	shelf, err := internal.ShelfFromString(r.PathValue("shelf"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	user := app.contextGetUser(r)
+	if err := app.services.Collection.SetVisibility(user.ID, shelf, *req.Public); err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.listShelvesHandler(w, r)
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ej-agas/perfume-db/internal"
+)
+
+type contextKey string
+
+const userContextKey = contextKey("user")
+
+func (app *application) contextSetUser(r *http.Request, user *internal.User) *http.Request {
+	ctx := context.WithValue(r.Context(), userContextKey, user)
+	return r.WithContext(ctx)
+}
+
+func (app *application) contextGetUser(r *http.Request) *internal.User {
+	user, ok := r.Context().Value(userContextKey).(*internal.User)
+	if !ok {
+		panic("missing user value in request context")
+	}
+
+	return user
+}
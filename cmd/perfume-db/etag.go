@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+func etagHeader(version int) http.Header {
+	headers := make(http.Header)
+	headers.Set("ETag", etag(version))
+
+	return headers
+}
+
+// checkIfMatch enforces the If-Match precondition of a PATCH request against
+// the version of the entity that was just loaded. It writes a 428 or 412
+// response and returns false when the request must not proceed.
+func (app *application) checkIfMatch(w http.ResponseWriter, r *http.Request, version int) bool {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+
+	if ifMatch == "" {
+		app.JSONResponse(w, ResponseMessage{Message: "If-Match header is required.", StatusCode: http.StatusPreconditionRequired}, http.StatusPreconditionRequired, nil)
+		return false
+	}
+
+	if ifMatch == "*" {
+		return true
+	}
+
+	// If-Match uses the strong comparison function, so weak tags never match.
+	current := etag(version)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(tag) == current {
+			return true
+		}
+	}
+
+	app.PreconditionFailed(w)
+	return false
+}
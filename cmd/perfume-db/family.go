@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+type createFamilyRequest struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	Description  string   `json:"description"`
+	ParentId     string   `json:"parent_id"`
+	NoteGroupIds []string `json:"note_group_ids"`
+}
+
+type updateFamilyRequest struct {
+	Name         Optional[string]   `json:"name" validate:"omitnil,required,max=100"`
+	Description  Optional[string]   `json:"description"`
+	ParentId     Optional[string]   `json:"parent_id"`
+	NoteGroupIds Optional[[]string] `json:"note_group_ids"`
+}
+
+type setPerfumeFamiliesRequest struct {
+	PrimaryFamilyId   string `json:"primary_family_id" validate:"required"`
+	SecondaryFamilyId string `json:"secondary_family_id"`
+}
+
+type familyScore struct {
+	Family *internal.Family `json:"family"`
+	Score  float64          `json:"score"`
+}
+
+type familyProposal struct {
+	Primary   *internal.Family `json:"primary"`
+	Secondary *internal.Family `json:"secondary"`
+	Scores    []familyScore    `json:"scores"`
+}
+
+func (app *application) listFamiliesHandler(w http.ResponseWriter, r *http.Request) {
+	cursor := r.URL.Query().Get("cursor")
+	var id = 0
+
+	if cursor != "" {
+		decrypted, err := app.Decrypt(cursor)
+		if err == nil {
+			id, _ = strconv.Atoi(string(decrypted))
+		}
+	}
+
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 || perPage > 100 {
+		perPage = 25
+	}
+
+	families, err := app.services.Family.List(id, perPage)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	var newCursor string
+	if len(families) == perPage {
+		lastFamily := families[len(families)-1]
+		newCursor, _ = app.Encrypt([]byte(strconv.Itoa(lastFamily.ID)))
+	}
+
+	res := Paginated[internal.Family]{
+		Data: families,
+		Next: newCursor,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+func (app *application) createFamilyHandler(w http.ResponseWriter, r *http.Request) {
+	var req createFamilyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	if req.NoteGroupIds == nil {
+		req.NoteGroupIds = make([]string, 0)
+	}
+
+	family, err := app.factory.NewFamily(req.Name, req.Description, req.ParentId, req.NoteGroupIds)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	if err := app.services.Family.Save(family); err != nil {
+		app.familySaveError(w, err)
+		return
+	}
+
+	app.JSONResponse(w, family, http.StatusCreated, etagHeader(family.Version))
+}
+
+func (app *application) showFamilyBySlugHandler(w http.ResponseWriter, r *http.Request) {
+	family, err := app.services.Family.FindBySlug(r.PathValue("slug"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	app.JSONResponse(w, family, http.StatusOK, etagHeader(family.Version))
+}
+
+func (app *application) updateFamilyHandler(w http.ResponseWriter, r *http.Request) {
+	var req updateFamilyRequest
+
+	family, err := app.services.Family.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if !app.checkIfMatch(w, r, family.Version) {
+		return
+	}
+
+	if !app.decodeMergePatch(w, r, &req) {
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	if req.Name.Set {
+		family.Name = req.Name.Value
+		family.Slug = internal.CreateSlug(req.Name.Value)
+	}
+
+	if req.Description.Set {
+		family.Description = req.Description.Value
+	}
+
+	if req.ParentId.Set {
+		family.ParentId = req.ParentId.Value
+	}
+
+	if req.NoteGroupIds.Set {
+		family.NoteGroupIds = make([]string, 0, len(req.NoteGroupIds.Value))
+		family.NoteGroupIds = append(family.NoteGroupIds, req.NoteGroupIds.Value...)
+	}
+
+	if err := app.services.Family.Save(family); err != nil {
+		app.familySaveError(w, err)
+		return
+	}
+
+	app.JSONResponse(w, family, http.StatusOK, etagHeader(family.Version))
+}
+
+// familySaveError writes the response for a family that could not be saved.
+func (app *application) familySaveError(w http.ResponseWriter, err error) {
+	res := NewValidationErrors()
+
+	switch {
+	case errors.Is(err, postgresql.ErrStaleVersion):
+		app.PreconditionFailed(w)
+		return
+	case errors.Is(err, postgresql.ErrFamilyAlreadyExists):
+		res.AddError("name", "Family already exists.")
+	case errors.Is(err, postgresql.ErrFamilyNotFound):
+		res.AddError("parent_id", "Parent family does not exist.")
+	case errors.Is(err, postgresql.ErrFamilyNesting):
+		res.AddError("parent_id", "Families can only be nested one level deep.")
+	case errors.Is(err, postgresql.ErrNoteGroupNotFound):
+		res.AddError("note_group_ids", "One or more note groups do not exist.")
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+}
+
+func (app *application) deleteFamilyHandler(w http.ResponseWriter, r *http.Request) {
+	err := app.services.Family.Delete(r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrFamilyNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrFamilyInUse):
+		app.JSONResponse(w, ResponseMessage{Message: "Family still has subfamilies.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+// proposePerfumeFamiliesHandler shows what the classifier makes of the
+// perfume's notes, so that editors can compare it with the families they
+// chose.
+func (app *application) proposePerfumeFamiliesHandler(w http.ResponseWriter, r *http.Request) {
+	perfume, err := app.services.Perfume.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	classification, err := app.services.Family.Propose(perfume.PublicId)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	familyIds := make([]string, 0, len(classification.Scores))
+	for _, score := range classification.Scores {
+		familyIds = append(familyIds, score.ID)
+	}
+
+	families, err := app.services.Family.FindMany(familyIds)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	familiesById := make(map[string]*internal.Family, len(families))
+	for _, family := range families {
+		familiesById[family.PublicId] = family
+	}
+
+	proposal := familyProposal{Scores: make([]familyScore, 0, len(classification.Scores))}
+	for _, score := range classification.Scores {
+		family := familiesById[score.ID]
+
+		switch family.PublicId {
+		case classification.Primary:
+			proposal.Primary = family
+		case classification.Secondary:
+			proposal.Secondary = family
+		}
+
+		proposal.Scores = append(proposal.Scores, familyScore{
+			Family: family,
+			Score:  math.Round(score.Score*1000) / 1000,
+		})
+	}
+
+	app.JSONResponse(w, proposal, http.StatusOK, nil)
+}
+
+// setPerfumeFamiliesHandler lets an editor override the proposed families of
+// a perfume. The classifier leaves overridden perfumes alone.
+func (app *application) setPerfumeFamiliesHandler(w http.ResponseWriter, r *http.Request) {
+	var req setPerfumeFamiliesRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	if req.PrimaryFamilyId == req.SecondaryFamilyId {
+		res := NewValidationErrors()
+		res.AddError("secondary_family_id", "Secondary family must differ from the primary family.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	err := app.services.Family.Override(r.PathValue("publicId"), req.PrimaryFamilyId, req.SecondaryFamilyId)
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrPerfumeNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrFamilyNotFound):
+		res := NewValidationErrors()
+		res.AddError("primary_family_id", "Family does not exist.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+// resetPerfumeFamiliesHandler drops an editor's override and reclassifies the
+// perfume from its notes.
+func (app *application) resetPerfumeFamiliesHandler(w http.ResponseWriter, r *http.Request) {
+	err := app.services.Family.ClearOverride(r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrPerfumeNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
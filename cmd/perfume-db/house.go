@@ -19,10 +19,10 @@ type createHouseRequest struct {
 }
 
 type updateHouseRequest struct {
-	Name        string `json:"name" validate:"omitempty"`
-	Country     string `json:"country" validate:"omitempty"`
-	Description string `json:"description" validate:"omitempty"`
-	YearFounded int    `json:"year_founded" validate:"omitempty,gte=1000,lte=9999"`
+	Name        Optional[string] `json:"name" validate:"omitnil,required"`
+	Country     Optional[string] `json:"country" validate:"omitnil,required"`
+	Description Optional[string] `json:"description" validate:"omitnil,required"`
+	YearFounded Optional[int]    `json:"year_founded" validate:"omitnil,required,gte=1000,lte=9999"`
 }
 
 func (app *application) createHouseHandler(w http.ResponseWriter, r *http.Request) {
@@ -50,7 +50,7 @@ func (app *application) createHouseHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err = app.services.House.Save(house)
+	err = app.services.House.Save(house, app.changeMeta(r))
 
 	if err == nil {
 		app.NoContent(w, http.StatusCreated)
@@ -84,7 +84,7 @@ func (app *application) listHouses(w http.ResponseWriter, r *http.Request) {
 	}
 
 	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
-	if err != nil || perPage < 0 || perPage > 100 {
+	if err != nil || perPage <= 0 || perPage > 100 {
 		perPage = 25
 	}
 
@@ -117,7 +117,7 @@ func (app *application) showHouseBySlug(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	app.JSONResponse(w, house, http.StatusOK, nil)
+	app.JSONResponse(w, house, http.StatusOK, etagHeader(house.Version))
 }
 
 func (app *application) updateHouseByPublicId(w http.ResponseWriter, r *http.Request) {
@@ -129,9 +129,11 @@ func (app *application) updateHouseByPublicId(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		app.logger.Error(err.Error())
-		app.BadRequest(w)
+	if !app.checkIfMatch(w, r, house.Version) {
+		return
+	}
+
+	if !app.decodeMergePatch(w, r, &requestData) {
 		return
 	}
 
@@ -141,28 +143,71 @@ func (app *application) updateHouseByPublicId(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if requestData.Name != "" {
-		house.Name = requestData.Name
-		house.Slug = internal.CreateSlug(requestData.Name)
+	applyHousePatch(house, requestData)
+
+	if err := app.services.House.Save(house, app.changeMeta(r)); err != nil {
+		if errors.Is(err, postgresql.ErrStaleVersion) {
+			app.PreconditionFailed(w)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
 	}
 
-	if requestData.Description != "" {
-		house.Description = requestData.Description
+	w.Header().Set("ETag", etag(house.Version))
+	app.NoContent(w, http.StatusOK)
+}
+
+// applyHousePatch applies a validated merge patch to the house.
+func applyHousePatch(house *internal.House, req updateHouseRequest) {
+	if req.Name.Set {
+		house.Name = req.Name.Value
+		house.Slug = internal.CreateSlug(req.Name.Value)
 	}
 
-	if requestData.Country != "" {
-		house.Country = requestData.Country
+	if req.Description.Set {
+		house.Description = req.Description.Value
 	}
 
-	if requestData.YearFounded != 0 {
-		house.YearFounded = time.Date(requestData.YearFounded, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if req.Country.Set {
+		house.Country = req.Country.Value
 	}
 
-	if err := app.services.House.Save(house); err != nil {
+	if req.YearFounded.Set {
+		house.YearFounded = time.Date(req.YearFounded.Value, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func (app *application) deleteHouseByPublicId(w http.ResponseWriter, r *http.Request) {
+	err := app.services.House.Delete(r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrHouseNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrHouseInUse):
+		app.JSONResponse(w, ResponseMessage{Message: "House is still referenced by one or more perfumes.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
 		app.logger.Error(err.Error())
 		app.ServerError(w)
-		return
 	}
+}
 
-	app.NoContent(w, http.StatusOK)
+func (app *application) restoreHouseByPublicId(w http.ResponseWriter, r *http.Request) {
+	err := app.services.House.Restore(r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusOK)
+	case errors.Is(err, postgresql.ErrHouseNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrHouseAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "House already exists.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
 }
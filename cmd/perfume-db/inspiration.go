@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+type createInspirationRequest struct {
+	OriginalId string `json:"original_id" validate:"required"`
+}
+
+type voteInspirationRequest struct {
+	Agrees *bool `json:"agrees" validate:"required"`
+}
+
+// inspiredPerfume is a perfume on the other side of an inspiration link,
+// along with how confident the community is in the link.
+type inspiredPerfume struct {
+	InspirationId string            `json:"inspiration_id"`
+	Perfume       *internal.Perfume `json:"perfume"`
+	Confidence    float64           `json:"confidence"`
+	NoteOverlap   float64           `json:"note_overlap"`
+	Agrees        int               `json:"agrees"`
+	Disagrees     int               `json:"disagrees"`
+}
+
+// createInspirationHandler links the perfume to the original it imitates.
+// The proposer's agreeing vote is recorded along with the link.
+func (app *application) createInspirationHandler(w http.ResponseWriter, r *http.Request) {
+	var req createInspirationRequest
+
+	perfume, err := app.services.Perfume.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	inspiration, err := app.factory.NewInspiration(perfume.PublicId, req.OriginalId, app.contextGetUser(r))
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	err = app.services.Inspiration.Save(inspiration)
+
+	switch {
+	case err == nil:
+		app.JSONResponse(w, inspiration, http.StatusCreated, nil)
+	case errors.Is(err, postgresql.ErrPerfumeNotFound):
+		res := NewValidationErrors()
+		res.AddError("original_id", "Perfume does not exist.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+	case errors.Is(err, postgresql.ErrInspirationSelf):
+		res := NewValidationErrors()
+		res.AddError("original_id", "A perfume cannot be inspired by itself.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+	case errors.Is(err, postgresql.ErrInspirationAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "The perfumes are already linked, vote on the link instead.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) deleteInspirationHandler(w http.ResponseWriter, r *http.Request) {
+	inspiration, err := app.services.Inspiration.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if err := app.services.Inspiration.Delete(inspiration); err != nil {
+		if errors.Is(err, postgresql.ErrInspirationNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.NoContent(w, http.StatusNoContent)
+}
+
+func (app *application) voteInspirationHandler(w http.ResponseWriter, r *http.Request) {
+	var req voteInspirationRequest
+
+	inspiration, err := app.services.Inspiration.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	if err := app.services.Inspiration.Vote(inspiration, app.contextGetUser(r).ID, *req.Agrees); err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	inspiration, err = app.services.Inspiration.Find(inspiration.PublicId)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.JSONResponse(w, inspiration, http.StatusOK, nil)
+}
+
+func (app *application) retractInspirationVoteHandler(w http.ResponseWriter, r *http.Request) {
+	inspiration, err := app.services.Inspiration.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if err := app.services.Inspiration.RetractVote(inspiration, app.contextGetUser(r).ID); err != nil {
+		if errors.Is(err, postgresql.ErrInspirationVoteNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.NoContent(w, http.StatusNoContent)
+}
+
+// listDupesHandler lists the perfumes that imitate the given one.
+func (app *application) listDupesHandler(w http.ResponseWriter, r *http.Request) {
+	app.listInspiredPerfumes(w, r, app.services.Inspiration.Dupes, func(inspiration internal.Inspiration) string {
+		return inspiration.PerfumeId
+	})
+}
+
+// listInspirationsHandler lists the originals the given perfume imitates.
+func (app *application) listInspirationsHandler(w http.ResponseWriter, r *http.Request) {
+	app.listInspiredPerfumes(w, r, app.services.Inspiration.InspiredBy, func(inspiration internal.Inspiration) string {
+		return inspiration.OriginalId
+	})
+}
+
+// listInspiredPerfumes responds with the perfumes on the other side of the
+// links that list returns for the perfume in the path, most confident first.
+func (app *application) listInspiredPerfumes(
+	w http.ResponseWriter,
+	r *http.Request,
+	list func(publicId string) ([]internal.Inspiration, error),
+	other func(inspiration internal.Inspiration) string,
+) {
+	perfume, err := app.services.Perfume.FindBySlug(r.PathValue("slug"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	inspirations, err := list(perfume.PublicId)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	ids := make([]string, 0, len(inspirations))
+	for _, inspiration := range inspirations {
+		ids = append(ids, other(inspiration))
+	}
+
+	perfumes, err := app.services.Perfume.FindAvailable(ids)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	byPublicId := make(map[string]*internal.Perfume, len(perfumes))
+	for _, perfume := range perfumes {
+		byPublicId[perfume.PublicId] = perfume
+	}
+
+	data := make([]inspiredPerfume, 0, len(inspirations))
+	for _, inspiration := range inspirations {
+		related, ok := byPublicId[other(inspiration)]
+		if !ok {
+			continue
+		}
+
+		data = append(data, inspiredPerfume{
+			InspirationId: inspiration.PublicId,
+			Perfume:       related,
+			Confidence:    inspiration.Confidence,
+			NoteOverlap:   inspiration.NoteOverlap,
+			Agrees:        inspiration.Agrees,
+			Disagrees:     inspiration.Disagrees,
+		})
+	}
+
+	res := struct {
+		Data []inspiredPerfume `json:"data"`
+	}{
+		Data: data,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
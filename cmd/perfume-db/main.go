@@ -10,6 +10,7 @@ import (
 	"strconv"
 
 	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/internal/batchcode"
 	"github.com/ej-agas/perfume-db/nanoid"
 	"github.com/ej-agas/perfume-db/postgresql"
 	"github.com/go-playground/validator/v10"
@@ -17,17 +18,20 @@ import (
 )
 
 type config struct {
-	port          int
-	environment   string
-	encryptionKey string
+	port              int
+	environment       string
+	encryptionKey     string
+	batchCodeDecoders string
 }
 
 type application struct {
-	config    config
-	logger    *slog.Logger
-	validator *validator.Validate
-	services  *postgresql.Services
-	factory   *internal.Factory
+	config          config
+	logger          *slog.Logger
+	validator       *validator.Validate
+	services        *postgresql.Services
+	factory         *internal.Factory
+	similarPerfumes similarityCache
+	batchCodes      *batchcode.Registry
 }
 
 var Version string
@@ -40,9 +44,10 @@ func main() {
 	}
 
 	cfg := config{
-		port:          port,
-		environment:   os.Getenv("APP_ENV"),
-		encryptionKey: os.Getenv("APP_ENCRYPTION_KEY"),
+		port:              port,
+		environment:       os.Getenv("APP_ENV"),
+		encryptionKey:     os.Getenv("APP_ENCRYPTION_KEY"),
+		batchCodeDecoders: os.Getenv("APP_BATCH_CODE_DECODERS"),
 	}
 
 	dbPort, err := strconv.Atoi(os.Getenv("DB_PORT"))
@@ -74,29 +79,43 @@ func main() {
 	idLength := 16
 	idGenerator := nanoid.NewNanoIdGenerator(idAlphabet, idLength)
 
-	validatorInstance := validator.New(validator.WithRequiredStructEnabled())
-	if err := validatorInstance.RegisterValidation("ymd-date-format", (&DateValidator{}).Validate); err != nil {
-		panic(err)
-	}
-
-	if err := validatorInstance.RegisterValidation("fragranceConcentration", (&FragranceConcentrationValidator{}).Validate); err != nil {
-		panic(err)
-	}
+	validatorInstance := newValidator()
 
-	if err := validatorInstance.RegisterValidation("noteCategory", (&NoteCategoriesValidator{}).Validate); err != nil {
-		panic(err)
+	// APP_BATCH_CODE_DECODERS assigns batch code formats to houses, such as
+	// "houseId:factory-month-year,otherHouseId:year-week".
+	batchCodes, err := batchcode.ParseRegistry(cfg.batchCodeDecoders)
+	if err != nil {
+		log.Fatal(fmt.Errorf("invalid batch code decoders: %s", err))
 	}
 
-	if err := validatorInstance.RegisterValidation("noteCount", (&NoteCountValidator{}).Validate); err != nil {
-		panic(err)
+	app := &application{
+		config:     cfg,
+		logger:     slog.New(slog.NewTextHandler(os.Stderr, nil)),
+		validator:  validatorInstance,
+		services:   postgresql.NewServices(conn),
+		factory:    &internal.Factory{IdGenerator: idGenerator},
+		batchCodes: batchCodes,
 	}
 
-	app := &application{
-		config:    cfg,
-		logger:    slog.New(slog.NewTextHandler(os.Stderr, nil)),
-		validator: validatorInstance,
-		services:  postgresql.NewServices(conn),
-		factory:   &internal.Factory{IdGenerator: idGenerator},
+	// Besides serving requests, the binary runs maintenance commands:
+	// "import-prices <file.csv>" records the prices of a file, and
+	// "promote-admin <email>" makes an existing user an admin, which is the
+	// only way the first admin of a fresh database is created.
+	if len(os.Args) == 3 {
+		switch os.Args[1] {
+		case "import-prices":
+			if err := app.importPrices(os.Args[2]); err != nil {
+				log.Fatal(fmt.Errorf("price import failed: %s", err))
+			}
+
+			return
+		case "promote-admin":
+			if err := app.promoteAdmin(os.Args[2]); err != nil {
+				log.Fatal(fmt.Errorf("admin promotion failed: %s", err))
+			}
+
+			return
+		}
 	}
 
 	app.logger.Info("APP RUNNING IN", "PORT", os.Getenv("APP_PORT"))
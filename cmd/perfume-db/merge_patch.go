@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"reflect"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// Optional is a field of an RFC 7386 JSON Merge Patch document. Set is false
+// when the key is absent from the patch, and Null is true when the key is
+// present with an explicit null, which clears the field.
+type Optional[T any] struct {
+	Value T
+	Set   bool
+	Null  bool
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+
+	if string(data) == "null" {
+		var zero T
+		o.Value = zero
+		o.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(data, &o.Value)
+}
+
+// Present reports whether the patch sets the field to a non-null value.
+func (o Optional[T]) Present() bool {
+	return o.Set && !o.Null
+}
+
+// validationValue hands the validator a nil pointer for absent keys, so that
+// "omitnil" and "omitempty" skip them, and the plain value (the zero value for
+// null) otherwise, so that "required" rejects a null on non-nullable fields.
+// A null slice or map is reported as an empty one, since "omitnil" would
+// otherwise mistake it for an absent key.
+func (o Optional[T]) validationValue() any {
+	if !o.Set {
+		return (*T)(nil)
+	}
+
+	if o.Null {
+		value := reflect.ValueOf(&o.Value).Elem()
+
+		switch value.Kind() {
+		case reflect.Slice:
+			return reflect.MakeSlice(value.Type(), 0, 0).Interface()
+		case reflect.Map:
+			return reflect.MakeMap(value.Type()).Interface()
+		}
+	}
+
+	return o.Value
+}
+
+// Nullable is an Optional field of a nullable column. The validator skips an
+// explicit null as it skips an absent key, so that rules such as "gte" only
+// apply to values and a null clears the field.
+type Nullable[T any] struct {
+	Optional[T]
+}
+
+func (n Nullable[T]) validationValue() any {
+	if n.Null {
+		return (*T)(nil)
+	}
+
+	return n.Optional.validationValue()
+}
+
+// optionalPointer applies a patched field to a nullable value, keeping the
+// current value when the key is absent and clearing it on null.
+func optionalPointer[T any](field Optional[T], current *T) *T {
+	if !field.Set {
+		return current
+	}
+
+	if field.Null {
+		return nil
+	}
+
+	value := field.Value
+	return &value
+}
+
+type optionalField interface {
+	validationValue() any
+}
+
+func optionalValue(field reflect.Value) any {
+	if optional, ok := field.Interface().(optionalField); ok {
+		return optional.validationValue()
+	}
+
+	return nil
+}
+
+// decodeMergePatch decodes a merge patch request body into dst. It writes a
+// 415 or 400 response and returns false when the body cannot be used.
+func (app *application) decodeMergePatch(w http.ResponseWriter, r *http.Request, dst any) bool {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+
+		if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+			headers := make(http.Header)
+			headers.Set("Accept-Patch", mergePatchContentType)
+
+			res := ResponseMessage{
+				Message:    "Unsupported media type, expected " + mergePatchContentType + ".",
+				StatusCode: http.StatusUnsupportedMediaType,
+			}
+
+			app.JSONResponse(w, res, http.StatusUnsupportedMediaType, headers)
+			return false
+		}
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// patchErrors decodes and validates the merge patch the way update handlers
+// do, returning the fields the handler would answer a 422 for.
+func patchErrors(t *testing.T, body string, req any) map[string][]string {
+	app := &application{logger: slog.New(slog.NewTextHandler(io.Discard, nil)), validator: newValidator()}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PATCH", "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", mergePatchContentType)
+
+	require.True(t, app.decodeMergePatch(w, r, req), w.Body.String())
+
+	return CreateResponseFromErrors(app.validator.Struct(req)).Errors
+}
+
+func TestNullableFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		req    func() any
+		errors []string
+	}{
+		{name: "review longevity null", body: `{"longevity": null}`, req: func() any { return &updateReviewRequest{} }},
+		{name: "review longevity zero", body: `{"longevity": 0}`, req: func() any { return &updateReviewRequest{} }, errors: []string{"longevity"}},
+		{name: "review sillage null", body: `{"sillage": null}`, req: func() any { return &updateReviewRequest{} }},
+		{name: "review value zero", body: `{"value": 0}`, req: func() any { return &updateReviewRequest{} }, errors: []string{"value"}},
+		{name: "collection item bottle size null", body: `{"bottle_size_ml": null}`, req: func() any { return &updateCollectionItemRequest{} }},
+		{name: "collection item bottle size zero", body: `{"bottle_size_ml": 0}`, req: func() any { return &updateCollectionItemRequest{} }, errors: []string{"bottle_size_ml"}},
+		{name: "variant size null", body: `{"size_ml": null}`, req: func() any { return &updatePerfumeVariantRequest{} }},
+		{name: "variant size zero", body: `{"size_ml": 0}`, req: func() any { return &updatePerfumeVariantRequest{} }, errors: []string{"size_ml"}},
+		{name: "review score null", body: `{"score": null}`, req: func() any { return &updateReviewRequest{} }, errors: []string{"score"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := patchErrors(t, tt.body, tt.req())
+
+			fields := make([]string, 0, len(errors))
+			for field := range errors {
+				fields = append(fields, field)
+			}
+
+			assert.ElementsMatch(t, tt.errors, fields)
+		})
+	}
+}
+
+func TestNullableFields_NullClears(t *testing.T) {
+	var req updateReviewRequest
+	require.Empty(t, patchErrors(t, `{"longevity": null}`, &req))
+
+	longevity := 4
+	assert.Nil(t, optionalPointer(req.Longevity.Optional, &longevity))
+	assert.Equal(t, &longevity, optionalPointer(req.Sillage.Optional, &longevity), "absent keys keep the current value")
+}
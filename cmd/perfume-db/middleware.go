@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+// authenticate attaches the user owning the Bearer token of the request to its
+// context, or the anonymous user when the request carries no token.
+func (app *application) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Authorization")
+
+		authorizationHeader := r.Header.Get("Authorization")
+
+		if authorizationHeader == "" {
+			next.ServeHTTP(w, app.contextSetUser(r, internal.AnonymousUser))
+			return
+		}
+
+		scheme, token, found := strings.Cut(authorizationHeader, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			app.InvalidAuthenticationToken(w)
+			return
+		}
+
+		user, err := app.services.User.FindByToken(token)
+		if err != nil {
+			if errors.Is(err, postgresql.ErrUserNotFound) {
+				app.InvalidAuthenticationToken(w)
+				return
+			}
+
+			app.logger.Error(err.Error())
+			app.ServerError(w)
+			return
+		}
+
+		next.ServeHTTP(w, app.contextSetUser(r, user))
+	})
+}
+
+func (app *application) requireAuthenticatedUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if app.contextGetUser(r).IsAnonymous() {
+			app.AuthenticationRequired(w)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// requireRole only lets authenticated users whose role includes the given
+// role through.
+func (app *application) requireRole(role internal.Role, next http.HandlerFunc) http.HandlerFunc {
+	return app.requireAuthenticatedUser(func(w http.ResponseWriter, r *http.Request) {
+		if !app.contextGetUser(r).Can(role) {
+			app.NotPermitted(w, role)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -18,10 +18,10 @@ type createNoteRequest struct {
 }
 
 type updateNoteRequest struct {
-	Name        string `json:"name" validate:"omitempty"`
-	Description string `json:"description" validate:"omitempty"`
-	ImageUrl    string `json:"image_url" validate:"omitempty,url"`
-	NoteGroupId string `json:"note_group_id" validate:"omitempty"`
+	Name        Optional[string] `json:"name" validate:"omitnil,required"`
+	Description Optional[string] `json:"description" validate:"omitnil,required"`
+	ImageUrl    Optional[string] `json:"image_url" validate:"omitempty,url"`
+	NoteGroupId Optional[string] `json:"note_group_id" validate:"omitnil,required"`
 }
 
 func (app *application) listNotes(w http.ResponseWriter, r *http.Request) {
@@ -42,7 +42,7 @@ func (app *application) listNotes(w http.ResponseWriter, r *http.Request) {
 	}
 
 	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
-	if err != nil || perPage < 0 || perPage > 100 {
+	if err != nil || perPage <= 0 || perPage > 100 {
 		perPage = 25
 	}
 
@@ -96,7 +96,7 @@ func (app *application) createNoteHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = app.services.Note.Save(note)
+	err = app.services.Note.Save(note, app.changeMeta(r))
 
 	if err == nil {
 		app.NoContent(w, http.StatusCreated)
@@ -127,7 +127,7 @@ func (app *application) showNoteBySlug(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	app.JSONResponse(w, note, http.StatusOK, nil)
+	app.JSONResponse(w, note, http.StatusOK, etagHeader(note.Version))
 }
 
 func (app *application) updateNoteByPublicId(w http.ResponseWriter, r *http.Request) {
@@ -139,9 +139,11 @@ func (app *application) updateNoteByPublicId(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		app.logger.Error(err.Error())
-		app.BadRequest(w)
+	if !app.checkIfMatch(w, r, note.Version) {
+		return
+	}
+
+	if !app.decodeMergePatch(w, r, &requestData) {
 		return
 	}
 
@@ -151,28 +153,76 @@ func (app *application) updateNoteByPublicId(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if requestData.Name != "" {
-		note.Name = requestData.Name
-		note.Slug = internal.CreateSlug(requestData.Name)
+	if requestData.Name.Set {
+		note.Name = requestData.Name.Value
+		note.Slug = internal.CreateSlug(requestData.Name.Value)
 	}
 
-	if requestData.Description != "" {
-		note.Description = requestData.Description
+	if requestData.Description.Set {
+		note.Description = requestData.Description.Value
 	}
 
-	if requestData.ImageUrl != "" {
-		note.ImageURL = requestData.ImageUrl
+	if requestData.ImageUrl.Set {
+		note.ImageURL = requestData.ImageUrl.Value
 	}
 
-	if requestData.NoteGroupId != "" {
-		note.NoteGroupId = requestData.NoteGroupId
+	if requestData.NoteGroupId.Set {
+		note.NoteGroupId = requestData.NoteGroupId.Value
 	}
 
-	if err := app.services.Note.Save(note); err != nil {
+	if err := app.services.Note.Save(note, app.changeMeta(r)); err != nil {
+		if errors.Is(err, postgresql.ErrStaleVersion) {
+			app.PreconditionFailed(w)
+			return
+		}
+
+		if errors.Is(err, postgresql.ErrNoteGroupNotFound) {
+			res := NewValidationErrors()
+			res.AddError("note_group_id", "Note Group does not exist.")
+
+			app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+			return
+		}
+
 		app.logger.Error(err.Error())
 		app.ServerError(w)
 		return
 	}
 
+	w.Header().Set("ETag", etag(note.Version))
 	app.NoContent(w, http.StatusOK)
 }
+
+func (app *application) deleteNoteByPublicId(w http.ResponseWriter, r *http.Request) {
+	err := app.services.Note.Delete(r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrNoteNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrNoteInUse):
+		app.JSONResponse(w, ResponseMessage{Message: "Note is still referenced by one or more perfumes.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) restoreNoteByPublicId(w http.ResponseWriter, r *http.Request) {
+	err := app.services.Note.Restore(r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusOK)
+	case errors.Is(err, postgresql.ErrNoteNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrNoteAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "Note already exists.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	case errors.Is(err, postgresql.ErrNoteGroupNotFound):
+		app.JSONResponse(w, ResponseMessage{Message: "Note group of the note has been deleted.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
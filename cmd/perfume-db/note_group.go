@@ -17,9 +17,9 @@ type createNoteGroupRequest struct {
 }
 
 type updateNoteGroupRequest struct {
-	Name        string `json:"name" validate:"omitempty"`
-	Description string `json:"description" validate:"omitempty"`
-	ImageUrl    string `json:"image_url" validate:"omitempty,url"`
+	Name        Optional[string] `json:"name" validate:"omitnil,required"`
+	Description Optional[string] `json:"description" validate:"omitnil,required"`
+	ImageUrl    Optional[string] `json:"image_url" validate:"omitempty,url"`
 }
 
 func (app *application) listNoteGroups(w http.ResponseWriter, r *http.Request) {
@@ -40,7 +40,7 @@ func (app *application) listNoteGroups(w http.ResponseWriter, r *http.Request) {
 	}
 
 	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
-	if err != nil || perPage < 0 || perPage > 100 {
+	if err != nil || perPage <= 0 || perPage > 100 {
 		perPage = 25
 	}
 
@@ -88,7 +88,7 @@ func (app *application) createNoteGroupHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	err = app.services.NoteGroup.Save(noteGroup)
+	err = app.services.NoteGroup.Save(noteGroup, app.changeMeta(r))
 
 	if err == nil {
 		app.NoContent(w, http.StatusCreated)
@@ -112,7 +112,7 @@ func (app *application) showNoteGroupBySlug(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	app.JSONResponse(w, noteGroup, http.StatusOK, nil)
+	app.JSONResponse(w, noteGroup, http.StatusOK, etagHeader(noteGroup.Version))
 }
 
 func (app *application) updateNoteGroupByPublicId(w http.ResponseWriter, r *http.Request) {
@@ -125,9 +125,11 @@ func (app *application) updateNoteGroupByPublicId(w http.ResponseWriter, r *http
 		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		app.logger.Error(err.Error())
-		app.BadRequest(w)
+	if !app.checkIfMatch(w, r, noteGroup.Version) {
+		return
+	}
+
+	if !app.decodeMergePatch(w, r, &requestData) {
 		return
 	}
 
@@ -137,24 +139,62 @@ func (app *application) updateNoteGroupByPublicId(w http.ResponseWriter, r *http
 		return
 	}
 
-	if requestData.Name != "" {
-		noteGroup.Name = requestData.Name
-		noteGroup.Slug = internal.CreateSlug(requestData.Name)
+	if requestData.Name.Set {
+		noteGroup.Name = requestData.Name.Value
+		noteGroup.Slug = internal.CreateSlug(requestData.Name.Value)
 	}
 
-	if requestData.Description != "" {
-		noteGroup.Description = requestData.Description
+	if requestData.Description.Set {
+		noteGroup.Description = requestData.Description.Value
 	}
 
-	if requestData.ImageUrl != "" {
-		noteGroup.ImageURL = requestData.ImageUrl
+	if requestData.ImageUrl.Set {
+		noteGroup.ImageURL = requestData.ImageUrl.Value
 	}
 
-	if err := app.services.NoteGroup.Save(noteGroup); err != nil {
+	if err := app.services.NoteGroup.Save(noteGroup, app.changeMeta(r)); err != nil {
+		if errors.Is(err, postgresql.ErrStaleVersion) {
+			app.PreconditionFailed(w)
+			return
+		}
+
 		app.logger.Error(err.Error())
 		app.ServerError(w)
 		return
 	}
 
+	w.Header().Set("ETag", etag(noteGroup.Version))
 	app.NoContent(w, http.StatusOK)
 }
+
+func (app *application) deleteNoteGroupByPublicId(w http.ResponseWriter, r *http.Request) {
+	err := app.services.NoteGroup.Delete(r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrNoteGroupNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrNoteGroupInUse):
+		app.JSONResponse(w, ResponseMessage{Message: "Note group is still referenced by one or more notes.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) restoreNoteGroupByPublicId(w http.ResponseWriter, r *http.Request) {
+	err := app.services.NoteGroup.Restore(r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusOK)
+	case errors.Is(err, postgresql.ErrNoteGroupNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrNoteGroupAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "Note group already exists.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/ej-agas/perfume-db/internal"
@@ -23,18 +24,136 @@ type createPerfumeRequest struct {
 }
 
 type updatePerfumeRequest struct {
-	Name             string              `json:"name" validate:"omitempty"`
-	Description      string              `json:"description" validate:"omitempty"`
-	Concentration    string              `json:"concentration" validate:"omitempty,fragranceConcentration"`
-	YearReleased     int                 `json:"year_released" validate:"omitempty,gte=1000,lte=9999"`
-	YearDiscontinued int                 `json:"year_discontinued" validate:"omitempty,gte=1000,lte=9999"`
-	HouseId          string              `json:"house_id" validate:"omitempty"`
-	Perfumers        []string            `json:"perfumers" validate:"omitempty,min=1"`
-	Notes            map[string][]string `json:"notes" validate:"omitempty,noteCategory"`
+	Name             Optional[string]              `json:"name" validate:"omitnil,required"`
+	Description      Optional[string]              `json:"description" validate:"omitnil,required"`
+	Concentration    Optional[string]              `json:"concentration" validate:"omitnil,required,fragranceConcentration"`
+	ImageUrl         Optional[string]              `json:"image_url" validate:"omitempty,url"`
+	YearReleased     Optional[int]                 `json:"year_released" validate:"omitnil,required,gte=1000,lte=9999"`
+	YearDiscontinued Optional[int]                 `json:"year_discontinued" validate:"omitempty,gte=1000,lte=9999"`
+	HouseId          Optional[string]              `json:"house_id" validate:"omitnil,required"`
+	Perfumers        Optional[[]string]            `json:"perfumers" validate:"omitnil,required,min=1"`
+	Notes            Optional[map[string][]string] `json:"notes" validate:"omitempty,noteCategory"`
+}
+
+type listPerfumesRequest struct {
+	HouseId       string
+	PerfumerId    string
+	NoteIds       []string
+	Concentration string `validate:"omitempty,fragranceConcentration"`
+	YearFrom      int    `validate:"omitempty,gte=1000,lte=9999"`
+	YearTo        int    `validate:"omitempty,gte=1000,lte=9999"`
+	Discontinued  string `validate:"omitempty,oneof=true false"`
+	Accord        string
+	Min           int `validate:"omitempty,gte=0,lte=100"`
+}
+
+func (app *application) listPerfumesHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	cursor := query.Get("cursor")
+	var id = 0
+
+	if cursor != "" {
+		decrypted, err := app.Decrypt(cursor)
+		if err != nil {
+			id = 0
+		}
+
+		convertedID, err := strconv.Atoi(string(decrypted))
+		if err != nil {
+			id = 0
+		}
+		id = convertedID
+	}
+
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage <= 0 || perPage > 100 {
+		perPage = 25
+	}
+
+	validationErrors := NewValidationErrors()
+	req := listPerfumesRequest{
+		HouseId:       query.Get("house_id"),
+		PerfumerId:    query.Get("perfumer_id"),
+		NoteIds:       query["note_id"],
+		Concentration: query.Get("concentration"),
+		Discontinued:  query.Get("discontinued"),
+		Accord:        query.Get("accord"),
+	}
+
+	if yearFrom := query.Get("year_from"); yearFrom != "" {
+		if req.YearFrom, err = strconv.Atoi(yearFrom); err != nil {
+			validationErrors.AddError("year_from", "The year from field must be a number.")
+		}
+	}
+
+	if yearTo := query.Get("year_to"); yearTo != "" {
+		if req.YearTo, err = strconv.Atoi(yearTo); err != nil {
+			validationErrors.AddError("year_to", "The year to field must be a number.")
+		}
+	}
+
+	if minStrength := query.Get("min"); minStrength != "" {
+		if req.Min, err = strconv.Atoi(minStrength); err != nil {
+			validationErrors.AddError("min", "The min field must be a number.")
+		} else if req.Accord == "" {
+			validationErrors.AddError("min", "The min field requires the accord field.")
+		}
+	}
+
+	if len(validationErrors.Errors) > 0 {
+		app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	filter := internal.PerfumeFilter{
+		HouseId:           req.HouseId,
+		PerfumerId:        req.PerfumerId,
+		NoteIds:           req.NoteIds,
+		YearReleasedFrom:  req.YearFrom,
+		YearReleasedTo:    req.YearTo,
+		AccordSlug:        req.Accord,
+		MinAccordStrength: req.Min,
+	}
+
+	if req.Concentration != "" {
+		concentration, _ := internal.ConcentrationFromString(req.Concentration)
+		filter.Concentration = &concentration
+	}
+
+	if req.Discontinued != "" {
+		discontinued := req.Discontinued == "true"
+		filter.Discontinued = &discontinued
+	}
+
+	perfumes, err := app.services.Perfume.List(id, perPage, filter)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	var newCursor string
+	if len(perfumes) == perPage {
+		lastPerfume := perfumes[len(perfumes)-1]
+		newCursor, _ = app.Encrypt([]byte(strconv.Itoa(lastPerfume.ID)))
+	}
+
+	res := Paginated[*internal.Perfume]{
+		Data: perfumes,
+		Next: newCursor,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
 }
 
 func (app *application) showPerfumeBySlug(w http.ResponseWriter, r *http.Request) {
-	house, err := app.services.Perfume.FindBySlug(r.PathValue("slug"))
+	perfume, err := app.services.Perfume.FindBySlug(r.PathValue("slug"))
 
 	if err != nil {
 		fmt.Println(err)
@@ -42,14 +161,11 @@ func (app *application) showPerfumeBySlug(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	app.JSONResponse(w, house, http.StatusOK, nil)
+	app.JSONResponse(w, perfume, http.StatusOK, etagHeader(perfume.Version))
 }
 
 func (app *application) createPerfumeHandler(w http.ResponseWriter, r *http.Request) {
 	var req createPerfumeRequest
-	var yearDiscontinued time.Time
-
-	validationErrors := NewValidationErrors()
 
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
@@ -64,25 +180,61 @@ func (app *application) createPerfumeHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	house, err := app.services.House.Find(req.HouseId)
-
+	perfume, validationErrors, err := app.newPerfume(app.services, req)
 	if err != nil {
-		validationErrors.AddError("house_id", "House not found.")
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	if validationErrors != nil {
 		app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
 		return
 	}
 
-	perfumers, err := app.services.Perfumer.FindMany(req.Perfumers...)
+	err = app.services.Perfume.Save(perfume, app.changeMeta(r))
+
+	if err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrPerfumeAlreadyExists):
+			app.JSONResponse(w, ResponseMessage{Message: "Perfume already exists", StatusCode: 422}, 422, nil)
+		case errors.Is(err, postgresql.ErrHouseNotFound):
+			validationErrors := NewValidationErrors()
+			validationErrors.AddError("house_id", "House not found.")
+			app.JSONResponse(w, validationErrors, 422, nil)
+		default:
+			app.logger.Error(err.Error())
+			app.ServerError(w)
+		}
+		return
+	}
+
+	app.JSONResponse(w, perfume, 200, nil)
+}
+
+// newPerfume builds the perfume described by a validated create request,
+// resolving its house, perfumers and notes through the given services.
+// Relations that cannot be resolved are returned as validation errors.
+func (app *application) newPerfume(services *postgresql.Services, req createPerfumeRequest) (*internal.Perfume, *ValidationErrors, error) {
+	var yearDiscontinued time.Time
+
+	validationErrors := NewValidationErrors()
+
+	house, err := services.House.Find(req.HouseId)
+
+	if err != nil {
+		validationErrors.AddError("house_id", "House not found.")
+		return nil, validationErrors, nil
+	}
+
+	perfumers, err := services.Perfumer.FindMany(req.Perfumers...)
 	if err != nil {
 		switch {
 		case errors.Is(err, postgresql.ErrPerfumerNotFound):
 			validationErrors.AddError("perfumers", err.Error())
-			app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
-			return
+			return nil, validationErrors, nil
 		default:
-			app.logger.Error(err.Error())
-			app.JSONResponse(w, err.Error(), http.StatusInternalServerError, nil)
-			return
+			return nil, nil, err
 		}
 	}
 
@@ -99,15 +251,13 @@ func (app *application) createPerfumeHandler(w http.ResponseWriter, r *http.Requ
 
 		if err != nil {
 			validationErrors.AddError("notes", "Invalid note category.")
-			app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
-			return
+			return nil, validationErrors, nil
 		}
 
-		notesResult, err := app.services.Note.FindMany(publicIds)
+		notesResult, err := services.Note.FindMany(publicIds)
 		if err != nil {
 			validationErrors.AddError("notes", err.Error())
-			app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
-			return
+			return nil, validationErrors, nil
 		}
 
 		notes[category] = notesResult
@@ -125,28 +275,10 @@ func (app *application) createPerfumeHandler(w http.ResponseWriter, r *http.Requ
 	)
 
 	if err != nil {
-		app.logger.Error(err.Error())
-		app.ServerError(w)
-		return
+		return nil, nil, err
 	}
 
-	err = app.services.Perfume.Save(perfume)
-
-	if err != nil {
-		switch {
-		case errors.Is(err, postgresql.ErrPerfumeAlreadyExists):
-			app.JSONResponse(w, ResponseMessage{Message: "Perfume already exists", StatusCode: 422}, 422, nil)
-		case errors.Is(err, postgresql.ErrHouseNotFound):
-			validationErrors.AddError("house_id", "House not found.")
-			app.JSONResponse(w, validationErrors, 422, nil)
-		default:
-			app.logger.Error(err.Error())
-			app.ServerError(w)
-		}
-		return
-	}
-
-	app.JSONResponse(w, perfume, 200, nil)
+	return perfume, nil, nil
 }
 
 func (app *application) updatePerfumeHandler(w http.ResponseWriter, r *http.Request) {
@@ -161,12 +293,11 @@ func (app *application) updatePerfumeHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	validationErrors := NewValidationErrors()
+	if !app.checkIfMatch(w, r, perfume.Version) {
+		return
+	}
 
-	err = json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		app.logger.Error(err.Error())
-		app.BadRequest(w)
+	if !app.decodeMergePatch(w, r, &req) {
 		return
 	}
 
@@ -176,87 +307,169 @@ func (app *application) updatePerfumeHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if req.Name != "" {
-		perfume.Name = req.Name
+	validationErrors, err := app.applyPerfumePatch(app.services, perfume, req)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
 	}
 
-	if req.Description != "" {
-		perfume.Description = req.Description
+	if validationErrors != nil {
+		app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
+		return
 	}
 
-	if req.Concentration != "" {
-		concentration, _ := internal.ConcentrationFromString(req.Concentration)
+	if err := app.services.Perfume.Save(perfume, app.changeMeta(r)); err != nil {
+		if errors.Is(err, postgresql.ErrStaleVersion) {
+			app.PreconditionFailed(w)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.JSONResponse(w, err.Error(), 500, nil)
+		return
+	}
+
+	app.JSONResponse(w, perfume, 200, etagHeader(perfume.Version))
+}
+
+// applyPerfumePatch applies a validated merge patch to the perfume, resolving
+// changed relations through the given services. Relations that cannot be
+// resolved are returned as validation errors.
+func (app *application) applyPerfumePatch(services *postgresql.Services, perfume *internal.Perfume, req updatePerfumeRequest) (*ValidationErrors, error) {
+	validationErrors := NewValidationErrors()
+
+	if req.Name.Set {
+		perfume.Name = req.Name.Value
+	}
+
+	if req.Description.Set {
+		perfume.Description = req.Description.Value
+	}
+
+	if req.Concentration.Set {
+		concentration, _ := internal.ConcentrationFromString(req.Concentration.Value)
 		perfume.Concentration = concentration
 	}
 
-	if req.YearReleased != 0 {
-		perfume.YearReleased = time.Date(req.YearReleased, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if req.ImageUrl.Set {
+		perfume.ImageURL = req.ImageUrl.Value
 	}
 
-	if req.YearDiscontinued != 0 {
-		perfume.YearDiscontinued = time.Date(req.YearDiscontinued, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if req.YearReleased.Set {
+		perfume.YearReleased = time.Date(req.YearReleased.Value, time.January, 1, 0, 0, 0, 0, time.UTC)
 	}
 
-	if req.HouseId != "" {
-		house, err := app.services.House.Find(req.HouseId)
+	if req.YearDiscontinued.Null {
+		perfume.YearDiscontinued = time.Time{}
+	}
+
+	if req.YearDiscontinued.Present() {
+		perfume.YearDiscontinued = time.Date(req.YearDiscontinued.Value, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	if req.HouseId.Set {
+		house, err := services.House.Find(req.HouseId.Value)
 
 		if err != nil {
 			validationErrors.AddError("house_id", "House not found.")
-			app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
-			return
+			return validationErrors, nil
 		}
 
 		perfume.House = house
 	}
 
-	if len(req.Perfumers) != 0 {
-		perfumers, err := app.services.Perfumer.FindMany(req.Perfumers...)
+	if req.Perfumers.Set {
+		perfumers, err := services.Perfumer.FindMany(req.Perfumers.Value...)
 		if err != nil {
 			switch {
 			case errors.Is(err, postgresql.ErrPerfumerNotFound):
 				validationErrors.AddError("perfumers", err.Error())
-				app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
-				return
+				return validationErrors, nil
 			default:
-				app.logger.Error(err.Error())
-				app.JSONResponse(w, err.Error(), http.StatusInternalServerError, nil)
-				return
+				return nil, err
 			}
 		}
 
 		perfume.Perfumers = perfumers
 	}
 
-	if req.Notes != nil {
-		notes := make(map[internal.NoteCategory][]*internal.Note, len(req.Notes))
+	if req.Notes.Null {
+		perfume.Notes = make(map[internal.NoteCategory][]*internal.Note)
+	}
+
+	if req.Notes.Present() {
+		notes := make(map[internal.NoteCategory][]*internal.Note, len(req.Notes.Value))
 
-		for key, publicIds := range req.Notes {
+		for key, publicIds := range req.Notes.Value {
 			category, err := internal.NoteCategoryFromString(key)
 
 			if err != nil {
 				validationErrors.AddError("notes", "Invalid note category.")
-				app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
-				return
+				return validationErrors, nil
+			}
+
+			// A null or empty category clears its notes.
+			if len(publicIds) == 0 {
+				notes[category] = nil
+				continue
 			}
 
-			notesResult, err := app.services.Note.FindMany(publicIds)
+			notesResult, err := services.Note.FindMany(publicIds)
 			if err != nil {
 				validationErrors.AddError("notes", err.Error())
-				app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
-				return
+				return validationErrors, nil
 			}
 
 			notes[category] = notesResult
 		}
 
-		perfume.Notes = notes
+		// Categories missing from the patch keep their current notes.
+		for category, categoryNotes := range notes {
+			if categoryNotes == nil {
+				delete(perfume.Notes, category)
+				continue
+			}
+
+			perfume.Notes[category] = categoryNotes
+		}
 	}
 
-	if err := app.services.Perfume.Save(perfume); err != nil {
+	return nil, nil
+}
+
+func (app *application) deletePerfumeHandler(w http.ResponseWriter, r *http.Request) {
+	err := app.services.Perfume.Delete(r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrPerfumeNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	default:
 		app.logger.Error(err.Error())
-		app.JSONResponse(w, err.Error(), 500, nil)
-		return
+		app.ServerError(w)
 	}
+}
 
-	app.JSONResponse(w, perfume, 200, nil)
+func (app *application) restorePerfumeHandler(w http.ResponseWriter, r *http.Request) {
+	err := app.services.Perfume.Restore(r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusOK)
+	case errors.Is(err, postgresql.ErrPerfumeNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrPerfumeAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "Perfume already exists.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	case errors.Is(err, postgresql.ErrHouseNotFound):
+		app.JSONResponse(w, ResponseMessage{Message: "House of the perfume has been deleted.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	case errors.Is(err, postgresql.ErrPerfumerNotFound):
+		app.JSONResponse(w, ResponseMessage{Message: "A perfumer of the perfume has been deleted.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	case errors.Is(err, postgresql.ErrNoteNotFound):
+		app.JSONResponse(w, ResponseMessage{Message: "A note of the perfume has been deleted.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
 }
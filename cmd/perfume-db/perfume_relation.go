@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+// perfumeRelation reads the relation from the path. It returns false when
+// the relation type is unknown.
+func perfumeRelation(r *http.Request) (*internal.PerfumeRelation, bool) {
+	relationType, err := internal.PerfumeRelationTypeFromString(r.PathValue("type"))
+	if err != nil {
+		return nil, false
+	}
+
+	return internal.NewPerfumeRelation(r.PathValue("publicId"), relationType, r.PathValue("relatedId")), true
+}
+
+func (app *application) relatePerfumesHandler(w http.ResponseWriter, r *http.Request) {
+	relation, ok := perfumeRelation(r)
+	if !ok {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	err := app.services.PerfumeRelation.Relate(relation)
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrPerfumeNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrPerfumeRelationSelf):
+		app.JSONResponse(w, ResponseMessage{Message: "A perfume cannot be related to itself.", StatusCode: http.StatusUnprocessableEntity}, http.StatusUnprocessableEntity, nil)
+	case errors.Is(err, postgresql.ErrPerfumeRelationCycle):
+		app.JSONResponse(w, ResponseMessage{Message: "The relation would create a cycle.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	case errors.Is(err, postgresql.ErrPerfumeRelationExists):
+		app.JSONResponse(w, ResponseMessage{Message: "The perfumes are already related with another type.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) unrelatePerfumesHandler(w http.ResponseWriter, r *http.Request) {
+	relation, ok := perfumeRelation(r)
+	if !ok {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	err := app.services.PerfumeRelation.Unrelate(relation)
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrPerfumeRelationNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
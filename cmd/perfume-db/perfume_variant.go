@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+type createPerfumeVariantRequest struct {
+	Type           string `json:"type" validate:"required,variantType"`
+	SizeMl         *int   `json:"size_ml" validate:"omitnil,gte=1,lte=5000"`
+	Barcode        string `json:"barcode" validate:"omitempty,barcode"`
+	LaunchedOn     string `json:"launched_on" validate:"omitempty,ymd-date-format"`
+	DiscontinuedOn string `json:"discontinued_on" validate:"omitempty,ymd-date-format"`
+}
+
+type updatePerfumeVariantRequest struct {
+	Type           Optional[string] `json:"type" validate:"omitnil,required,variantType"`
+	SizeMl         Nullable[int]    `json:"size_ml" validate:"omitnil,gte=1,lte=5000"`
+	Barcode        Optional[string] `json:"barcode" validate:"omitempty,barcode"`
+	LaunchedOn     Optional[string] `json:"launched_on" validate:"omitempty,ymd-date-format"`
+	DiscontinuedOn Optional[string] `json:"discontinued_on" validate:"omitempty,ymd-date-format"`
+}
+
+type barcodeLookup struct {
+	Variant *internal.PerfumeVariant `json:"variant"`
+	Perfume *internal.Perfume        `json:"perfume"`
+}
+
+func (app *application) listPerfumeVariantsHandler(w http.ResponseWriter, r *http.Request) {
+	perfume, err := app.services.Perfume.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	variants, err := app.services.PerfumeVariant.List(perfume.PublicId)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	res := struct {
+		Data []internal.PerfumeVariant `json:"data"`
+	}{
+		Data: variants,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+func (app *application) createPerfumeVariantHandler(w http.ResponseWriter, r *http.Request) {
+	var req createPerfumeVariantRequest
+
+	perfume, err := app.services.Perfume.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	variantType, _ := internal.VariantTypeFromString(req.Type)
+	variant, err := app.factory.NewPerfumeVariant(perfume.PublicId, variantType, req.Barcode)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	variant.SizeMl = req.SizeMl
+	variant.LaunchedOn = parseOptionalDate(req.LaunchedOn)
+	variant.DiscontinuedOn = parseOptionalDate(req.DiscontinuedOn)
+
+	app.savePerfumeVariant(w, variant, http.StatusCreated)
+}
+
+func (app *application) showPerfumeVariantHandler(w http.ResponseWriter, r *http.Request) {
+	variant, err := app.services.PerfumeVariant.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	app.JSONResponse(w, variant, http.StatusOK, nil)
+}
+
+func (app *application) updatePerfumeVariantHandler(w http.ResponseWriter, r *http.Request) {
+	var req updatePerfumeVariantRequest
+
+	variant, err := app.services.PerfumeVariant.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if !app.decodeMergePatch(w, r, &req) {
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	if req.Type.Set {
+		variant.Type, _ = internal.VariantTypeFromString(req.Type.Value)
+	}
+
+	variant.SizeMl = optionalPointer(req.SizeMl.Optional, variant.SizeMl)
+
+	if req.Barcode.Set {
+		variant.Barcode = req.Barcode.Value
+	}
+
+	if req.LaunchedOn.Set {
+		variant.LaunchedOn = parseOptionalDate(req.LaunchedOn.Value)
+	}
+
+	if req.DiscontinuedOn.Set {
+		variant.DiscontinuedOn = parseOptionalDate(req.DiscontinuedOn.Value)
+	}
+
+	app.savePerfumeVariant(w, variant, http.StatusOK)
+}
+
+// savePerfumeVariant saves the variant and responds with it, or with the
+// reason it could not be saved.
+func (app *application) savePerfumeVariant(w http.ResponseWriter, variant *internal.PerfumeVariant, status int) {
+	if variant.LaunchedOn != nil && variant.DiscontinuedOn != nil && variant.DiscontinuedOn.Before(*variant.LaunchedOn) {
+		res := NewValidationErrors()
+		res.AddError("discontinued_on", "The discontinued on field must not be before the launched on field.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	err := app.services.PerfumeVariant.Save(variant)
+
+	switch {
+	case err == nil:
+		app.JSONResponse(w, variant, status, nil)
+	case errors.Is(err, postgresql.ErrBarcodeAlreadyExists):
+		res := NewValidationErrors()
+		res.AddError("barcode", "The barcode belongs to another variant.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+	case errors.Is(err, postgresql.ErrPerfumeNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) deletePerfumeVariantHandler(w http.ResponseWriter, r *http.Request) {
+	variant, err := app.services.PerfumeVariant.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	err = app.services.PerfumeVariant.Delete(variant)
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrPerfumeVariantNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrPerfumeVariantInUse):
+		app.JSONResponse(w, ResponseMessage{Message: "Variant has recorded prices.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+// showBarcodeHandler resolves a scanned barcode to its variant and perfume.
+// UPC-A, EAN-13 and GTIN-14 forms of the same barcode all resolve.
+func (app *application) showBarcodeHandler(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	if !internal.ValidBarcode(code) {
+		res := NewValidationErrors()
+		res.AddError("code", "The code must be a valid EAN, UPC or GTIN barcode.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	variant, err := app.services.PerfumeVariant.FindByBarcode(code)
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	perfume, err := app.services.Perfume.Find(variant.PerfumeId)
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	app.JSONResponse(w, barcodeLookup{Variant: variant, Perfume: perfume}, http.StatusOK, nil)
+}
@@ -19,10 +19,10 @@ type createPerfumerRequest struct {
 }
 
 type updatePerfumerRequest struct {
-	Name        string `json:"name" validate:"omitempty"`
-	Nationality string `json:"nationality" validate:"omitempty"`
-	ImageUrl    string `json:"image_url" validate:"omitempty,url"`
-	BirthDate   string `json:"birth_date" validate:"omitempty,ymd-date-format"`
+	Name        Optional[string] `json:"name" validate:"omitnil,required"`
+	Nationality Optional[string] `json:"nationality" validate:"omitnil,required"`
+	ImageUrl    Optional[string] `json:"image_url" validate:"omitempty,url"`
+	BirthDate   Optional[string] `json:"birth_date" validate:"omitnil,required,ymd-date-format"`
 }
 
 func (app *application) createPerfumerHandler(w http.ResponseWriter, r *http.Request) {
@@ -58,7 +58,7 @@ func (app *application) createPerfumerHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	err = app.services.Perfumer.Save(perfumer)
+	err = app.services.Perfumer.Save(perfumer, app.changeMeta(r))
 
 	if err == nil {
 		app.NoContent(w, http.StatusCreated)
@@ -83,9 +83,11 @@ func (app *application) updatePerfumerByPublicIdHandler(w http.ResponseWriter, r
 		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		app.logger.Error(err.Error())
-		app.BadRequest(w)
+	if !app.checkIfMatch(w, r, perfumer.Version) {
+		return
+	}
+
+	if !app.decodeMergePatch(w, r, &req) {
 		return
 	}
 
@@ -95,38 +97,52 @@ func (app *application) updatePerfumerByPublicIdHandler(w http.ResponseWriter, r
 		return
 	}
 
-	if req.Name != "" {
-		perfumer.Name = req.Name
-		perfumer.Slug = internal.CreateSlug(req.Name)
+	if res := applyPerfumerPatch(perfumer, req); res != nil {
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
 	}
 
-	if req.Nationality != "" {
-		perfumer.Nationality = req.Nationality
-	}
+	if err := app.services.Perfumer.Save(perfumer, app.changeMeta(r)); err != nil {
+		if errors.Is(err, postgresql.ErrStaleVersion) {
+			app.PreconditionFailed(w)
+			return
+		}
 
-	if req.ImageUrl != "" {
-		perfumer.ImageURL = req.ImageUrl
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
 	}
 
-	if req.BirthDate != "" {
-		birthDate, err := time.Parse("2006-01-02", req.BirthDate)
+	w.Header().Set("ETag", etag(perfumer.Version))
+	app.NoContent(w, http.StatusOK)
+}
+
+// applyPerfumerPatch applies a validated merge patch to the perfumer.
+func applyPerfumerPatch(perfumer *internal.Perfumer, req updatePerfumerRequest) *ValidationErrors {
+	if req.BirthDate.Set {
+		birthDate, err := time.Parse("2006-01-02", req.BirthDate.Value)
 		if err != nil {
-			app.logger.Error(err.Error())
 			res := NewValidationErrors()
 			res.AddError("birth_date", "Invalid birth date.")
-			app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
-			return
+			return res
 		}
 		perfumer.BirthDate = birthDate
 	}
 
-	if err := app.services.Perfumer.Save(perfumer); err != nil {
-		app.logger.Error(err.Error())
-		app.ServerError(w)
-		return
+	if req.Name.Set {
+		perfumer.Name = req.Name.Value
+		perfumer.Slug = internal.CreateSlug(req.Name.Value)
 	}
 
-	app.NoContent(w, http.StatusOK)
+	if req.Nationality.Set {
+		perfumer.Nationality = req.Nationality.Value
+	}
+
+	if req.ImageUrl.Set {
+		perfumer.ImageURL = req.ImageUrl.Value
+	}
+
+	return nil
 }
 
 func (app *application) listPerfumersHandler(w http.ResponseWriter, r *http.Request) {
@@ -147,7 +163,7 @@ func (app *application) listPerfumersHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
-	if err != nil || perPage < 0 || perPage > 100 {
+	if err != nil || perPage <= 0 || perPage > 100 {
 		perPage = 25
 	}
 
@@ -180,5 +196,37 @@ func (app *application) showPerfumerBySlugHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	app.JSONResponse(w, perfumer, http.StatusOK, nil)
+	app.JSONResponse(w, perfumer, http.StatusOK, etagHeader(perfumer.Version))
+}
+
+func (app *application) deletePerfumerByPublicIdHandler(w http.ResponseWriter, r *http.Request) {
+	err := app.services.Perfumer.Delete(r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrPerfumerNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrPerfumerInUse):
+		app.JSONResponse(w, ResponseMessage{Message: "Perfumer is still referenced by one or more perfumes.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) restorePerfumerByPublicIdHandler(w http.ResponseWriter, r *http.Request) {
+	err := app.services.Perfumer.Restore(r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusOK)
+	case errors.Is(err, postgresql.ErrPerfumerNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrPerfumerAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "Perfumer already exists.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
 }
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+const (
+	// currentPriceAge is how long an observed price is taken to still hold.
+	currentPriceAge = 30 * 24 * time.Hour
+
+	maxRecordedPrices = 100
+)
+
+type priceObservationRequest struct {
+	VariantId  string   `json:"variant_id" validate:"required"`
+	RetailerId string   `json:"retailer_id" validate:"required"`
+	Price      *float64 `json:"price" validate:"required,gte=0"`
+	Currency   string   `json:"currency" validate:"required,iso4217"`
+	ObservedAt string   `json:"observed_at" validate:"omitempty,rfc3339-timestamp"`
+}
+
+type recordPricesRequest struct {
+	Observations []priceObservationRequest `json:"observations" validate:"required,min=1,dive"`
+}
+
+type priceHistoryRequest struct {
+	From string `validate:"omitempty,ymd-date-format"`
+	To   string `validate:"omitempty,ymd-date-format"`
+}
+
+// recordPricesHandler records prices observed at retailers. Prices default
+// to being observed now, and are recorded all together or not at all.
+func (app *application) recordPricesHandler(w http.ResponseWriter, r *http.Request) {
+	var req recordPricesRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	if len(req.Observations) > maxRecordedPrices {
+		res := NewValidationErrors()
+		res.AddError("observations", "At most 100 prices may be recorded at once.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	user := app.contextGetUser(r)
+	now := time.Now().UTC()
+
+	observations := make([]*internal.PriceObservation, 0, len(req.Observations))
+	for _, item := range req.Observations {
+		observedAt := now
+		if item.ObservedAt != "" {
+			observedAt, _ = time.Parse(time.RFC3339, item.ObservedAt)
+			observedAt = observedAt.UTC()
+		}
+
+		if observedAt.After(now) {
+			res := NewValidationErrors()
+			res.AddError("observed_at", "The observed at field must not be in the future.")
+			app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+			return
+		}
+
+		observation, err := app.factory.NewPriceObservation(item.VariantId, item.RetailerId, *item.Price, item.Currency, observedAt, internal.PriceSubmitted)
+		if err != nil {
+			app.logger.Error(err.Error())
+			app.ServerError(w)
+			return
+		}
+
+		observation.CreatedBy = &user.ID
+		observations = append(observations, observation)
+	}
+
+	err := app.services.Price.Record(observations)
+
+	switch {
+	case err == nil:
+		res := struct {
+			Data []*internal.PriceObservation `json:"data"`
+		}{
+			Data: observations,
+		}
+
+		app.JSONResponse(w, res, http.StatusCreated, nil)
+	case errors.Is(err, postgresql.ErrPerfumeVariantNotFound):
+		res := NewValidationErrors()
+		res.AddError("variant_id", "Variant does not exist.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+	case errors.Is(err, postgresql.ErrRetailerNotFound):
+		res := NewValidationErrors()
+		res.AddError("retailer_id", "Retailer does not exist.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+// lowestPricesHandler lists the cheapest current price of each variant of
+// the perfume, per currency. A price is current for currentPriceAge after it
+// was observed, unless the retailer has been observed asking another since.
+func (app *application) lowestPricesHandler(w http.ResponseWriter, r *http.Request) {
+	perfume, err := app.services.Perfume.FindBySlug(r.PathValue("slug"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	offers, err := app.services.Price.Lowest(perfume.PublicId, time.Now().UTC().Add(-currentPriceAge))
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	res := struct {
+		Data []internal.PriceOffer `json:"data"`
+	}{
+		Data: offers,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+// priceHistoryHandler lists the prices observed for the perfume, oldest
+// first, optionally narrowed by the "variant", "retailer", "from" and "to"
+// query parameters.
+func (app *application) priceHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	perfume, err := app.services.Perfume.FindBySlug(r.PathValue("slug"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	req := priceHistoryRequest{
+		From: r.URL.Query().Get("from"),
+		To:   r.URL.Query().Get("to"),
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	filter := internal.PriceHistoryFilter{
+		VariantId:  r.URL.Query().Get("variant"),
+		RetailerId: r.URL.Query().Get("retailer"),
+	}
+	filter.From, _ = time.Parse("2006-01-02", req.From)
+	filter.To, _ = time.Parse("2006-01-02", req.To)
+
+	observations, err := app.services.Price.History(perfume.PublicId, filter)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	res := struct {
+		Data []internal.PriceObservation `json:"data"`
+	}{
+		Data: observations,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
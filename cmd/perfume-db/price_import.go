@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+)
+
+// priceImportColumns are the columns a price import file must start with.
+// The variant is given by its ID or its barcode, the retailer by its slug,
+// and the observation time as RFC 3339 or as a YYYY-MM-DD day.
+var priceImportColumns = []string{"variant", "retailer", "price", "currency", "observed_at"}
+
+// importPrices records the prices of a CSV file, or of standard input when
+// the path is "-". Either every row is recorded or, when a row is invalid,
+// none of them.
+func (app *application) importPrices(path string) error {
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		input = file
+	}
+
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = len(priceImportColumns)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("read header: %w", err)
+	}
+
+	for i, column := range priceImportColumns {
+		if strings.ToLower(strings.TrimSpace(header[i])) != column {
+			return fmt.Errorf("expected columns %s", strings.Join(priceImportColumns, ","))
+		}
+	}
+
+	variants := make(map[string]string)
+	retailers := make(map[string]string)
+	observations := make([]*internal.PriceObservation, 0)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		observation, err := app.priceImportObservation(record, variants, retailers)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		observations = append(observations, observation)
+	}
+
+	if err := app.services.Price.Record(observations); err != nil {
+		return err
+	}
+
+	app.logger.Info("IMPORTED PRICES", "COUNT", len(observations))
+
+	return nil
+}
+
+// priceImportObservation turns a row of a price import into an observation.
+// Resolved variant and retailer IDs are cached in the given maps.
+func (app *application) priceImportObservation(record []string, variants, retailers map[string]string) (*internal.PriceObservation, error) {
+	variant, retailer := record[0], record[1]
+
+	variantId, ok := variants[variant]
+	if !ok {
+		find := app.services.PerfumeVariant.Find
+		if internal.ValidBarcode(variant) {
+			find = app.services.PerfumeVariant.FindByBarcode
+		}
+
+		found, err := find(variant)
+		if err != nil {
+			return nil, err
+		}
+
+		variantId = found.PublicId
+		variants[variant] = variantId
+	}
+
+	retailerId, ok := retailers[retailer]
+	if !ok {
+		found, err := app.services.Retailer.FindBySlug(retailer)
+		if err != nil {
+			return nil, err
+		}
+
+		retailerId = found.PublicId
+		retailers[retailer] = retailerId
+	}
+
+	price, err := strconv.ParseFloat(record[2], 64)
+	if err != nil || price < 0 {
+		return nil, fmt.Errorf("invalid price %q", record[2])
+	}
+
+	currency := strings.ToUpper(record[3])
+	if err := app.validator.Var(currency, "iso4217"); err != nil {
+		return nil, fmt.Errorf("invalid currency %q", record[3])
+	}
+
+	observedAt, err := time.Parse(time.RFC3339, record[4])
+	if err != nil {
+		observedAt, err = time.Parse("2006-01-02", record[4])
+	}
+
+	if err != nil || observedAt.After(time.Now()) {
+		return nil, fmt.Errorf("invalid observed_at %q", record[4])
+	}
+
+	return app.factory.NewPriceObservation(variantId, retailerId, price, currency, observedAt.UTC(), internal.PriceImported)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/internal/similarity"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+const maxRecommendations = 50
+
+type recommendationReason struct {
+	Note   *internal.Note `json:"note"`
+	Weight float64        `json:"weight"`
+}
+
+type recommendation struct {
+	Perfume *internal.Perfume      `json:"perfume"`
+	Score   float64                `json:"score"`
+	Reasons []recommendationReason `json:"reasons"`
+}
+
+// recommend scores the perfumes the user has not shelved or reviewed yet
+// against the taste profile built from the ones they have. Scoring reads the
+// current similarity snapshot without holding any lock.
+func (app *application) recommend(userId, limit int) ([]similarity.Recommendation, error) {
+	interactions, err := app.services.Recommendation.Interactions(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	exclusions, err := app.services.Recommendation.Exclusions(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := similarity.Exclusions{
+		Perfumes: make(map[string]bool, len(interactions)),
+		Houses:   make(map[string]bool),
+		Notes:    make(map[string]bool),
+	}
+
+	for _, interaction := range interactions {
+		excluded.Perfumes[interaction.PerfumeID] = true
+	}
+
+	for _, exclusion := range exclusions {
+		switch exclusion.Type {
+		case internal.HouseEntity:
+			excluded.Houses[exclusion.EntityId] = true
+		case internal.NoteEntity:
+			excluded.Notes[exclusion.EntityId] = true
+		}
+	}
+
+	snapshot, err := app.similarityIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	index := snapshot.index
+	return index.Recommend(index.Profile(interactions), excluded, limit), nil
+}
+
+func (app *application) listRecommendationsHandler(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > maxRecommendations {
+		limit = 10
+	}
+
+	recommendations, err := app.recommend(app.contextGetUser(r).ID, limit)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	ids := make([]string, 0, len(recommendations))
+	for _, recommendation := range recommendations {
+		ids = append(ids, recommendation.ID)
+	}
+
+	perfumes, err := app.services.Perfume.FindAvailable(ids)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	byPublicId := make(map[string]*internal.Perfume, len(perfumes))
+	for _, perfume := range perfumes {
+		byPublicId[perfume.PublicId] = perfume
+	}
+
+	data := make([]recommendation, 0, len(recommendations))
+	for _, recommended := range recommendations {
+		perfume, ok := byPublicId[recommended.ID]
+		if !ok {
+			continue
+		}
+
+		reasons := make([]recommendationReason, 0, len(recommended.Reasons))
+		for _, reason := range recommended.Reasons {
+			notes := sharedNotes(perfume, []string{reason.NoteID})
+			if len(notes) == 0 {
+				continue
+			}
+
+			reasons = append(reasons, recommendationReason{
+				Note:   notes[0],
+				Weight: math.Round(reason.Weight*1000) / 1000,
+			})
+		}
+
+		data = append(data, recommendation{
+			Perfume: perfume,
+			Score:   math.Round(recommended.Score*1000) / 1000,
+			Reasons: reasons,
+		})
+	}
+
+	res := struct {
+		Data []recommendation `json:"data"`
+	}{
+		Data: data,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+func (app *application) listRecommendationExclusionsHandler(w http.ResponseWriter, r *http.Request) {
+	exclusions, err := app.services.Recommendation.Exclusions(app.contextGetUser(r).ID)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	res := struct {
+		Data []internal.RecommendationExclusion `json:"data"`
+	}{
+		Data: exclusions,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+// recommendationExclusion reads the excluded house or note from the path.
+func recommendationExclusion(r *http.Request) (internal.RecommendationExclusion, error) {
+	entityType, err := internal.EntityTypeFromString(r.PathValue("type"))
+	if err != nil {
+		return internal.RecommendationExclusion{}, err
+	}
+
+	return internal.NewRecommendationExclusion(entityType, r.PathValue("publicId"))
+}
+
+func (app *application) excludeFromRecommendationsHandler(w http.ResponseWriter, r *http.Request) {
+	exclusion, err := recommendationExclusion(r)
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	switch exclusion.Type {
+	case internal.HouseEntity:
+		_, err = app.services.House.Find(exclusion.EntityId)
+	case internal.NoteEntity:
+		_, err = app.services.Note.Find(exclusion.EntityId)
+	}
+
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if err := app.services.Recommendation.Exclude(app.contextGetUser(r).ID, exclusion); err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.NoContent(w, http.StatusNoContent)
+}
+
+func (app *application) includeInRecommendationsHandler(w http.ResponseWriter, r *http.Request) {
+	exclusion, err := recommendationExclusion(r)
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if err := app.services.Recommendation.Include(app.contextGetUser(r).ID, exclusion); err != nil {
+		if errors.Is(err, postgresql.ErrRecommendationExclusionNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.NoContent(w, http.StatusNoContent)
+}
@@ -63,3 +63,63 @@ func (app *application) NoContent(w http.ResponseWriter, statusCode int) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(statusCode)
 }
+
+func (app *application) PreconditionFailed(w http.ResponseWriter) {
+	res := ResponseMessage{
+		Message:    "The resource has been modified since it was read.",
+		StatusCode: http.StatusPreconditionFailed,
+	}
+
+	app.JSONResponse(w, res, http.StatusPreconditionFailed, nil)
+}
+
+func (app *application) AuthenticationRequired(w http.ResponseWriter) {
+	res := ResponseMessage{
+		Message:    "You must be authenticated to access this resource.",
+		StatusCode: http.StatusUnauthorized,
+	}
+
+	headers := make(http.Header)
+	headers.Set("WWW-Authenticate", "Bearer")
+
+	app.JSONResponse(w, res, http.StatusUnauthorized, headers)
+}
+
+func (app *application) InvalidAuthenticationToken(w http.ResponseWriter) {
+	res := ResponseMessage{
+		Message:    "Invalid or missing authentication token.",
+		StatusCode: http.StatusUnauthorized,
+	}
+
+	headers := make(http.Header)
+	headers.Set("WWW-Authenticate", "Bearer")
+
+	app.JSONResponse(w, res, http.StatusUnauthorized, headers)
+}
+
+func (app *application) InvalidCredentials(w http.ResponseWriter) {
+	res := ResponseMessage{
+		Message:    "Invalid authentication credentials.",
+		StatusCode: http.StatusUnauthorized,
+	}
+
+	app.JSONResponse(w, res, http.StatusUnauthorized, nil)
+}
+
+func (app *application) NotPermitted(w http.ResponseWriter, role internal.Role) {
+	res := ResponseMessage{
+		Message:    "Your account needs the " + role.String() + " role to access this resource.",
+		StatusCode: http.StatusForbidden,
+	}
+
+	app.JSONResponse(w, res, http.StatusForbidden, nil)
+}
+
+func (app *application) NotOwner(w http.ResponseWriter) {
+	res := ResponseMessage{
+		Message:    "Only the owner can modify this resource.",
+		StatusCode: http.StatusForbidden,
+	}
+
+	app.JSONResponse(w, res, http.StatusForbidden, nil)
+}
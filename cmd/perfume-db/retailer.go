@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+type createRetailerRequest struct {
+	Name    string `json:"name" validate:"required,max=100"`
+	Website string `json:"website" validate:"omitempty,url"`
+}
+
+type updateRetailerRequest struct {
+	Name    Optional[string] `json:"name" validate:"omitnil,required,max=100"`
+	Website Optional[string] `json:"website" validate:"omitempty,url"`
+}
+
+func (app *application) listRetailersHandler(w http.ResponseWriter, r *http.Request) {
+	cursor := r.URL.Query().Get("cursor")
+	var id = 0
+
+	if cursor != "" {
+		decrypted, err := app.Decrypt(cursor)
+		if err == nil {
+			id, _ = strconv.Atoi(string(decrypted))
+		}
+	}
+
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 || perPage > 100 {
+		perPage = 25
+	}
+
+	retailers, err := app.services.Retailer.List(id, perPage)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	var newCursor string
+	if len(retailers) == perPage {
+		lastRetailer := retailers[len(retailers)-1]
+		newCursor, _ = app.Encrypt([]byte(strconv.Itoa(lastRetailer.ID)))
+	}
+
+	res := Paginated[internal.Retailer]{
+		Data: retailers,
+		Next: newCursor,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+func (app *application) showRetailerBySlugHandler(w http.ResponseWriter, r *http.Request) {
+	retailer, err := app.services.Retailer.FindBySlug(r.PathValue("slug"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	app.JSONResponse(w, retailer, http.StatusOK, nil)
+}
+
+func (app *application) createRetailerHandler(w http.ResponseWriter, r *http.Request) {
+	var req createRetailerRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	retailer, err := app.factory.NewRetailer(req.Name, req.Website)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	if err := app.services.Retailer.Save(retailer); err != nil {
+		app.retailerSaveError(w, err)
+		return
+	}
+
+	app.JSONResponse(w, retailer, http.StatusCreated, nil)
+}
+
+func (app *application) updateRetailerHandler(w http.ResponseWriter, r *http.Request) {
+	var req updateRetailerRequest
+
+	retailer, err := app.services.Retailer.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if !app.decodeMergePatch(w, r, &req) {
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	if req.Name.Set {
+		retailer.Name = req.Name.Value
+		retailer.Slug = internal.CreateSlug(req.Name.Value)
+	}
+
+	if req.Website.Set {
+		retailer.Website = req.Website.Value
+	}
+
+	if err := app.services.Retailer.Save(retailer); err != nil {
+		app.retailerSaveError(w, err)
+		return
+	}
+
+	app.JSONResponse(w, retailer, http.StatusOK, nil)
+}
+
+func (app *application) retailerSaveError(w http.ResponseWriter, err error) {
+	if errors.Is(err, postgresql.ErrRetailerAlreadyExists) {
+		app.JSONResponse(w, ResponseMessage{Message: "Retailer already exists.", StatusCode: http.StatusUnprocessableEntity}, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	app.logger.Error(err.Error())
+	app.ServerError(w)
+}
+
+func (app *application) deleteRetailerHandler(w http.ResponseWriter, r *http.Request) {
+	retailer, err := app.services.Retailer.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	err = app.services.Retailer.Delete(retailer)
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrRetailerNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, postgresql.ErrRetailerInUse):
+		app.JSONResponse(w, ResponseMessage{Message: "Retailer has recorded prices.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
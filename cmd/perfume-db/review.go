@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+type createReviewRequest struct {
+	Score     int    `json:"score" validate:"required,gte=1,lte=10"`
+	Text      string `json:"text" validate:"max=10000"`
+	Longevity *int   `json:"longevity" validate:"omitnil,gte=1,lte=5"`
+	Sillage   *int   `json:"sillage" validate:"omitnil,gte=1,lte=5"`
+	Value     *int   `json:"value" validate:"omitnil,gte=1,lte=5"`
+}
+
+type updateReviewRequest struct {
+	Score     Optional[int]    `json:"score" validate:"omitnil,required,gte=1,lte=10"`
+	Text      Optional[string] `json:"text" validate:"omitempty,max=10000"`
+	Longevity Nullable[int]    `json:"longevity" validate:"omitnil,gte=1,lte=5"`
+	Sillage   Nullable[int]    `json:"sillage" validate:"omitnil,gte=1,lte=5"`
+	Value     Nullable[int]    `json:"value" validate:"omitnil,gte=1,lte=5"`
+}
+
+func (app *application) createReviewHandler(w http.ResponseWriter, r *http.Request) {
+	var req createReviewRequest
+
+	perfume, err := app.services.Perfume.Find(r.PathValue("publicId"))
+	if err != nil {
+		if errors.Is(err, postgresql.ErrPerfumeNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	review, err := app.factory.NewReview(perfume.PublicId, app.contextGetUser(r), req.Score, req.Text)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	review.Longevity = req.Longevity
+	review.Sillage = req.Sillage
+	review.Value = req.Value
+
+	if err := app.services.Review.Save(review); err != nil {
+		if errors.Is(err, postgresql.ErrReviewAlreadyExists) {
+			app.JSONResponse(w, ResponseMessage{Message: "You have already reviewed this perfume.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+			return
+		}
+
+		if errors.Is(err, postgresql.ErrPerfumeNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.JSONResponse(w, review, http.StatusCreated, nil)
+}
+
+func (app *application) listReviews(w http.ResponseWriter, r *http.Request, filter internal.ReviewFilter) {
+	cursor := r.URL.Query().Get("cursor")
+	var id = 0
+
+	if cursor != "" {
+		decrypted, err := app.Decrypt(cursor)
+		if err == nil {
+			id, _ = strconv.Atoi(string(decrypted))
+		}
+	}
+
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 || perPage > 100 {
+		perPage = 25
+	}
+
+	reviews, err := app.services.Review.List(filter, id, perPage)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	var newCursor string
+	if len(reviews) == perPage {
+		lastReview := reviews[len(reviews)-1]
+		newCursor, _ = app.Encrypt([]byte(strconv.Itoa(lastReview.ID)))
+	}
+
+	res := Paginated[internal.Review]{
+		Data: reviews,
+		Next: newCursor,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+func (app *application) listPerfumeReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	perfume, err := app.services.Perfume.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	app.listReviews(w, r, internal.ReviewFilter{PerfumeId: perfume.PublicId})
+}
+
+func (app *application) listOwnReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	app.listReviews(w, r, internal.ReviewFilter{UserId: app.contextGetUser(r).ID})
+}
+
+func (app *application) showReviewHandler(w http.ResponseWriter, r *http.Request) {
+	review, err := app.services.Review.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	app.JSONResponse(w, review, http.StatusOK, nil)
+}
+
+// updateReviewHandler applies a merge patch to a review. Only its author can
+// edit it.
+func (app *application) updateReviewHandler(w http.ResponseWriter, r *http.Request) {
+	var req updateReviewRequest
+
+	review, err := app.services.Review.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if !review.IsWrittenBy(app.contextGetUser(r)) {
+		app.NotOwner(w)
+		return
+	}
+
+	if !app.decodeMergePatch(w, r, &req) {
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	if req.Score.Set {
+		review.Score = req.Score.Value
+	}
+
+	if req.Text.Set {
+		review.Text = req.Text.Value
+	}
+
+	review.Longevity = optionalPointer(req.Longevity.Optional, review.Longevity)
+	review.Sillage = optionalPointer(req.Sillage.Optional, review.Sillage)
+	review.Value = optionalPointer(req.Value.Optional, review.Value)
+
+	if err := app.services.Review.Save(review); err != nil {
+		if errors.Is(err, postgresql.ErrReviewNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.JSONResponse(w, review, http.StatusOK, nil)
+}
+
+// deleteReviewHandler deletes a review on behalf of its author, or of an
+// editor moderating it.
+func (app *application) deleteReviewHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	review, err := app.services.Review.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if !review.IsWrittenBy(user) && !user.Can(internal.RoleEditor) {
+		app.NotOwner(w)
+		return
+	}
+
+	if err := app.services.Review.Delete(review); err != nil {
+		if errors.Is(err, postgresql.ErrReviewNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.NoContent(w, http.StatusNoContent)
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+// changeMeta describes the author and reason of a change made by the request.
+// The author is the authenticated user and the reason is taken from the
+// optional X-Change-Reason header.
+func (app *application) changeMeta(r *http.Request) internal.ChangeMeta {
+	actor := "anonymous"
+	if user := app.contextGetUser(r); !user.IsAnonymous() {
+		actor = user.Username
+	}
+
+	return internal.ChangeMeta{
+		Actor:  actor,
+		Reason: r.Header.Get("X-Change-Reason"),
+	}
+}
+
+func (app *application) listRevisionsHandler(entityType internal.EntityType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("cursor")
+		var id = 0
+
+		if cursor != "" {
+			decrypted, err := app.Decrypt(cursor)
+			if err != nil {
+				id = 0
+			}
+
+			convertedID, err := strconv.Atoi(string(decrypted))
+			if err != nil {
+				id = 0
+			}
+			id = convertedID
+		}
+
+		perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+		if err != nil || perPage <= 0 || perPage > 100 {
+			perPage = 25
+		}
+
+		revisions, err := app.services.Revision.List(entityType, r.PathValue("publicId"), id, perPage)
+		if err != nil {
+			app.logger.Error(err.Error())
+			app.ServerError(w)
+			return
+		}
+
+		var newCursor string
+		if len(revisions) == perPage {
+			lastRevision := revisions[len(revisions)-1]
+			newCursor, _ = app.Encrypt([]byte(strconv.Itoa(lastRevision.ID)))
+		}
+
+		res := Paginated[internal.Revision]{
+			Data: revisions,
+			Next: newCursor,
+		}
+
+		app.JSONResponse(w, res, http.StatusOK, nil)
+	}
+}
+
+func (app *application) showRevisionHandler(entityType internal.EntityType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		revision, err := app.findRevision(entityType, r)
+		if err != nil {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.JSONResponse(w, revision, http.StatusOK, nil)
+	}
+}
+
+func (app *application) revertRevisionHandler(entityType internal.EntityType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		revision, err := app.findRevision(entityType, r)
+		if err != nil {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		meta := app.changeMeta(r)
+		if meta.Reason == "" {
+			meta.Reason = fmt.Sprintf("Reverted to revision %d.", revision.Number)
+		}
+
+		switch entityType {
+		case internal.HouseEntity:
+			app.revertHouse(w, revision, meta)
+		case internal.PerfumerEntity:
+			app.revertPerfumer(w, revision, meta)
+		case internal.NoteGroupEntity:
+			app.revertNoteGroup(w, revision, meta)
+		case internal.NoteEntity:
+			app.revertNote(w, revision, meta)
+		case internal.PerfumeEntity:
+			app.revertPerfume(w, revision, meta)
+		default:
+			app.NoContent(w, http.StatusNotFound)
+		}
+	}
+}
+
+func (app *application) findRevision(entityType internal.EntityType, r *http.Request) (*internal.Revision, error) {
+	number, err := strconv.Atoi(r.PathValue("revision"))
+	if err != nil {
+		return nil, err
+	}
+
+	return app.services.Revision.Find(entityType, r.PathValue("publicId"), number)
+}
+
+func (app *application) revertHouse(w http.ResponseWriter, revision *internal.Revision, meta internal.ChangeMeta) {
+	current, err := app.services.House.Find(revision.EntityId)
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	var house internal.House
+	if err := json.Unmarshal(revision.Snapshot, &house); err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	house.ID = current.ID
+	house.PublicId = current.PublicId
+	house.CreatedAt = current.CreatedAt
+	house.Version = current.Version
+
+	err = app.services.House.Save(&house, meta)
+	switch {
+	case err == nil:
+		app.JSONResponse(w, house, http.StatusOK, etagHeader(house.Version))
+	case errors.Is(err, postgresql.ErrStaleVersion):
+		app.PreconditionFailed(w)
+	case errors.Is(err, postgresql.ErrHouseAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "House already exists.", StatusCode: http.StatusUnprocessableEntity}, http.StatusUnprocessableEntity, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) revertPerfumer(w http.ResponseWriter, revision *internal.Revision, meta internal.ChangeMeta) {
+	current, err := app.services.Perfumer.Find(revision.EntityId)
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	var perfumer internal.Perfumer
+	if err := json.Unmarshal(revision.Snapshot, &perfumer); err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	perfumer.ID = current.ID
+	perfumer.PublicId = current.PublicId
+	perfumer.CreatedAt = current.CreatedAt
+	perfumer.Version = current.Version
+
+	err = app.services.Perfumer.Save(&perfumer, meta)
+	switch {
+	case err == nil:
+		app.JSONResponse(w, perfumer, http.StatusOK, etagHeader(perfumer.Version))
+	case errors.Is(err, postgresql.ErrStaleVersion):
+		app.PreconditionFailed(w)
+	case errors.Is(err, postgresql.ErrPerfumerAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "Perfumer already exists.", StatusCode: http.StatusUnprocessableEntity}, http.StatusUnprocessableEntity, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) revertNoteGroup(w http.ResponseWriter, revision *internal.Revision, meta internal.ChangeMeta) {
+	current, err := app.services.NoteGroup.Find(revision.EntityId)
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	var noteGroup internal.NoteGroup
+	if err := json.Unmarshal(revision.Snapshot, &noteGroup); err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	noteGroup.ID = current.ID
+	noteGroup.PublicId = current.PublicId
+	noteGroup.CreatedAt = current.CreatedAt
+	noteGroup.Version = current.Version
+
+	err = app.services.NoteGroup.Save(&noteGroup, meta)
+	switch {
+	case err == nil:
+		app.JSONResponse(w, noteGroup, http.StatusOK, etagHeader(noteGroup.Version))
+	case errors.Is(err, postgresql.ErrStaleVersion):
+		app.PreconditionFailed(w)
+	case errors.Is(err, postgresql.ErrNoteGroupAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "Note group already exists.", StatusCode: http.StatusUnprocessableEntity}, http.StatusUnprocessableEntity, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) revertNote(w http.ResponseWriter, revision *internal.Revision, meta internal.ChangeMeta) {
+	current, err := app.services.Note.Find(revision.EntityId)
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	var note internal.Note
+	if err := json.Unmarshal(revision.Snapshot, &note); err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	if _, err := app.services.NoteGroup.Find(note.NoteGroupId); err != nil {
+		res := NewValidationErrors()
+		res.AddError("note_group_id", "Note Group does not exist.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	note.ID = current.ID
+	note.PublicId = current.PublicId
+	note.CreatedAt = current.CreatedAt
+	note.Version = current.Version
+
+	err = app.services.Note.Save(&note, meta)
+	switch {
+	case err == nil:
+		app.JSONResponse(w, note, http.StatusOK, etagHeader(note.Version))
+	case errors.Is(err, postgresql.ErrStaleVersion):
+		app.PreconditionFailed(w)
+	case errors.Is(err, postgresql.ErrNoteAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "Note already exists.", StatusCode: http.StatusUnprocessableEntity}, http.StatusUnprocessableEntity, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) revertPerfume(w http.ResponseWriter, revision *internal.Revision, meta internal.ChangeMeta) {
+	current, err := app.services.Perfume.Find(revision.EntityId)
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	var perfume internal.Perfume
+	if err := json.Unmarshal(revision.Snapshot, &perfume); err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	validationErrors := NewValidationErrors()
+
+	// Relations are re-resolved so that the revert fails cleanly when one of
+	// them has been deleted since the revision was taken.
+	house, err := app.services.House.Find(perfume.House.PublicId)
+	if err != nil {
+		validationErrors.AddError("house_id", "House not found.")
+		app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	perfumerIds := make([]string, len(perfume.Perfumers))
+	for i, perfumer := range perfume.Perfumers {
+		perfumerIds[i] = perfumer.PublicId
+	}
+
+	perfumers, err := app.services.Perfumer.FindMany(perfumerIds...)
+	if err != nil {
+		validationErrors.AddError("perfumers", err.Error())
+		app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	notes := make(map[internal.NoteCategory][]*internal.Note, len(perfume.Notes))
+	for category, categoryNotes := range perfume.Notes {
+		noteIds := make([]string, len(categoryNotes))
+		for i, note := range categoryNotes {
+			noteIds[i] = note.PublicId
+		}
+
+		notesResult, err := app.services.Note.FindMany(noteIds)
+		if err != nil {
+			validationErrors.AddError("notes", err.Error())
+			app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
+			return
+		}
+
+		notes[category] = notesResult
+	}
+
+	perfume.ID = current.ID
+	perfume.PublicId = current.PublicId
+	perfume.CreatedAt = current.CreatedAt
+	perfume.Version = current.Version
+	perfume.House = house
+	perfume.Perfumers = perfumers
+	perfume.Notes = notes
+
+	err = app.services.Perfume.Save(&perfume, meta)
+	switch {
+	case err == nil:
+		app.JSONResponse(w, perfume, http.StatusOK, etagHeader(perfume.Version))
+	case errors.Is(err, postgresql.ErrStaleVersion):
+		app.PreconditionFailed(w)
+	case errors.Is(err, postgresql.ErrPerfumeAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "Perfume already exists", StatusCode: http.StatusUnprocessableEntity}, http.StatusUnprocessableEntity, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
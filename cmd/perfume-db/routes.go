@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
 )
 
 func Home(w http.ResponseWriter, r *http.Request) {
@@ -28,29 +30,163 @@ func (app *application) routes() http.Handler {
 
 	router.HandleFunc("GET /", Home)
 
-	router.HandleFunc("POST /houses", app.createHouseHandler)
+	// Contributors add houses, note groups, notes, perfumers and perfumes
+	// through /submissions, which editors moderate. Creating them directly
+	// therefore needs an editor rather than a contributor.
+	router.HandleFunc("POST /houses", app.requireRole(internal.RoleEditor, app.createHouseHandler))
 	router.HandleFunc("GET /houses", app.listHouses)
 	router.HandleFunc("GET /houses/{slug}", app.showHouseBySlug)
-	router.HandleFunc("PATCH /houses/{publicId}", app.updateHouseByPublicId)
+	router.HandleFunc("PATCH /houses/{publicId}", app.requireRole(internal.RoleEditor, app.updateHouseByPublicId))
+	router.HandleFunc("DELETE /houses/{publicId}", app.requireRole(internal.RoleAdmin, app.deleteHouseByPublicId))
+	router.HandleFunc("POST /houses/{publicId}/restore", app.requireRole(internal.RoleAdmin, app.restoreHouseByPublicId))
+	router.HandleFunc("GET /houses/{publicId}/history", app.listRevisionsHandler(internal.HouseEntity))
+	router.HandleFunc("GET /houses/{publicId}/history/{revision}", app.showRevisionHandler(internal.HouseEntity))
+	router.HandleFunc("POST /houses/{publicId}/history/{revision}/revert", app.requireRole(internal.RoleEditor, app.revertRevisionHandler(internal.HouseEntity)))
+	router.HandleFunc("GET /houses/{publicId}/change-requests", app.requireRole(internal.RoleEditor, app.listEntityChangeRequestsHandler(internal.HouseEntity)))
+	router.HandleFunc("POST /houses/{publicId}/change-requests", app.requireRole(internal.RoleContributor, app.createChangeRequestHandler(internal.HouseEntity)))
+	router.HandleFunc("POST /houses/{slug}/batch-codes/decode", app.decodeBatchCodeHandler)
 
-	router.HandleFunc("POST /note-groups", app.createNoteGroupHandler)
+	router.HandleFunc("POST /note-groups", app.requireRole(internal.RoleEditor, app.createNoteGroupHandler))
 	router.HandleFunc("GET /note-groups", app.listNoteGroups)
 	router.HandleFunc("GET /note-groups/{slug}", app.showNoteGroupBySlug)
-	router.HandleFunc("PATCH /note-groups/{publicId}", app.updateNoteGroupByPublicId)
+	router.HandleFunc("PATCH /note-groups/{publicId}", app.requireRole(internal.RoleEditor, app.updateNoteGroupByPublicId))
+	router.HandleFunc("DELETE /note-groups/{publicId}", app.requireRole(internal.RoleAdmin, app.deleteNoteGroupByPublicId))
+	router.HandleFunc("POST /note-groups/{publicId}/restore", app.requireRole(internal.RoleAdmin, app.restoreNoteGroupByPublicId))
+	router.HandleFunc("GET /note-groups/{publicId}/history", app.listRevisionsHandler(internal.NoteGroupEntity))
+	router.HandleFunc("GET /note-groups/{publicId}/history/{revision}", app.showRevisionHandler(internal.NoteGroupEntity))
+	router.HandleFunc("POST /note-groups/{publicId}/history/{revision}/revert", app.requireRole(internal.RoleEditor, app.revertRevisionHandler(internal.NoteGroupEntity)))
 
-	router.HandleFunc("POST /notes", app.createNoteHandler)
+	router.HandleFunc("POST /notes", app.requireRole(internal.RoleEditor, app.createNoteHandler))
 	router.HandleFunc("GET /notes", app.listNotes)
 	router.HandleFunc("GET /notes/{slug}", app.showNoteBySlug)
-	router.HandleFunc("PATCH /notes/{publicId}", app.updateNoteByPublicId)
+	router.HandleFunc("PATCH /notes/{publicId}", app.requireRole(internal.RoleEditor, app.updateNoteByPublicId))
+	router.HandleFunc("DELETE /notes/{publicId}", app.requireRole(internal.RoleAdmin, app.deleteNoteByPublicId))
+	router.HandleFunc("POST /notes/{publicId}/restore", app.requireRole(internal.RoleAdmin, app.restoreNoteByPublicId))
+	router.HandleFunc("GET /notes/{publicId}/history", app.listRevisionsHandler(internal.NoteEntity))
+	router.HandleFunc("GET /notes/{publicId}/history/{revision}", app.showRevisionHandler(internal.NoteEntity))
+	router.HandleFunc("POST /notes/{publicId}/history/{revision}/revert", app.requireRole(internal.RoleEditor, app.revertRevisionHandler(internal.NoteEntity)))
+
+	router.HandleFunc("GET /notes/{publicId}/accords", app.listNoteAccordsHandler)
+	router.HandleFunc("PUT /notes/{publicId}/accords", app.requireRole(internal.RoleEditor, app.setNoteAccordsHandler))
+
+	router.HandleFunc("POST /accords", app.requireRole(internal.RoleEditor, app.createAccordHandler))
+	router.HandleFunc("GET /accords", app.listAccordsHandler)
+	router.HandleFunc("GET /accords/{slug}", app.showAccordBySlugHandler)
+	router.HandleFunc("PATCH /accords/{publicId}", app.requireRole(internal.RoleEditor, app.updateAccordHandler))
+	router.HandleFunc("DELETE /accords/{publicId}", app.requireRole(internal.RoleAdmin, app.deleteAccordHandler))
 
-	router.HandleFunc("POST /perfumers", app.createPerfumerHandler)
-	router.HandleFunc("PATCH /perfumers/{publicId}", app.updatePerfumerByPublicIdHandler)
+	router.HandleFunc("POST /families", app.requireRole(internal.RoleEditor, app.createFamilyHandler))
+	router.HandleFunc("GET /families", app.listFamiliesHandler)
+	router.HandleFunc("GET /families/{slug}", app.showFamilyBySlugHandler)
+	router.HandleFunc("PATCH /families/{publicId}", app.requireRole(internal.RoleEditor, app.updateFamilyHandler))
+	router.HandleFunc("DELETE /families/{publicId}", app.requireRole(internal.RoleAdmin, app.deleteFamilyHandler))
+
+	router.HandleFunc("POST /perfumers", app.requireRole(internal.RoleEditor, app.createPerfumerHandler))
+	router.HandleFunc("PATCH /perfumers/{publicId}", app.requireRole(internal.RoleEditor, app.updatePerfumerByPublicIdHandler))
 	router.HandleFunc("GET /perfumers", app.listPerfumersHandler)
 	router.HandleFunc("GET /perfumers/{slug}", app.showPerfumerBySlugHandler)
+	router.HandleFunc("DELETE /perfumers/{publicId}", app.requireRole(internal.RoleAdmin, app.deletePerfumerByPublicIdHandler))
+	router.HandleFunc("POST /perfumers/{publicId}/restore", app.requireRole(internal.RoleAdmin, app.restorePerfumerByPublicIdHandler))
+	router.HandleFunc("GET /perfumers/{publicId}/history", app.listRevisionsHandler(internal.PerfumerEntity))
+	router.HandleFunc("GET /perfumers/{publicId}/history/{revision}", app.showRevisionHandler(internal.PerfumerEntity))
+	router.HandleFunc("POST /perfumers/{publicId}/history/{revision}/revert", app.requireRole(internal.RoleEditor, app.revertRevisionHandler(internal.PerfumerEntity)))
+	router.HandleFunc("GET /perfumers/{publicId}/change-requests", app.requireRole(internal.RoleEditor, app.listEntityChangeRequestsHandler(internal.PerfumerEntity)))
+	router.HandleFunc("POST /perfumers/{publicId}/change-requests", app.requireRole(internal.RoleContributor, app.createChangeRequestHandler(internal.PerfumerEntity)))
 
-	router.HandleFunc("POST /perfumes", app.createPerfumeHandler)
-	router.HandleFunc("PATCH /perfumes/{publicId}", app.updatePerfumeHandler)
+	router.HandleFunc("POST /perfumes", app.requireRole(internal.RoleEditor, app.createPerfumeHandler))
+	router.HandleFunc("GET /perfumes", app.listPerfumesHandler)
+	router.HandleFunc("PATCH /perfumes/{publicId}", app.requireRole(internal.RoleEditor, app.updatePerfumeHandler))
 	router.HandleFunc("GET /perfumes/{slug}", app.showPerfumeBySlug)
+	router.HandleFunc("GET /perfumes/{slug}/similar", app.similarPerfumesHandler)
+	router.HandleFunc("GET /perfumes/{slug}/dupes", app.listDupesHandler)
+	router.HandleFunc("GET /perfumes/{slug}/inspired", app.listInspirationsHandler)
+	router.HandleFunc("DELETE /perfumes/{publicId}", app.requireRole(internal.RoleAdmin, app.deletePerfumeHandler))
+	router.HandleFunc("POST /perfumes/{publicId}/restore", app.requireRole(internal.RoleAdmin, app.restorePerfumeHandler))
+	router.HandleFunc("GET /perfumes/{publicId}/history", app.listRevisionsHandler(internal.PerfumeEntity))
+	router.HandleFunc("GET /perfumes/{publicId}/history/{revision}", app.showRevisionHandler(internal.PerfumeEntity))
+	router.HandleFunc("POST /perfumes/{publicId}/history/{revision}/revert", app.requireRole(internal.RoleEditor, app.revertRevisionHandler(internal.PerfumeEntity)))
+	router.HandleFunc("GET /perfumes/{publicId}/change-requests", app.requireRole(internal.RoleEditor, app.listEntityChangeRequestsHandler(internal.PerfumeEntity)))
+	router.HandleFunc("POST /perfumes/{publicId}/change-requests", app.requireRole(internal.RoleContributor, app.createChangeRequestHandler(internal.PerfumeEntity)))
+	router.HandleFunc("GET /perfumes/{publicId}/families/proposal", app.requireRole(internal.RoleEditor, app.proposePerfumeFamiliesHandler))
+	router.HandleFunc("PUT /perfumes/{publicId}/families", app.requireRole(internal.RoleEditor, app.setPerfumeFamiliesHandler))
+	router.HandleFunc("DELETE /perfumes/{publicId}/families", app.requireRole(internal.RoleEditor, app.resetPerfumeFamiliesHandler))
+	router.HandleFunc("PUT /perfumes/{publicId}/relations/{type}/{relatedId}", app.requireRole(internal.RoleEditor, app.relatePerfumesHandler))
+	router.HandleFunc("DELETE /perfumes/{publicId}/relations/{type}/{relatedId}", app.requireRole(internal.RoleEditor, app.unrelatePerfumesHandler))
+	router.HandleFunc("GET /perfumes/{publicId}/reviews", app.listPerfumeReviewsHandler)
+	router.HandleFunc("POST /perfumes/{publicId}/reviews", app.requireAuthenticatedUser(app.createReviewHandler))
+	router.HandleFunc("PUT /perfumes/{publicId}/votes/{dimension}", app.requireAuthenticatedUser(app.castVoteHandler))
+	router.HandleFunc("DELETE /perfumes/{publicId}/votes/{dimension}", app.requireAuthenticatedUser(app.retractVoteHandler))
+
+	router.HandleFunc("GET /perfumes/{publicId}/variants", app.listPerfumeVariantsHandler)
+	router.HandleFunc("POST /perfumes/{publicId}/variants", app.requireRole(internal.RoleEditor, app.createPerfumeVariantHandler))
+	router.HandleFunc("GET /variants/{publicId}", app.showPerfumeVariantHandler)
+	router.HandleFunc("PATCH /variants/{publicId}", app.requireRole(internal.RoleEditor, app.updatePerfumeVariantHandler))
+	router.HandleFunc("DELETE /variants/{publicId}", app.requireRole(internal.RoleEditor, app.deletePerfumeVariantHandler))
+	router.HandleFunc("GET /barcodes/{code}", app.showBarcodeHandler)
+
+	router.HandleFunc("GET /perfumes/{slug}/prices", app.lowestPricesHandler)
+	router.HandleFunc("GET /perfumes/{slug}/prices/history", app.priceHistoryHandler)
+	router.HandleFunc("POST /prices", app.requireRole(internal.RoleContributor, app.recordPricesHandler))
+
+	router.HandleFunc("GET /retailers", app.listRetailersHandler)
+	router.HandleFunc("POST /retailers", app.requireRole(internal.RoleEditor, app.createRetailerHandler))
+	router.HandleFunc("GET /retailers/{slug}", app.showRetailerBySlugHandler)
+	router.HandleFunc("PATCH /retailers/{publicId}", app.requireRole(internal.RoleEditor, app.updateRetailerHandler))
+	router.HandleFunc("DELETE /retailers/{publicId}", app.requireRole(internal.RoleAdmin, app.deleteRetailerHandler))
+
+	router.HandleFunc("POST /perfumes/{publicId}/inspirations", app.requireAuthenticatedUser(app.createInspirationHandler))
+	router.HandleFunc("DELETE /inspirations/{publicId}", app.requireRole(internal.RoleEditor, app.deleteInspirationHandler))
+	router.HandleFunc("PUT /inspirations/{publicId}/vote", app.requireAuthenticatedUser(app.voteInspirationHandler))
+	router.HandleFunc("DELETE /inspirations/{publicId}/vote", app.requireAuthenticatedUser(app.retractInspirationVoteHandler))
+
+	router.HandleFunc("GET /reviews/{publicId}", app.showReviewHandler)
+	router.HandleFunc("PATCH /reviews/{publicId}", app.requireAuthenticatedUser(app.updateReviewHandler))
+	router.HandleFunc("DELETE /reviews/{publicId}", app.requireAuthenticatedUser(app.deleteReviewHandler))
+	router.HandleFunc("GET /me/reviews", app.requireAuthenticatedUser(app.listOwnReviewsHandler))
+
+	router.HandleFunc("GET /me/collection", app.requireAuthenticatedUser(app.listOwnCollectionHandler))
+	router.HandleFunc("POST /me/collection", app.requireAuthenticatedUser(app.createCollectionItemHandler))
+	router.HandleFunc("GET /me/collection/{publicId}", app.requireAuthenticatedUser(app.showCollectionItemHandler))
+	router.HandleFunc("PATCH /me/collection/{publicId}", app.requireAuthenticatedUser(app.updateCollectionItemHandler))
+	router.HandleFunc("DELETE /me/collection/{publicId}", app.requireAuthenticatedUser(app.deleteCollectionItemHandler))
+	router.HandleFunc("GET /me/shelves", app.requireAuthenticatedUser(app.listShelvesHandler))
+	router.HandleFunc("PUT /me/shelves/{shelf}", app.requireAuthenticatedUser(app.setShelfVisibilityHandler))
+	router.HandleFunc("GET /users/{username}/collection", app.showUserCollectionHandler)
+
+	router.HandleFunc("GET /me/wears", app.requireAuthenticatedUser(app.listWearsHandler))
+	router.HandleFunc("POST /me/wears", app.requireAuthenticatedUser(app.createWearHandler))
+	router.HandleFunc("DELETE /me/wears/{publicId}", app.requireAuthenticatedUser(app.deleteWearHandler))
+	router.HandleFunc("GET /me/wears/stats", app.requireAuthenticatedUser(app.wearStatsHandler))
+
+	router.HandleFunc("GET /me/recommendations", app.requireAuthenticatedUser(app.listRecommendationsHandler))
+	router.HandleFunc("GET /me/recommendations/exclusions", app.requireAuthenticatedUser(app.listRecommendationExclusionsHandler))
+	router.HandleFunc("PUT /me/recommendations/exclusions/{type}/{publicId}", app.requireAuthenticatedUser(app.excludeFromRecommendationsHandler))
+	router.HandleFunc("DELETE /me/recommendations/exclusions/{type}/{publicId}", app.requireAuthenticatedUser(app.includeInRecommendationsHandler))
+
+	router.HandleFunc("GET /search", app.searchHandler)
+	router.HandleFunc("GET /autocomplete", app.autocompleteHandler)
+
+	router.HandleFunc("POST /users", app.registerUserHandler)
+	router.HandleFunc("GET /me", app.requireAuthenticatedUser(app.showCurrentUserHandler))
+	router.HandleFunc("POST /tokens", app.createTokenHandler)
+	router.HandleFunc("GET /tokens", app.requireAuthenticatedUser(app.listTokensHandler))
+	router.HandleFunc("DELETE /tokens/{publicId}", app.requireAuthenticatedUser(app.revokeTokenHandler))
+
+	router.HandleFunc("POST /submissions", app.requireRole(internal.RoleContributor, app.createSubmissionHandler))
+	router.HandleFunc("GET /submissions", app.requireRole(internal.RoleEditor, app.listSubmissionsHandler))
+	router.HandleFunc("GET /submissions/{publicId}", app.requireAuthenticatedUser(app.showSubmissionHandler))
+	router.HandleFunc("POST /submissions/{publicId}/approve", app.requireRole(internal.RoleEditor, app.approveSubmissionHandler))
+	router.HandleFunc("POST /submissions/{publicId}/reject", app.requireRole(internal.RoleEditor, app.rejectSubmissionHandler))
+	router.HandleFunc("GET /me/submissions", app.requireAuthenticatedUser(app.listOwnSubmissionsHandler))
+
+	router.HandleFunc("GET /change-requests", app.requireRole(internal.RoleEditor, app.listChangeRequestsHandler))
+	router.HandleFunc("GET /change-requests/{publicId}", app.requireAuthenticatedUser(app.showChangeRequestHandler))
+	router.HandleFunc("POST /change-requests/{publicId}/accept", app.requireRole(internal.RoleEditor, app.acceptChangeRequestHandler))
+	router.HandleFunc("POST /change-requests/{publicId}/reject", app.requireRole(internal.RoleEditor, app.rejectChangeRequestHandler))
+	router.HandleFunc("GET /me/change-requests", app.requireAuthenticatedUser(app.listOwnChangeRequestsHandler))
+
+	router.HandleFunc("GET /admin/users", app.requireRole(internal.RoleAdmin, app.listUsersHandler))
+	router.HandleFunc("PUT /admin/users/{publicId}/role", app.requireRole(internal.RoleAdmin, app.updateUserRoleHandler))
 
-	return router
+	return app.authenticate(router)
 }
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ej-agas/perfume-db/internal"
+)
+
+type searchRequest struct {
+	Q string `validate:"required"`
+}
+
+func (app *application) searchHandler(w http.ResponseWriter, r *http.Request) {
+	req := searchRequest{Q: r.URL.Query().Get("q")}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 25
+	}
+
+	results, err := app.services.Search.Search(req.Q, limit)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	res := struct {
+		Data []internal.SearchResult `json:"data"`
+	}{
+		Data: results,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
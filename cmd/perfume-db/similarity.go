@@ -0,0 +1,190 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/internal/similarity"
+)
+
+const (
+	similarityIndexTTL = 10 * time.Minute
+	maxSimilarPerfumes = 50
+)
+
+// similarityCache holds the latest similarity snapshot. Snapshots are never
+// modified once published, so requests read them without locking. A snapshot
+// older than similarityIndexTTL is replaced in the background while requests
+// keep reading it, so catalogue edits show up with at most that delay.
+type similarityCache struct {
+	snapshot   atomic.Pointer[similaritySnapshot]
+	refreshing atomic.Bool
+	building   sync.Mutex
+}
+
+// similaritySnapshot is a similarity index together with the rankings already
+// read from it.
+type similaritySnapshot struct {
+	index    *similarity.Index
+	builtAt  time.Time
+	rankings sync.Map
+}
+
+type similarPerfume struct {
+	Perfume     *internal.Perfume `json:"perfume"`
+	Score       float64           `json:"score"`
+	SharedNotes []*internal.Note  `json:"shared_notes"`
+}
+
+// similarityIndex returns the current snapshot. Only the very first call
+// waits for the index to be built; later calls get the current snapshot
+// right away and start a rebuild in the background once it is stale.
+func (app *application) similarityIndex() (*similaritySnapshot, error) {
+	cache := &app.similarPerfumes
+
+	snapshot := cache.snapshot.Load()
+	if snapshot == nil {
+		cache.building.Lock()
+		defer cache.building.Unlock()
+
+		if snapshot = cache.snapshot.Load(); snapshot != nil {
+			return snapshot, nil
+		}
+
+		return app.buildSimilarityIndex()
+	}
+
+	if time.Since(snapshot.builtAt) > similarityIndexTTL && cache.refreshing.CompareAndSwap(false, true) {
+		go func() {
+			defer cache.refreshing.Store(false)
+
+			if _, err := app.buildSimilarityIndex(); err != nil {
+				app.logger.Error(err.Error())
+			}
+		}()
+	}
+
+	return snapshot, nil
+}
+
+// buildSimilarityIndex loads the catalogue into a new snapshot and publishes
+// it.
+func (app *application) buildSimilarityIndex() (*similaritySnapshot, error) {
+	perfumes, err := app.services.Similarity.Perfumes()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &similaritySnapshot{
+		index:   similarity.NewIndex(perfumes, similarity.DefaultWeights),
+		builtAt: time.Now(),
+	}
+	app.similarPerfumes.snapshot.Store(snapshot)
+
+	return snapshot, nil
+}
+
+// similarTo returns the best matches of the perfume, computing its ranking
+// on first use.
+func (app *application) similarTo(publicId string) ([]similarity.Match, error) {
+	snapshot, err := app.similarityIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	if matches, ok := snapshot.rankings.Load(publicId); ok {
+		return matches.([]similarity.Match), nil
+	}
+
+	matches := snapshot.index.Similar(publicId, maxSimilarPerfumes)
+	snapshot.rankings.Store(publicId, matches)
+
+	return matches, nil
+}
+
+func (app *application) similarPerfumesHandler(w http.ResponseWriter, r *http.Request) {
+	perfume, err := app.services.Perfume.FindBySlug(r.PathValue("slug"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > maxSimilarPerfumes {
+		limit = 10
+	}
+
+	matches, err := app.similarTo(perfume.PublicId)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.ID)
+	}
+
+	perfumes, err := app.services.Perfume.FindAvailable(ids)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	byPublicId := make(map[string]*internal.Perfume, len(perfumes))
+	for _, similar := range perfumes {
+		byPublicId[similar.PublicId] = similar
+	}
+
+	data := make([]similarPerfume, 0, len(matches))
+	for _, match := range matches {
+		similar, ok := byPublicId[match.ID]
+		if !ok {
+			continue
+		}
+
+		data = append(data, similarPerfume{
+			Perfume:     similar,
+			Score:       math.Round(match.Score*1000) / 1000,
+			SharedNotes: sharedNotes(similar, match.SharedNotes),
+		})
+	}
+
+	res := struct {
+		Data []similarPerfume `json:"data"`
+	}{
+		Data: data,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+// sharedNotes picks the notes with the given public IDs from the perfume.
+func sharedNotes(perfume *internal.Perfume, publicIds []string) []*internal.Note {
+	wanted := make(map[string]bool, len(publicIds))
+	for _, id := range publicIds {
+		wanted[id] = true
+	}
+
+	notes := make([]*internal.Note, 0, len(publicIds))
+	for _, category := range []internal.NoteCategory{internal.TopNote, internal.MiddleNote, internal.BaseNote, internal.UncategorizedNote} {
+		for _, note := range perfume.Notes[category] {
+			if wanted[note.PublicId] {
+				notes = append(notes, note)
+			}
+		}
+	}
+
+	return notes
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+var errInvalidSubmission = errors.New("submission payload is invalid")
+
+type createSubmissionRequest struct {
+	Type    string          `json:"type" validate:"required,oneof=perfume house note"`
+	Payload json.RawMessage `json:"payload" validate:"required"`
+}
+
+type rejectSubmissionRequest struct {
+	Comment string `json:"comment" validate:"required"`
+}
+
+// submissionPayload returns the create request matching the entity type of a
+// submission, ready to be unmarshalled into.
+func submissionPayload(entityType internal.EntityType) any {
+	switch entityType {
+	case internal.PerfumeEntity:
+		return &createPerfumeRequest{}
+	case internal.HouseEntity:
+		return &createHouseRequest{}
+	case internal.NoteEntity:
+		return &createNoteRequest{}
+	default:
+		return nil
+	}
+}
+
+// decodeSubmissionPayload unmarshals and validates the payload of a
+// submission. It returns validation errors when the payload is unusable.
+func (app *application) decodeSubmissionPayload(entityType internal.EntityType, payload json.RawMessage) (any, *ValidationErrors) {
+	req := submissionPayload(entityType)
+
+	if err := json.Unmarshal(payload, req); err != nil {
+		validationErrors := NewValidationErrors()
+		validationErrors.AddError("payload", "The payload field must be a valid "+entityType.String()+".")
+		return nil, validationErrors
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		return nil, CreateResponseFromErrors(err)
+	}
+
+	return req, nil
+}
+
+func (app *application) createSubmissionHandler(w http.ResponseWriter, r *http.Request) {
+	var req createSubmissionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	entityType, _ := internal.EntityTypeFromString(req.Type)
+
+	payload, validationErrors := app.decodeSubmissionPayload(entityType, req.Payload)
+	if validationErrors != nil {
+		app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	// The payload is stored in its normalized create request shape.
+	normalized, err := json.Marshal(payload)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	submission, err := app.factory.NewSubmission(entityType, normalized, app.contextGetUser(r))
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	if err := app.services.Submission.Save(submission); err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.JSONResponse(w, submission, http.StatusCreated, nil)
+}
+
+func (app *application) listSubmissions(w http.ResponseWriter, r *http.Request, filter internal.SubmissionFilter) {
+	cursor := r.URL.Query().Get("cursor")
+	var id = 0
+
+	if cursor != "" {
+		decrypted, err := app.Decrypt(cursor)
+		if err == nil {
+			id, _ = strconv.Atoi(string(decrypted))
+		}
+	}
+
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 || perPage > 100 {
+		perPage = 25
+	}
+
+	submissions, err := app.services.Submission.List(filter, id, perPage)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	var newCursor string
+	if len(submissions) == perPage {
+		lastSubmission := submissions[len(submissions)-1]
+		newCursor, _ = app.Encrypt([]byte(strconv.Itoa(lastSubmission.ID)))
+	}
+
+	res := Paginated[internal.Submission]{
+		Data: submissions,
+		Next: newCursor,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+// listSubmissionsHandler is the moderator queue. It lists pending submissions
+// unless another status is requested.
+func (app *application) listSubmissionsHandler(w http.ResponseWriter, r *http.Request) {
+	status := internal.SubmissionPending
+
+	if value := r.URL.Query().Get("status"); value != "" {
+		parsed, err := internal.SubmissionStatusFromString(value)
+		if err != nil {
+			res := NewValidationErrors()
+			res.AddError("status", "The selected status is invalid.")
+			app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+			return
+		}
+
+		status = parsed
+	}
+
+	app.listSubmissions(w, r, internal.SubmissionFilter{Status: status})
+}
+
+func (app *application) listOwnSubmissionsHandler(w http.ResponseWriter, r *http.Request) {
+	app.listSubmissions(w, r, internal.SubmissionFilter{SubmitterId: app.contextGetUser(r).ID})
+}
+
+// showSubmissionHandler shows a submission to its submitter and to editors.
+func (app *application) showSubmissionHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	submission, err := app.services.Submission.Find(r.PathValue("publicId"))
+	if err != nil || (submission.SubmitterId != user.ID && !user.Can(internal.RoleEditor)) {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	app.JSONResponse(w, submission, http.StatusOK, nil)
+}
+
+// approveSubmissionHandler creates the submitted entity through the regular
+// factory and Save path and marks the submission as approved, all in one
+// transaction.
+func (app *application) approveSubmissionHandler(w http.ResponseWriter, r *http.Request) {
+	var submission *internal.Submission
+	var validationErrors *ValidationErrors
+
+	reviewer := app.contextGetUser(r)
+	meta := app.changeMeta(r)
+	if meta.Reason == "" {
+		meta.Reason = "Approved submission " + r.PathValue("publicId")
+	}
+
+	err := app.services.Transaction(func(tx *postgresql.Services) error {
+		var err error
+
+		submission, err = tx.Submission.Find(r.PathValue("publicId"))
+		if err != nil {
+			return err
+		}
+
+		if submission.Status != internal.SubmissionPending {
+			return internal.ErrSubmissionNotPending
+		}
+
+		var entityId string
+		entityId, validationErrors, err = app.createFromSubmission(tx, submission, meta)
+		if err != nil {
+			return err
+		}
+
+		if validationErrors != nil {
+			return errInvalidSubmission
+		}
+
+		if err := submission.Approve(reviewer, entityId); err != nil {
+			return err
+		}
+
+		return tx.Submission.Review(submission)
+	})
+
+	switch {
+	case err == nil:
+		app.JSONResponse(w, submission, http.StatusOK, nil)
+	case errors.Is(err, postgresql.ErrSubmissionNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	case errors.Is(err, internal.ErrSubmissionNotPending):
+		app.JSONResponse(w, ResponseMessage{Message: "Submission has already been reviewed.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	case errors.Is(err, errInvalidSubmission):
+		app.JSONResponse(w, validationErrors, http.StatusUnprocessableEntity, nil)
+	case errors.Is(err, postgresql.ErrPerfumeAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "Perfume already exists.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	case errors.Is(err, postgresql.ErrHouseAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "House already exists.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	case errors.Is(err, postgresql.ErrNoteAlreadyExists):
+		app.JSONResponse(w, ResponseMessage{Message: "Note already exists.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+// createFromSubmission saves the entity described by the submission payload
+// and returns its public ID.
+func (app *application) createFromSubmission(tx *postgresql.Services, submission *internal.Submission, meta internal.ChangeMeta) (string, *ValidationErrors, error) {
+	payload, validationErrors := app.decodeSubmissionPayload(submission.EntityType, submission.Payload)
+	if validationErrors != nil {
+		return "", validationErrors, nil
+	}
+
+	switch req := payload.(type) {
+	case *createPerfumeRequest:
+		perfume, validationErrors, err := app.newPerfume(tx, *req)
+		if err != nil || validationErrors != nil {
+			return "", validationErrors, err
+		}
+
+		if err := tx.Perfume.Save(perfume, meta); err != nil {
+			return "", nil, err
+		}
+
+		return perfume.PublicId, nil, nil
+	case *createHouseRequest:
+		yearFounded := time.Date(req.YearFounded, time.January, 1, 0, 0, 0, 0, time.UTC)
+		house, err := app.factory.NewHouse(req.Name, req.Country, req.Description, yearFounded)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if err := tx.House.Save(house, meta); err != nil {
+			return "", nil, err
+		}
+
+		return house.PublicId, nil, nil
+	case *createNoteRequest:
+		note, err := app.factory.NewNote(req.Name, req.Description, req.ImageUrl, req.NoteGroupId)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if err := tx.Note.Save(note, meta); err != nil {
+			if errors.Is(err, postgresql.ErrNoteGroupNotFound) {
+				validationErrors := NewValidationErrors()
+				validationErrors.AddError("note_group_id", "Note Group does not exist.")
+				return "", validationErrors, nil
+			}
+
+			return "", nil, err
+		}
+
+		return note.PublicId, nil, nil
+	}
+
+	return "", nil, errInvalidSubmission
+}
+
+func (app *application) rejectSubmissionHandler(w http.ResponseWriter, r *http.Request) {
+	var req rejectSubmissionRequest
+
+	submission, err := app.services.Submission.Find(r.PathValue("publicId"))
+	if err != nil {
+		if errors.Is(err, postgresql.ErrSubmissionNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	err = submission.Reject(app.contextGetUser(r), req.Comment)
+	if err == nil {
+		err = app.services.Submission.Review(submission)
+	}
+
+	switch {
+	case err == nil:
+		app.JSONResponse(w, submission, http.StatusOK, nil)
+	case errors.Is(err, internal.ErrSubmissionNotPending):
+		app.JSONResponse(w, ResponseMessage{Message: "Submission has already been reviewed.", StatusCode: http.StatusConflict}, http.StatusConflict, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+type createTokenRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	Name     string `json:"name" validate:"required,max=100"`
+	TTLDays  int    `json:"ttl_days" validate:"omitempty,gte=1,lte=365"`
+}
+
+// createTokenHandler exchanges a username (or email) and password for a new
+// personal API token. The plaintext token is only ever returned here.
+func (app *application) createTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var req createTokenRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	user, err := app.services.User.FindByUsername(req.Username)
+	if errors.Is(err, postgresql.ErrUserNotFound) {
+		user, err = app.services.User.FindByEmail(req.Username)
+	}
+
+	if err != nil {
+		if errors.Is(err, postgresql.ErrUserNotFound) {
+			app.InvalidCredentials(w)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	matches, err := user.PasswordMatches(req.Password)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	if !matches {
+		app.InvalidCredentials(w)
+		return
+	}
+
+	token, err := app.factory.NewToken(user.ID, req.Name, time.Duration(req.TTLDays)*24*time.Hour)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	if err := app.services.Token.Save(token); err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.JSONResponse(w, token, http.StatusCreated, nil)
+}
+
+func (app *application) listTokensHandler(w http.ResponseWriter, r *http.Request) {
+	tokens, err := app.services.Token.ListForUser(app.contextGetUser(r).ID)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.JSONResponse(w, struct {
+		Data []internal.Token `json:"data"`
+	}{Data: tokens}, http.StatusOK, nil)
+}
+
+func (app *application) revokeTokenHandler(w http.ResponseWriter, r *http.Request) {
+	err := app.services.Token.Revoke(app.contextGetUser(r).ID, r.PathValue("publicId"))
+
+	switch {
+	case err == nil:
+		app.NoContent(w, http.StatusNoContent)
+	case errors.Is(err, postgresql.ErrTokenNotFound):
+		app.NoContent(w, http.StatusNotFound)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
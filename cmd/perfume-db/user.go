@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+type registerUserRequest struct {
+	Username string `json:"username" validate:"required,alphanum,min=3,max=32"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Request) {
+	var req registerUserRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	user, err := app.factory.NewUser(req.Username, req.Email, req.Password)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	err = app.services.User.Save(user)
+
+	switch {
+	case err == nil:
+		app.JSONResponse(w, user, http.StatusCreated, nil)
+	case errors.Is(err, postgresql.ErrDuplicateUsername):
+		res := NewValidationErrors()
+		res.AddError("username", "The username has already been taken.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+	case errors.Is(err, postgresql.ErrDuplicateEmail):
+		res := NewValidationErrors()
+		res.AddError("email", "The email has already been registered.")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+	default:
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+	}
+}
+
+func (app *application) showCurrentUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.JSONResponse(w, app.contextGetUser(r), http.StatusOK, nil)
+}
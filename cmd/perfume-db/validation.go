@@ -25,6 +25,51 @@ func (validationErrors *ValidationErrors) AddError(field, message string) {
 	validationErrors.Errors[field] = append(validationErrors.Errors[field], message)
 }
 
+// newValidator returns the validator the handlers use, with the custom rules
+// registered and merge patch fields unwrapped.
+func newValidator() *validator.Validate {
+	validatorInstance := validator.New(validator.WithRequiredStructEnabled())
+	if err := validatorInstance.RegisterValidation("ymd-date-format", (&DateValidator{}).Validate); err != nil {
+		panic(err)
+	}
+
+	if err := validatorInstance.RegisterValidation("rfc3339-timestamp", (&TimestampValidator{}).Validate); err != nil {
+		panic(err)
+	}
+
+	if err := validatorInstance.RegisterValidation("fragranceConcentration", (&FragranceConcentrationValidator{}).Validate); err != nil {
+		panic(err)
+	}
+
+	if err := validatorInstance.RegisterValidation("noteCategory", (&NoteCategoriesValidator{}).Validate); err != nil {
+		panic(err)
+	}
+
+	if err := validatorInstance.RegisterValidation("noteCount", (&NoteCountValidator{}).Validate); err != nil {
+		panic(err)
+	}
+
+	if err := validatorInstance.RegisterValidation("variantType", (&VariantTypeValidator{}).Validate); err != nil {
+		panic(err)
+	}
+
+	if err := validatorInstance.RegisterValidation("barcode", (&BarcodeValidator{}).Validate); err != nil {
+		panic(err)
+	}
+
+	validatorInstance.RegisterCustomTypeFunc(
+		optionalValue,
+		Optional[string]{},
+		Optional[int]{},
+		Optional[float64]{},
+		Optional[[]string]{},
+		Optional[map[string][]string]{},
+		Nullable[int]{},
+	)
+
+	return validatorInstance
+}
+
 func CreateResponseFromErrors(err error) *ValidationErrors {
 	response := NewValidationErrors()
 
@@ -57,6 +102,21 @@ func CreateResponseFromErrors(err error) *ValidationErrors {
 		case "min":
 			message := fmt.Sprintf("The %s field must have a minimum count of %s", field, err.Param())
 			response.AddError(jsonTag, message)
+		case "max":
+			message := fmt.Sprintf("The %s field must not be greater than %s characters.", field, err.Param())
+			response.AddError(jsonTag, message)
+		case "email":
+			message := fmt.Sprintf("The %s field must be a valid email address.", field)
+			response.AddError(jsonTag, message)
+		case "alphanum":
+			message := fmt.Sprintf("The %s field must only contain letters and numbers.", field)
+			response.AddError(jsonTag, message)
+		case "iso4217":
+			message := fmt.Sprintf("The %s field must be a valid ISO 4217 currency code.", field)
+			response.AddError(jsonTag, message)
+		case "oneof":
+			message := fmt.Sprintf("The selected %s is invalid.", field)
+			response.AddError(jsonTag, message)
 		case "fragranceConcentration":
 			message := fmt.Sprintf("The selected %s is invalid", field)
 			response.AddError(jsonTag, message)
@@ -66,6 +126,15 @@ func CreateResponseFromErrors(err error) *ValidationErrors {
 		case "noteCount":
 			message := fmt.Sprintf("The %s field must have a minimum count of %s.", field, err.Param())
 			response.AddError(jsonTag, message)
+		case "variantType":
+			message := fmt.Sprintf("The selected %s is invalid.", field)
+			response.AddError(jsonTag, message)
+		case "barcode":
+			message := fmt.Sprintf("The %s field must be a valid EAN, UPC or GTIN barcode.", field)
+			response.AddError(jsonTag, message)
+		case "rfc3339-timestamp":
+			message := fmt.Sprintf("The %s field must be a valid timestamp format 'YYYY-MM-DDTHH:MM:SSZ'.", field)
+			response.AddError(jsonTag, message)
 		}
 	}
 
@@ -106,6 +175,14 @@ func (dv DateValidator) Validate(fl validator.FieldLevel) bool {
 	return err == nil
 }
 
+type TimestampValidator struct{}
+
+func (tv TimestampValidator) Validate(fl validator.FieldLevel) bool {
+	_, err := time.Parse(time.RFC3339, fl.Field().String())
+
+	return err == nil
+}
+
 type FragranceConcentrationValidator struct{}
 
 func (validator FragranceConcentrationValidator) Validate(fl validator.FieldLevel) bool {
@@ -149,3 +226,17 @@ func (validator NoteCountValidator) Validate(fl validator.FieldLevel) bool {
 
 	return true
 }
+
+type VariantTypeValidator struct{}
+
+func (validator VariantTypeValidator) Validate(fl validator.FieldLevel) bool {
+	_, err := internal.VariantTypeFromString(fl.Field().String())
+
+	return err == nil
+}
+
+type BarcodeValidator struct{}
+
+func (validator BarcodeValidator) Validate(fl validator.FieldLevel) bool {
+	return internal.ValidBarcode(fl.Field().String())
+}
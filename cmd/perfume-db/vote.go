@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+type castVoteRequest struct {
+	Value string `json:"value" validate:"required"`
+}
+
+// castVoteHandler records the user's vote on one dimension of a perfume,
+// replacing the previous one.
+func (app *application) castVoteHandler(w http.ResponseWriter, r *http.Request) {
+	var req castVoteRequest
+
+	dimension, err := internal.VoteDimensionFromString(r.PathValue("dimension"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	perfume, err := app.services.Perfume.Find(r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	vote, err := internal.NewVote(perfume.PublicId, app.contextGetUser(r), dimension, req.Value)
+	if err != nil {
+		res := NewValidationErrors()
+		res.AddError("value", "The selected value is invalid, expected one of: "+strings.Join(dimension.Values(), ", ")+".")
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	if err := app.services.Vote.Cast(vote); err != nil {
+		if errors.Is(err, postgresql.ErrPerfumeNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.JSONResponse(w, vote, http.StatusOK, nil)
+}
+
+func (app *application) retractVoteHandler(w http.ResponseWriter, r *http.Request) {
+	dimension, err := internal.VoteDimensionFromString(r.PathValue("dimension"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	err = app.services.Vote.Retract(r.PathValue("publicId"), app.contextGetUser(r).ID, dimension)
+	if err != nil {
+		if errors.Is(err, postgresql.ErrVoteNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.NoContent(w, http.StatusNoContent)
+}
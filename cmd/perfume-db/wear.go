@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/postgresql"
+)
+
+type createWearRequest struct {
+	PerfumeId string `json:"perfume_id" validate:"required"`
+	WornOn    string `json:"worn_on" validate:"omitempty,ymd-date-format"`
+	Occasion  string `json:"occasion" validate:"max=100"`
+	Weather   string `json:"weather" validate:"max=100"`
+	Sprays    *int   `json:"sprays" validate:"omitnil,gte=1,lte=50"`
+}
+
+type wearStatsRequest struct {
+	From string `validate:"omitempty,ymd-date-format"`
+	To   string `validate:"omitempty,ymd-date-format"`
+}
+
+// createWearHandler logs a wearing in the user's diary. The wearing defaults
+// to today when no date is given.
+func (app *application) createWearHandler(w http.ResponseWriter, r *http.Request) {
+	var req createWearRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.logger.Error(err.Error())
+		app.BadRequest(w)
+		return
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	perfume, err := app.services.Perfume.Find(req.PerfumeId)
+	if err != nil {
+		if errors.Is(err, postgresql.ErrPerfumeNotFound) {
+			res := NewValidationErrors()
+			res.AddError("perfume_id", "Perfume does not exist.")
+			app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	wornOn := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.WornOn != "" {
+		wornOn, _ = time.Parse("2006-01-02", req.WornOn)
+	}
+
+	wear, err := app.factory.NewWear(app.contextGetUser(r), perfume.PublicId, wornOn)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	wear.Perfume = perfume
+	wear.Occasion = req.Occasion
+	wear.Weather = req.Weather
+	wear.Sprays = req.Sprays
+
+	if err := app.services.Wear.Save(wear); err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.JSONResponse(w, wear, http.StatusCreated, nil)
+}
+
+func (app *application) listWearsHandler(w http.ResponseWriter, r *http.Request) {
+	cursor := r.URL.Query().Get("cursor")
+	var id = 0
+
+	if cursor != "" {
+		decrypted, err := app.Decrypt(cursor)
+		if err == nil {
+			id, _ = strconv.Atoi(string(decrypted))
+		}
+	}
+
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 || perPage > 100 {
+		perPage = 25
+	}
+
+	wears, err := app.services.Wear.List(app.contextGetUser(r).ID, id, perPage)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	var newCursor string
+	if len(wears) == perPage {
+		lastWear := wears[len(wears)-1]
+		newCursor, _ = app.Encrypt([]byte(strconv.Itoa(lastWear.ID)))
+	}
+
+	res := Paginated[internal.Wear]{
+		Data: wears,
+		Next: newCursor,
+	}
+
+	app.JSONResponse(w, res, http.StatusOK, nil)
+}
+
+func (app *application) deleteWearHandler(w http.ResponseWriter, r *http.Request) {
+	wear, err := app.services.Wear.Find(app.contextGetUser(r).ID, r.PathValue("publicId"))
+	if err != nil {
+		app.NoContent(w, http.StatusNotFound)
+		return
+	}
+
+	if err := app.services.Wear.Delete(wear); err != nil {
+		if errors.Is(err, postgresql.ErrWearNotFound) {
+			app.NoContent(w, http.StatusNotFound)
+			return
+		}
+
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.NoContent(w, http.StatusNoContent)
+}
+
+// wearStatsHandler reports the rotation statistics of the user, optionally
+// limited to the days between the "from" and "to" query parameters.
+func (app *application) wearStatsHandler(w http.ResponseWriter, r *http.Request) {
+	req := wearStatsRequest{
+		From: r.URL.Query().Get("from"),
+		To:   r.URL.Query().Get("to"),
+	}
+
+	if err := app.validator.Struct(req); err != nil {
+		res := CreateResponseFromErrors(err)
+		app.JSONResponse(w, res, http.StatusUnprocessableEntity, nil)
+		return
+	}
+
+	var filter internal.WearStatsFilter
+	filter.From, _ = time.Parse("2006-01-02", req.From)
+	filter.To, _ = time.Parse("2006-01-02", req.To)
+
+	stats, err := app.services.Wear.Stats(app.contextGetUser(r).ID, filter)
+	if err != nil {
+		app.logger.Error(err.Error())
+		app.ServerError(w)
+		return
+	}
+
+	app.JSONResponse(w, stats, http.StatusOK, nil)
+}
@@ -0,0 +1,48 @@
+package internal
+
+import "time"
+
+const (
+	MinAccordWeight = 1
+	MaxAccordWeight = 100
+)
+
+// Accord is a recognisable scent impression such as "citrus" or "oud" that
+// notes express to some degree.
+type Accord struct {
+	ID          int       `json:"-"`
+	PublicId    string    `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (a Accord) GetID() int {
+	return a.ID
+}
+
+// NoteAccord is how strongly a note expresses an accord, from
+// MinAccordWeight to MaxAccordWeight.
+type NoteAccord struct {
+	AccordId string `json:"accord_id"`
+	Weight   int    `json:"weight"`
+}
+
+// AccordStrength is an accord of a perfume's profile. The strongest accord of
+// a perfume has a strength of 100, the others are relative to it.
+type AccordStrength struct {
+	Accord   *Accord `json:"accord"`
+	Strength int     `json:"strength"`
+}
+
+type AccordService interface {
+	List(cursor, perPage int) ([]Accord, error)
+	Save(accord *Accord) error
+	Find(publicId string) (*Accord, error)
+	FindBySlug(s string) (*Accord, error)
+	Delete(publicId string) error
+	NoteAccords(noteId string) ([]NoteAccord, error)
+	SetNoteAccords(noteId string, accords []NoteAccord) error
+}
@@ -0,0 +1,12 @@
+package internal
+
+type AutocompleteItem struct {
+	PublicId string     `json:"id"`
+	Slug     string     `json:"slug"`
+	Name     string     `json:"name"`
+	Type     EntityType `json:"type"`
+}
+
+type AutocompleteService interface {
+	Suggest(query string, types []EntityType, perTypeLimit int, threshold float64) ([]AutocompleteItem, error)
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gtinLength is the length of a GTIN-14, to which every barcode is padded so
+// that a UPC-A and the EAN-13 with a leading zero are the same product.
+const gtinLength = 14
+
+// ValidBarcode reports whether code is a GTIN-8, UPC-A (GTIN-12), EAN-13 or
+// GTIN-14 with a correct check digit.
+func ValidBarcode(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+
+	for _, char := range code {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+
+	return checkDigit(code[:len(code)-1]) == code[len(code)-1]
+}
+
+// checkDigit computes the GS1 check digit of the digits: from the right,
+// digits are weighted 3, 1, 3, 1, ... and the check digit brings the sum up
+// to a multiple of ten.
+func checkDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		digit := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			digit *= 3
+		}
+
+		sum += digit
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+// NormalizeBarcode validates the barcode and pads it to a GTIN-14.
+func NormalizeBarcode(code string) (string, error) {
+	if !ValidBarcode(code) {
+		return "", fmt.Errorf("invalid barcode: %s", code)
+	}
+
+	return strings.Repeat("0", gtinLength-len(code)) + code, nil
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidBarcode(t *testing.T) {
+	valid := []string{
+		"96385074",       // GTIN-8
+		"036000291452",   // UPC-A
+		"4006381333931",  // EAN-13
+		"3348901250146",  // EAN-13
+		"10614141000415", // GTIN-14
+	}
+
+	for _, code := range valid {
+		assert.True(t, ValidBarcode(code), code)
+	}
+
+	invalid := []string{
+		"",
+		"4006381333932",  // wrong check digit
+		"036000291453",   // wrong check digit
+		"400638133393",   // EAN-13 missing a digit
+		"40063813339311", // wrong check digit
+		"4006381a33931",
+		"123456789",
+	}
+
+	for _, code := range invalid {
+		assert.False(t, ValidBarcode(code), code)
+	}
+}
+
+func TestNormalizeBarcode(t *testing.T) {
+	upc, err := NormalizeBarcode("036000291452")
+	assert.Nil(t, err)
+	assert.Equal(t, "00036000291452", upc)
+
+	ean, err := NormalizeBarcode("0036000291452")
+	assert.Nil(t, err)
+	assert.Equal(t, upc, ean, "a UPC-A and its EAN-13 form are the same product")
+
+	_, err = NormalizeBarcode("4006381333932")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,108 @@
+// Package batchcode dates perfume bottles from the batch codes houses print on
+// them. Each house encodes production differently, so decoding is delegated
+// to a Decoder registered for the house.
+package batchcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoDecoder        = errors.New("no batch code decoder for house")
+	ErrUnrecognizedCode = errors.New("batch code not recognized")
+	ErrUnknownFormat    = errors.New("unknown batch code format")
+)
+
+// Precision is how exactly a batch code dates production. The production
+// date of a code is the first day of the period it names.
+type Precision string
+
+const (
+	DayPrecision   Precision = "day"
+	WeekPrecision  Precision = "week"
+	MonthPrecision Precision = "month"
+)
+
+// Production is what a batch code tells about a bottle: when it was made,
+// and in which factory. Factory is empty when the code does not name one.
+type Production struct {
+	Date      time.Time
+	Precision Precision
+	Factory   string
+}
+
+// Decoder turns the batch code of a house into its production. Codes that
+// only carry the last digit of the year are resolved to the latest matching
+// production not after asOf.
+type Decoder interface {
+	Decode(code string, asOf time.Time) (Production, error)
+}
+
+// Registry holds the decoder of each house, keyed on the house's public ID.
+type Registry struct {
+	mu       sync.RWMutex
+	decoders map[string]Decoder
+}
+
+func NewRegistry() *Registry {
+	return &Registry{decoders: make(map[string]Decoder)}
+}
+
+// ParseRegistry builds a registry from a comma separated list of
+// "houseId:format" pairs, where format names one of Formats.
+func ParseRegistry(spec string) (*Registry, error) {
+	registry := NewRegistry()
+
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		houseId, format, ok := strings.Cut(pair, ":")
+		if !ok || houseId == "" {
+			return nil, fmt.Errorf("invalid batch code decoder %q, expected houseId:format", pair)
+		}
+
+		decoder, ok := Formats[format]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+		}
+
+		registry.Register(houseId, decoder)
+	}
+
+	return registry, nil
+}
+
+// Register sets the decoder of the house, replacing any previous one.
+func (registry *Registry) Register(houseId string, decoder Decoder) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.decoders[houseId] = decoder
+}
+
+// Decoder returns the decoder of the house, if it has one.
+func (registry *Registry) Decoder(houseId string) (Decoder, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	decoder, ok := registry.decoders[houseId]
+	return decoder, ok
+}
+
+// Decode decodes a batch code of the house. Codes are matched ignoring case
+// and surrounding spaces.
+func (registry *Registry) Decode(houseId, code string, asOf time.Time) (Production, error) {
+	decoder, ok := registry.Decoder(houseId)
+	if !ok {
+		return Production{}, fmt.Errorf("%w: %s", ErrNoDecoder, houseId)
+	}
+
+	return decoder.Decode(strings.ToUpper(strings.TrimSpace(code)), asOf)
+}
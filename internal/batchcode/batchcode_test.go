@@ -0,0 +1,66 @@
+package batchcode
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Decode(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("house123", FactoryMonthYear{})
+
+	production, err := registry.Decode("house123", " a53 ", asOf)
+	assert.Nil(t, err)
+	assert.Equal(t, Production{date(2023, time.May, 1), MonthPrecision, "A"}, production)
+
+	_, err = registry.Decode("house456", "A53", asOf)
+	assert.True(t, errors.Is(err, ErrNoDecoder))
+
+	_, err = registry.Decode("house123", "3045", asOf)
+	assert.True(t, errors.Is(err, ErrUnrecognizedCode))
+}
+
+func TestParseRegistry(t *testing.T) {
+	registry, err := ParseRegistry("house123:factory-month-year, house456:year-week,")
+	assert.Nil(t, err)
+
+	decoder, ok := registry.Decoder("house123")
+	assert.True(t, ok)
+	assert.Equal(t, FactoryMonthYear{}, decoder)
+
+	decoder, ok = registry.Decoder("house456")
+	assert.True(t, ok)
+	assert.Equal(t, YearWeek{}, decoder)
+
+	_, ok = registry.Decoder("house789")
+	assert.False(t, ok)
+}
+
+func TestParseRegistry_Empty(t *testing.T) {
+	registry, err := ParseRegistry("")
+	assert.Nil(t, err)
+
+	_, ok := registry.Decoder("house123")
+	assert.False(t, ok)
+}
+
+func TestParseRegistry_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"missing format", "house123"},
+		{"missing house", ":year-week"},
+		{"unknown format", "house123:julian"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRegistry(tt.spec)
+			assert.NotNil(t, err)
+		})
+	}
+}
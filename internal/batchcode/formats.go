@@ -0,0 +1,141 @@
+package batchcode
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Formats are the decoders that ship with the package, by the name a
+// registry refers to them with. They decode formats shared by several houses.
+var Formats = map[string]Decoder{
+	"factory-month-year": FactoryMonthYear{},
+	"year-day":           YearDay{},
+	"year-week":          YearWeek{},
+}
+
+// FactoryMonthYear decodes three character codes such as "A93": a factory
+// letter, the month as 1 to 9 or A to C for October to December, and the
+// last digit of the year.
+type FactoryMonthYear struct{}
+
+func (FactoryMonthYear) Decode(code string, asOf time.Time) (Production, error) {
+	if len(code) != 3 || !isLetter(code[0]) {
+		return Production{}, unrecognized(code)
+	}
+
+	month, ok := monthDigit(code[1])
+	if !ok || !isDigit(code[2]) {
+		return Production{}, unrecognized(code)
+	}
+
+	year := latestYear(int(code[2]-'0'), asOf)
+	date := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	if date.After(asOf) {
+		date = date.AddDate(-10, 0, 0)
+	}
+
+	return Production{Date: date, Precision: MonthPrecision, Factory: code[:1]}, nil
+}
+
+// YearDay decodes codes such as "3045" or "3045B": the last digit of the
+// year, the day of the year from 001 to 366, and optionally the factory.
+type YearDay struct{}
+
+func (YearDay) Decode(code string, asOf time.Time) (Production, error) {
+	if len(code) < 4 || !digits(code[:4]) {
+		return Production{}, unrecognized(code)
+	}
+
+	day, _ := strconv.Atoi(code[1:4])
+	if day < 1 || day > 366 {
+		return Production{}, unrecognized(code)
+	}
+
+	// Only leap years have a 366th day, so the latest matching year may be
+	// older than the latest year with the digit.
+	for year := latestYear(int(code[0]-'0'), asOf); year > asOf.Year()-100; year -= 10 {
+		date := time.Date(year, time.January, day, 0, 0, 0, 0, time.UTC)
+		if date.Year() == year && !date.After(asOf) {
+			return Production{Date: date, Precision: DayPrecision, Factory: code[4:]}, nil
+		}
+	}
+
+	return Production{}, unrecognized(code)
+}
+
+// YearWeek decodes codes such as "2315" or "2315FR": the last two digits of
+// the year, the ISO week from 01 to 53, and optionally the factory.
+type YearWeek struct{}
+
+func (YearWeek) Decode(code string, asOf time.Time) (Production, error) {
+	if len(code) < 4 || !digits(code[:4]) {
+		return Production{}, unrecognized(code)
+	}
+
+	yy, _ := strconv.Atoi(code[:2])
+	week, _ := strconv.Atoi(code[2:4])
+	if week < 1 || week > 53 {
+		return Production{}, unrecognized(code)
+	}
+
+	year := asOf.Year() - (asOf.Year()%100-yy+100)%100
+	date := isoWeekStart(year, week)
+	if date.After(asOf) {
+		year -= 100
+		date = isoWeekStart(year, week)
+	}
+
+	if _, isoWeek := date.ISOWeek(); isoWeek != week {
+		return Production{}, unrecognized(code)
+	}
+
+	return Production{Date: date, Precision: WeekPrecision, Factory: code[4:]}, nil
+}
+
+// isoWeekStart returns the Monday of the ISO week of the year.
+func isoWeekStart(year, week int) time.Time {
+	// January 4th always falls in the first ISO week.
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+
+	return monday.AddDate(0, 0, (week-1)*7)
+}
+
+// latestYear returns the latest year up to asOf that ends in the digit.
+func latestYear(digit int, asOf time.Time) int {
+	return asOf.Year() - (asOf.Year()%10-digit+10)%10
+}
+
+func monthDigit(c byte) (time.Month, bool) {
+	switch {
+	case c >= '1' && c <= '9':
+		return time.Month(c - '0'), true
+	case c >= 'A' && c <= 'C':
+		return time.Month(c-'A') + time.October, true
+	default:
+		return 0, false
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func digits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func unrecognized(code string) error {
+	return fmt.Errorf("%w: %q", ErrUnrecognizedCode, code)
+}
@@ -0,0 +1,79 @@
+package batchcode
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var asOf = time.Date(2024, time.June, 15, 0, 0, 0, 0, time.UTC)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		decoder  Decoder
+		code     string
+		expected Production
+	}{
+		{"factory month year", FactoryMonthYear{}, "A53", Production{date(2023, time.May, 1), MonthPrecision, "A"}},
+		{"factory month year in current year", FactoryMonthYear{}, "B64", Production{date(2024, time.June, 1), MonthPrecision, "B"}},
+		{"factory month year after as of is a decade older", FactoryMonthYear{}, "C74", Production{date(2014, time.July, 1), MonthPrecision, "C"}},
+		{"factory month year in october", FactoryMonthYear{}, "AA9", Production{date(2019, time.October, 1), MonthPrecision, "A"}},
+		{"factory month year in december", FactoryMonthYear{}, "ZC0", Production{date(2020, time.December, 1), MonthPrecision, "Z"}},
+		{"year day", YearDay{}, "3045", Production{date(2023, time.February, 14), DayPrecision, ""}},
+		{"year day with factory", YearDay{}, "1200B", Production{date(2021, time.July, 19), DayPrecision, "B"}},
+		{"year day after as of is a decade older", YearDay{}, "4200", Production{date(2014, time.July, 19), DayPrecision, ""}},
+		{"year day on a leap day", YearDay{}, "0366", Production{date(2020, time.December, 31), DayPrecision, ""}},
+		{"year day on a leap day skips common years", YearDay{}, "2366", Production{date(2012, time.December, 31), DayPrecision, ""}},
+		{"year week", YearWeek{}, "2315", Production{date(2023, time.April, 10), WeekPrecision, ""}},
+		{"year week with factory", YearWeek{}, "2401FR", Production{date(2024, time.January, 1), WeekPrecision, "FR"}},
+		{"year week starting in the previous year", YearWeek{}, "2101", Production{date(2021, time.January, 4), WeekPrecision, ""}},
+		{"year week after as of is a century older", YearWeek{}, "2450", Production{date(1924, time.December, 8), WeekPrecision, ""}},
+		{"year week 53", YearWeek{}, "2053", Production{date(2020, time.December, 28), WeekPrecision, ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			production, err := tt.decoder.Decode(tt.code, asOf)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, production)
+		})
+	}
+}
+
+func TestFormats_Unrecognized(t *testing.T) {
+	tests := []struct {
+		name    string
+		decoder Decoder
+		code    string
+	}{
+		{"factory month year too short", FactoryMonthYear{}, "A5"},
+		{"factory month year too long", FactoryMonthYear{}, "A534"},
+		{"factory month year without factory", FactoryMonthYear{}, "153"},
+		{"factory month year with month zero", FactoryMonthYear{}, "A03"},
+		{"factory month year with month D", FactoryMonthYear{}, "AD3"},
+		{"factory month year without year", FactoryMonthYear{}, "A5X"},
+		{"year day too short", YearDay{}, "304"},
+		{"year day with day zero", YearDay{}, "3000"},
+		{"year day past the end of the year", YearDay{}, "3367"},
+		{"year day with a sign", YearDay{}, "3+45"},
+		{"year week with week zero", YearWeek{}, "2300"},
+		{"year week 53 in a year without one", YearWeek{}, "2353"},
+		{"year week with letters", YearWeek{}, "23AB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.decoder.Decode(tt.code, asOf)
+
+			assert.True(t, errors.Is(err, ErrUnrecognizedCode))
+		})
+	}
+}
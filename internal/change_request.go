@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type ChangeRequestStatus string
+
+const (
+	ChangeRequestPending  ChangeRequestStatus = "pending"
+	ChangeRequestAccepted ChangeRequestStatus = "accepted"
+	ChangeRequestRejected ChangeRequestStatus = "rejected"
+)
+
+var ChangeRequestStatusMap = map[string]ChangeRequestStatus{
+	"pending":  ChangeRequestPending,
+	"accepted": ChangeRequestAccepted,
+	"rejected": ChangeRequestRejected,
+}
+
+var ErrChangeRequestNotPending = errors.New("change request has already been reviewed")
+
+func ChangeRequestStatusFromString(s string) (ChangeRequestStatus, error) {
+	status, ok := ChangeRequestStatusMap[s]
+	if !ok {
+		return "", fmt.Errorf("unknown change request status: %s", s)
+	}
+
+	return status, nil
+}
+
+func (s ChangeRequestStatus) String() string {
+	return string(s)
+}
+
+// ChangeRequest is a suggested edit of an existing entity, stored as an
+// RFC 7386 merge patch until an editor accepts or rejects it.
+type ChangeRequest struct {
+	ID         int                 `json:"-"`
+	PublicId   string              `json:"id"`
+	EntityType EntityType          `json:"type"`
+	EntityId   string              `json:"entity_id"`
+	Patch      json.RawMessage     `json:"patch"`
+	Reason     string              `json:"reason"`
+	Status     ChangeRequestStatus `json:"status"`
+	ProposerId int                 `json:"-"`
+	Proposer   string              `json:"proposed_by"`
+	ReviewerId int                 `json:"-"`
+	Reviewer   string              `json:"reviewed_by,omitempty"`
+	Comment    string              `json:"comment,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	ReviewedAt *time.Time          `json:"reviewed_at"`
+}
+
+func (c ChangeRequest) GetID() int {
+	return c.ID
+}
+
+func (c *ChangeRequest) Accept(reviewer *User) error {
+	return c.review(reviewer, ChangeRequestAccepted, "")
+}
+
+func (c *ChangeRequest) Reject(reviewer *User, comment string) error {
+	return c.review(reviewer, ChangeRequestRejected, comment)
+}
+
+func (c *ChangeRequest) review(reviewer *User, status ChangeRequestStatus, comment string) error {
+	if c.Status != ChangeRequestPending {
+		return ErrChangeRequestNotPending
+	}
+
+	now := time.Now()
+	c.Status = status
+	c.ReviewerId = reviewer.ID
+	c.Reviewer = reviewer.Username
+	c.Comment = comment
+	c.ReviewedAt = &now
+
+	return nil
+}
+
+type ChangeRequestFilter struct {
+	Status     ChangeRequestStatus
+	ProposerId int
+	EntityType EntityType
+	EntityId   string
+}
+
+type ChangeRequestService interface {
+	Save(changeRequest *ChangeRequest) error
+	Review(changeRequest *ChangeRequest) error
+	Find(publicId string) (*ChangeRequest, error)
+	List(filter ChangeRequestFilter, cursor, perPage int) ([]ChangeRequest, error)
+}
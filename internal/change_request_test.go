@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestChangeRequest_Accept(t *testing.T) {
+	reviewer := &User{ID: 3, Username: "editor"}
+	changeRequest := &ChangeRequest{Status: ChangeRequestPending}
+
+	assert.Nil(t, changeRequest.Accept(reviewer))
+	assert.Equal(t, ChangeRequestAccepted, changeRequest.Status)
+	assert.Equal(t, 3, changeRequest.ReviewerId)
+	assert.Equal(t, "editor", changeRequest.Reviewer)
+	assert.NotNil(t, changeRequest.ReviewedAt)
+
+	assert.ErrorIs(t, changeRequest.Reject(reviewer, "no"), ErrChangeRequestNotPending)
+}
+
+func TestChangeRequest_Reject(t *testing.T) {
+	reviewer := &User{ID: 3, Username: "editor"}
+	changeRequest := &ChangeRequest{Status: ChangeRequestPending}
+
+	assert.Nil(t, changeRequest.Reject(reviewer, "The release year is correct."))
+	assert.Equal(t, ChangeRequestRejected, changeRequest.Status)
+	assert.Equal(t, "The release year is correct.", changeRequest.Comment)
+
+	assert.ErrorIs(t, changeRequest.Accept(reviewer), ErrChangeRequestNotPending)
+}
@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Shelf is one of the fixed lists a user sorts their perfumes into.
+type Shelf string
+
+const (
+	ShelfOwned    Shelf = "owned"
+	ShelfWishlist Shelf = "wishlist"
+	ShelfTried    Shelf = "tried"
+	ShelfSold     Shelf = "sold"
+)
+
+var ShelfMap = map[string]Shelf{
+	"owned":    ShelfOwned,
+	"wishlist": ShelfWishlist,
+	"tried":    ShelfTried,
+	"sold":     ShelfSold,
+}
+
+// Shelves lists every shelf in display order.
+var Shelves = []Shelf{ShelfOwned, ShelfWishlist, ShelfTried, ShelfSold}
+
+func ShelfFromString(s string) (Shelf, error) {
+	shelf, ok := ShelfMap[strings.ToLower(s)]
+	if !ok {
+		return "", fmt.Errorf("unknown shelf: %s", s)
+	}
+
+	return shelf, nil
+}
+
+func (s Shelf) String() string {
+	return string(s)
+}
+
+// CollectionItem is a perfume on one of a user's shelves. A perfume can sit on
+// several shelves of the same user, but only once per shelf.
+type CollectionItem struct {
+	ID           int        `json:"-"`
+	PublicId     string     `json:"id"`
+	UserId       int        `json:"-"`
+	Shelf        Shelf      `json:"shelf"`
+	PerfumeId    string     `json:"perfume_id"`
+	Perfume      *Perfume   `json:"perfume,omitempty"`
+	BottleSizeMl *int       `json:"bottle_size_ml"`
+	PurchaseDate *time.Time `json:"purchase_date"`
+	Price        *float64   `json:"price"`
+	Currency     string     `json:"currency"`
+	Notes        string     `json:"notes"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (c CollectionItem) GetID() int {
+	return c.ID
+}
+
+// MarshalJSON renders the purchase date in the same YYYY-MM-DD format it is
+// submitted in.
+func (c CollectionItem) MarshalJSON() ([]byte, error) {
+	type Alias CollectionItem
+
+	var purchaseDate *string
+	if c.PurchaseDate != nil {
+		formatted := c.PurchaseDate.Format("2006-01-02")
+		purchaseDate = &formatted
+	}
+
+	return json.Marshal(&struct {
+		*Alias
+		PurchaseDate *string `json:"purchase_date"`
+	}{
+		Alias:        (*Alias)(&c),
+		PurchaseDate: purchaseDate,
+	})
+}
+
+// ShelfSettings holds the visibility of a user's shelf and how many perfumes
+// it holds. Shelves are private until their owner makes them public.
+type ShelfSettings struct {
+	Shelf  Shelf `json:"shelf"`
+	Public bool  `json:"public"`
+	Count  int   `json:"count"`
+}
+
+type CollectionFilter struct {
+	UserId int
+	Shelf  Shelf
+	// PublicOnly leaves out the items of private shelves.
+	PublicOnly bool
+}
+
+type CollectionService interface {
+	Save(item *CollectionItem) error
+	Find(userId int, publicId string) (*CollectionItem, error)
+	List(filter CollectionFilter, cursor, perPage int) ([]CollectionItem, error)
+	Delete(item *CollectionItem) error
+	Shelves(userId int) ([]ShelfSettings, error)
+	SetVisibility(userId int, shelf Shelf, public bool) error
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestShelfFromString(t *testing.T) {
+	shelf, err := ShelfFromString("Wishlist")
+	assert.Nil(t, err)
+	assert.Equal(t, ShelfWishlist, shelf)
+
+	_, err = ShelfFromString("borrowed")
+	assert.NotNil(t, err)
+}
+
+func TestShelves(t *testing.T) {
+	assert.Len(t, Shelves, len(ShelfMap))
+
+	for _, shelf := range Shelves {
+		assert.Equal(t, shelf, ShelfMap[shelf.String()])
+	}
+}
+
+func TestCollectionItem_MarshalJSON(t *testing.T) {
+	purchaseDate := time.Date(2023, time.March, 14, 0, 0, 0, 0, time.UTC)
+	item := CollectionItem{Shelf: ShelfOwned, PurchaseDate: &purchaseDate}
+
+	data, err := json.Marshal(item)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"purchase_date":"2023-03-14"`)
+
+	data, err = json.Marshal(CollectionItem{Shelf: ShelfWishlist})
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"purchase_date":null`)
+}
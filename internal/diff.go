@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// FieldChange is a single changed field between two versions of an entity.
+// Nested objects are compared key by key, so Field is a dotted path such as
+// "house.name". Map keys appear as they are marshalled: the notes of a
+// perfume are keyed by NoteCategory number, so top notes are "notes.0".
+type FieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+// Diff compares the JSON representations of before and after and returns the
+// fields that differ, sorted by path. Fields missing on one side are reported
+// with a null value on that side.
+func Diff(before, after any) ([]FieldChange, error) {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]FieldChange, 0)
+	if err := diffValues("", beforeJSON, afterJSON, &changes); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes, nil
+}
+
+func diffValues(path string, before, after json.RawMessage, changes *[]FieldChange) error {
+	beforeObject, beforeIsObject := asObject(before)
+	afterObject, afterIsObject := asObject(after)
+
+	if !beforeIsObject || !afterIsObject {
+		if !jsonEqual(before, after) {
+			*changes = append(*changes, FieldChange{Field: path, From: orNull(before), To: orNull(after)})
+		}
+
+		return nil
+	}
+
+	keys := make(map[string]struct{}, len(beforeObject)+len(afterObject))
+	for key := range beforeObject {
+		keys[key] = struct{}{}
+	}
+	for key := range afterObject {
+		keys[key] = struct{}{}
+	}
+
+	for key := range keys {
+		field := key
+		if path != "" {
+			field = path + "." + key
+		}
+
+		if err := diffValues(field, beforeObject[key], afterObject[key], changes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func asObject(value json.RawMessage) (map[string]json.RawMessage, bool) {
+	trimmed := bytes.TrimSpace(value)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &object); err != nil {
+		return nil, false
+	}
+
+	return object, true
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var compactA, compactB bytes.Buffer
+
+	if err := json.Compact(&compactA, orNull(a)); err != nil {
+		return false
+	}
+
+	if err := json.Compact(&compactB, orNull(b)); err != nil {
+		return false
+	}
+
+	return bytes.Equal(compactA.Bytes(), compactB.Bytes())
+}
+
+func orNull(value json.RawMessage) json.RawMessage {
+	if len(value) == 0 {
+		return json.RawMessage("null")
+	}
+
+	return value
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := map[string]any{
+		"name":  "Sauvage",
+		"year":  2015,
+		"notes": map[string][]string{"top": {"bergamot"}, "base": {"ambroxan"}},
+		"image": "/sauvage.png",
+	}
+	after := map[string]any{
+		"name":  "Sauvage",
+		"year":  2016,
+		"notes": map[string][]string{"top": {"bergamot", "pepper"}, "base": {"ambroxan"}},
+	}
+
+	changes, err := Diff(before, after)
+	assert.Nil(t, err)
+	assert.Equal(t, []FieldChange{
+		{Field: "image", From: json.RawMessage(`"/sauvage.png"`), To: json.RawMessage("null")},
+		{Field: "notes.top", From: json.RawMessage(`["bergamot"]`), To: json.RawMessage(`["bergamot","pepper"]`)},
+		{Field: "year", From: json.RawMessage("2015"), To: json.RawMessage("2016")},
+	}, changes)
+}
+
+func TestDiff_NoChanges(t *testing.T) {
+	house := House{PublicId: "abc123", Name: "Dior"}
+
+	changes, err := Diff(house, house)
+	assert.Nil(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestDiff_Structs(t *testing.T) {
+	before := Perfumer{Name: "Francois Demachy", Nationality: "French"}
+	after := before
+	after.Nationality = "France"
+
+	changes, err := Diff(before, after)
+	assert.Nil(t, err)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "nationality", changes[0].Field)
+	assert.Equal(t, json.RawMessage(`"French"`), changes[0].From)
+	assert.Equal(t, json.RawMessage(`"France"`), changes[0].To)
+}
@@ -0,0 +1,34 @@
+package internal
+
+import "fmt"
+
+type EntityType string
+
+const (
+	HouseEntity     EntityType = "house"
+	PerfumerEntity  EntityType = "perfumer"
+	NoteEntity      EntityType = "note"
+	NoteGroupEntity EntityType = "note_group"
+	PerfumeEntity   EntityType = "perfume"
+)
+
+var EntityTypeMap = map[string]EntityType{
+	"house":      HouseEntity,
+	"perfumer":   PerfumerEntity,
+	"note":       NoteEntity,
+	"note_group": NoteGroupEntity,
+	"perfume":    PerfumeEntity,
+}
+
+func EntityTypeFromString(s string) (EntityType, error) {
+	entityType, ok := EntityTypeMap[s]
+	if !ok {
+		return "", fmt.Errorf("unknown entity type: %s", s)
+	}
+
+	return entityType, nil
+}
+
+func (e EntityType) String() string {
+	return string(e)
+}
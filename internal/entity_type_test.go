@@ -0,0 +1,24 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEntityTypeFromString(t *testing.T) {
+	house, err := EntityTypeFromString("house")
+	assert.Nil(t, err)
+	assert.Equal(t, HouseEntity, house)
+
+	noteGroup, err := EntityTypeFromString("note_group")
+	assert.Nil(t, err)
+	assert.Equal(t, NoteGroupEntity, noteGroup)
+
+	perfume, err := EntityTypeFromString("perfume")
+	assert.Nil(t, err)
+	assert.Equal(t, PerfumeEntity, perfume)
+
+	unknown, err := EntityTypeFromString("foo")
+	assert.Error(t, err, "foo")
+	assert.Equal(t, EntityType(""), unknown)
+}
@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -104,3 +106,247 @@ func (factory Factory) NewPerfume(opts ...PerfumeOption) (*Perfume, error) {
 
 	return p, nil
 }
+
+func (factory Factory) NewUser(username, email, password string) (*User, error) {
+	now := time.Now()
+	id, err := factory.IdGenerator.Generate()
+	if err != nil {
+		return &User{}, err
+	}
+
+	user := &User{
+		PublicId:  id,
+		Username:  username,
+		Email:     email,
+		Role:      RoleContributor,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := user.SetPassword(password); err != nil {
+		return &User{}, err
+	}
+
+	return user, nil
+}
+
+// NewToken creates a token for the user. A zero ttl creates a token that
+// never expires.
+func (factory Factory) NewToken(userId int, name string, ttl time.Duration) (*Token, error) {
+	now := time.Now()
+	id, err := factory.IdGenerator.Generate()
+	if err != nil {
+		return &Token{}, err
+	}
+
+	plaintext, err := generateTokenPlaintext()
+	if err != nil {
+		return &Token{}, err
+	}
+
+	token := &Token{
+		PublicId:  id,
+		UserId:    userId,
+		Name:      name,
+		Plaintext: plaintext,
+		Hash:      HashToken(plaintext),
+		CreatedAt: now,
+	}
+
+	if ttl > 0 {
+		expiresAt := now.Add(ttl)
+		token.ExpiresAt = &expiresAt
+	}
+
+	return token, nil
+}
+
+func (factory Factory) NewSubmission(entityType EntityType, payload json.RawMessage, submitter *User) (*Submission, error) {
+	id, err := factory.IdGenerator.Generate()
+	if err != nil {
+		return &Submission{}, err
+	}
+
+	return &Submission{
+		PublicId:    id,
+		EntityType:  entityType,
+		Payload:     payload,
+		Status:      SubmissionPending,
+		SubmitterId: submitter.ID,
+		Submitter:   submitter.Username,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+func (factory Factory) NewChangeRequest(entityType EntityType, entityId string, patch json.RawMessage, reason string, proposer *User) (*ChangeRequest, error) {
+	id, err := factory.IdGenerator.Generate()
+	if err != nil {
+		return &ChangeRequest{}, err
+	}
+
+	return &ChangeRequest{
+		PublicId:   id,
+		EntityType: entityType,
+		EntityId:   entityId,
+		Patch:      patch,
+		Reason:     reason,
+		Status:     ChangeRequestPending,
+		ProposerId: proposer.ID,
+		Proposer:   proposer.Username,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+func (factory Factory) NewReview(perfumeId string, author *User, score int, text string) (*Review, error) {
+	now := time.Now()
+	id, err := factory.IdGenerator.Generate()
+	if err != nil {
+		return &Review{}, err
+	}
+
+	return &Review{
+		PublicId:  id,
+		PerfumeId: perfumeId,
+		UserId:    author.ID,
+		Author:    author.Username,
+		Score:     score,
+		Text:      text,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (factory Factory) NewCollectionItem(user *User, shelf Shelf, perfumeId string) (*CollectionItem, error) {
+	now := time.Now()
+	id, err := factory.IdGenerator.Generate()
+	if err != nil {
+		return &CollectionItem{}, err
+	}
+
+	return &CollectionItem{
+		PublicId:  id,
+		UserId:    user.ID,
+		Shelf:     shelf,
+		PerfumeId: perfumeId,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (factory Factory) NewWear(user *User, perfumeId string, wornOn time.Time) (*Wear, error) {
+	id, err := factory.IdGenerator.Generate()
+	if err != nil {
+		return &Wear{}, err
+	}
+
+	return &Wear{
+		PublicId:  id,
+		UserId:    user.ID,
+		PerfumeId: perfumeId,
+		WornOn:    wornOn,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func (factory Factory) NewFamily(name, description, parentId string, noteGroupIds []string) (*Family, error) {
+	now := time.Now()
+	id, err := factory.IdGenerator.Generate()
+	if err != nil {
+		return &Family{}, err
+	}
+
+	return &Family{
+		PublicId:     id,
+		ParentId:     parentId,
+		Name:         name,
+		Slug:         CreateSlug(name),
+		Description:  description,
+		NoteGroupIds: noteGroupIds,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+func (factory Factory) NewAccord(name, description string) (*Accord, error) {
+	now := time.Now()
+	id, err := factory.IdGenerator.Generate()
+	if err != nil {
+		return &Accord{}, err
+	}
+
+	return &Accord{
+		PublicId:    id,
+		Name:        name,
+		Slug:        CreateSlug(name),
+		Description: description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+func (factory Factory) NewInspiration(perfumeId, originalId string, proposer *User) (*Inspiration, error) {
+	id, err := factory.IdGenerator.Generate()
+	if err != nil {
+		return &Inspiration{}, err
+	}
+
+	return &Inspiration{
+		PublicId:   id,
+		PerfumeId:  perfumeId,
+		OriginalId: originalId,
+		CreatedBy:  proposer.ID,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+func (factory Factory) NewPerfumeVariant(perfumeId string, variantType VariantType, barcode string) (*PerfumeVariant, error) {
+	now := time.Now()
+	id, err := factory.IdGenerator.Generate()
+	if err != nil {
+		return &PerfumeVariant{}, err
+	}
+
+	return &PerfumeVariant{
+		PublicId:  id,
+		PerfumeId: perfumeId,
+		Type:      variantType,
+		Barcode:   barcode,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (factory Factory) NewRetailer(name, website string) (*Retailer, error) {
+	now := time.Now()
+	id, err := factory.IdGenerator.Generate()
+	if err != nil {
+		return &Retailer{}, err
+	}
+
+	return &Retailer{
+		PublicId:  id,
+		Name:      name,
+		Slug:      CreateSlug(name),
+		Website:   website,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (factory Factory) NewPriceObservation(variantId, retailerId string, price float64, currency string, observedAt time.Time, source PriceSource) (*PriceObservation, error) {
+	id, err := factory.IdGenerator.Generate()
+	if err != nil {
+		return &PriceObservation{}, err
+	}
+
+	return &PriceObservation{
+		PublicId:   id,
+		VariantId:  variantId,
+		RetailerId: retailerId,
+		Price:      price,
+		Currency:   strings.ToUpper(currency),
+		Source:     source,
+		ObservedAt: observedAt,
+		CreatedAt:  time.Now(),
+	}, nil
+}
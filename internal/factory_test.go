@@ -84,3 +84,121 @@ func TestFactory_NewHouse(t *testing.T) {
 		assert.Contains(t, alphabet, string(char))
 	}
 }
+
+func TestFactory_NewUser(t *testing.T) {
+	factory := Factory{IdGenerator: nanoid.NewNanoIdGenerator("0123456789abcdefghijklmnopqrstuvwxyz", 12)}
+
+	user, err := factory.NewUser("jdoe", "jdoe@example.com", "secret-password")
+	assert.Nil(t, err)
+	assert.Equal(t, 12, len(user.PublicId))
+	assert.Equal(t, "jdoe", user.Username)
+	assert.Equal(t, "jdoe@example.com", user.Email)
+	assert.Equal(t, RoleContributor, user.Role)
+
+	matches, err := user.PasswordMatches("secret-password")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+}
+
+func TestFactory_NewToken(t *testing.T) {
+	factory := Factory{IdGenerator: nanoid.NewNanoIdGenerator("0123456789abcdefghijklmnopqrstuvwxyz", 12)}
+
+	token, err := factory.NewToken(1, "cli", time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, token.UserId)
+	assert.Equal(t, "cli", token.Name)
+	assert.Equal(t, 52, len(token.Plaintext))
+	assert.Equal(t, HashToken(token.Plaintext), token.Hash)
+	assert.NotNil(t, token.ExpiresAt)
+	assert.WithinDuration(t, token.CreatedAt.Add(time.Hour), *token.ExpiresAt, time.Second)
+
+	other, err := factory.NewToken(1, "cli", 0)
+	assert.Nil(t, err)
+	assert.Nil(t, other.ExpiresAt)
+	assert.NotEqual(t, token.Plaintext, other.Plaintext)
+}
+
+// TestFactory_PublicIds checks that the constructors give the entity a public
+// ID from the generator, along with what each of them derives or copies from
+// its arguments.
+func TestFactory_PublicIds(t *testing.T) {
+	factory := Factory{IdGenerator: nanoid.NewNanoIdGenerator("0123456789abcdefghijklmnopqrstuvwxyz", 12)}
+
+	tests := []struct {
+		name   string
+		create func(t *testing.T) (string, error)
+	}{
+		{"review", func(t *testing.T) (string, error) {
+			review, err := factory.NewReview("perfume123", &User{ID: 5, Username: "jdoe"}, 8, "Lovely dry down.")
+			assert.Equal(t, 5, review.UserId)
+			assert.Equal(t, "jdoe", review.Author)
+			assert.Equal(t, 8, review.Score)
+			assert.Nil(t, review.Longevity)
+			return review.PublicId, err
+		}},
+		{"collection item", func(t *testing.T) (string, error) {
+			item, err := factory.NewCollectionItem(&User{ID: 9}, ShelfOwned, "perfume123")
+			assert.Equal(t, 9, item.UserId)
+			assert.Equal(t, ShelfOwned, item.Shelf)
+			assert.Nil(t, item.Price)
+			return item.PublicId, err
+		}},
+		{"wear", func(t *testing.T) (string, error) {
+			wornOn := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+			wear, err := factory.NewWear(&User{ID: 4}, "perfume123", wornOn)
+			assert.Equal(t, 4, wear.UserId)
+			assert.Equal(t, wornOn, wear.WornOn)
+			return wear.PublicId, err
+		}},
+		{"family", func(t *testing.T) (string, error) {
+			family, err := factory.NewFamily("Woody Oriental", "Warm woods and resins.", "oriental123", []string{"woody123"})
+			assert.Equal(t, "woody-oriental", family.Slug)
+			assert.Equal(t, "oriental123", family.ParentId)
+			assert.True(t, family.IsSubfamily())
+			assert.Equal(t, []string{"woody123"}, family.NoteGroupIds)
+			return family.PublicId, err
+		}},
+		{"accord", func(t *testing.T) (string, error) {
+			accord, err := factory.NewAccord("Warm Spicy", "Cinnamon, clove and pepper.")
+			assert.Equal(t, "warm-spicy", accord.Slug)
+			return accord.PublicId, err
+		}},
+		{"inspiration", func(t *testing.T) (string, error) {
+			inspiration, err := factory.NewInspiration("dupe123", "original123", &User{ID: 6})
+			assert.Equal(t, "original123", inspiration.OriginalId)
+			assert.Equal(t, 6, inspiration.CreatedBy)
+			return inspiration.PublicId, err
+		}},
+		{"perfume variant", func(t *testing.T) (string, error) {
+			variant, err := factory.NewPerfumeVariant("perfume123", RefillVariant, "3348901250146")
+			assert.Equal(t, RefillVariant, variant.Type)
+			assert.Equal(t, "3348901250146", variant.Barcode)
+			assert.Nil(t, variant.SizeMl)
+			return variant.PublicId, err
+		}},
+		{"retailer", func(t *testing.T) (string, error) {
+			retailer, err := factory.NewRetailer("Notino Europe", "https://www.notino.com")
+			assert.Equal(t, "notino-europe", retailer.Slug)
+			assert.Equal(t, "https://www.notino.com", retailer.Website)
+			return retailer.PublicId, err
+		}},
+		{"price observation", func(t *testing.T) (string, error) {
+			observedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+			observation, err := factory.NewPriceObservation("variant123", "retailer123", 89.99, "eur", observedAt, PriceImported)
+			assert.Equal(t, "EUR", observation.Currency)
+			assert.Equal(t, PriceImported, observation.Source)
+			assert.Equal(t, observedAt, observation.ObservedAt)
+			assert.Nil(t, observation.PricePerMl)
+			return observation.PublicId, err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publicId, err := tt.create(t)
+
+			assert.Nil(t, err)
+			assert.Equal(t, 12, len(publicId))
+		})
+	}
+}
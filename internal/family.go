@@ -0,0 +1,39 @@
+package internal
+
+import "time"
+
+// Family is an olfactive family of the fragrance wheel, such as "Woody" or
+// "Fresh". A family with a parent is a subfamily, such as "Woody Oriental".
+// NoteGroupIds lists the note groups that are typical of the family, from
+// which the family of a perfume is proposed.
+type Family struct {
+	ID           int       `json:"-"`
+	PublicId     string    `json:"id"`
+	ParentId     string    `json:"parent_id"`
+	Name         string    `json:"name"`
+	Slug         string    `json:"slug"`
+	Description  string    `json:"description"`
+	NoteGroupIds []string  `json:"note_group_ids"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Version      int       `json:"version"`
+}
+
+func (f Family) GetID() int {
+	return f.ID
+}
+
+func (f Family) IsSubfamily() bool {
+	return f.ParentId != ""
+}
+
+type FamilyService interface {
+	List(cursor, perPage int) ([]Family, error)
+	Save(family *Family) error
+	Find(publicId string) (*Family, error)
+	FindMany(publicIds []string) ([]*Family, error)
+	FindBySlug(s string) (*Family, error)
+	Delete(publicId string) error
+	Override(perfumeId, primaryId, secondaryId string) error
+	ClearOverride(perfumeId string) error
+}
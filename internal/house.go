@@ -15,6 +15,7 @@ type House struct {
 	YearFounded time.Time `json:"year_founded"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int       `json:"version"`
 }
 
 func (h House) GetID() int {
@@ -50,9 +51,43 @@ func (h House) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (h *House) UnmarshalJSON(data []byte) error {
+	type Alias House
+
+	aux := &struct {
+		*Alias
+		YearFounded string `json:"year_founded"`
+		CreatedAt   string `json:"created_at"`
+		UpdatedAt   string `json:"updated_at"`
+	}{
+		Alias: (*Alias)(h),
+	}
+
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	var err error
+	if h.YearFounded, err = parseOptionalTime("2006", aux.YearFounded); err != nil {
+		return err
+	}
+
+	if h.CreatedAt, err = parseOptionalTime("2006-01-02T15:04:05Z07:00", aux.CreatedAt); err != nil {
+		return err
+	}
+
+	if h.UpdatedAt, err = parseOptionalTime("2006-01-02T15:04:05Z07:00", aux.UpdatedAt); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 type HouseService interface {
 	List(cursor, perPage int) ([]House, error)
-	Save(house *House) error
+	Save(house *House, meta ChangeMeta) error
 	Find(publicId string) (*House, error)
 	FindBySlug(s string) (*House, error)
+	Delete(publicId string) error
+	Restore(publicId string) error
 }
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -21,3 +22,23 @@ func TestNewHouse(t *testing.T) {
 	assert.Equal(t, yearFounded, company.YearFounded)
 	assert.Equal(t, slug, company.Slug)
 }
+
+func TestHouse_JSONRoundTrip(t *testing.T) {
+	house := House{
+		PublicId:    "abc123",
+		Slug:        "perfume-company-123",
+		Name:        "Perfume Company 123",
+		Country:     "Philippines",
+		Description: "Niche Perfume House",
+		YearFounded: time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC),
+		CreatedAt:   time.Date(2024, time.March, 10, 8, 30, 0, 0, time.UTC),
+		UpdatedAt:   time.Date(2024, time.March, 11, 8, 30, 0, 0, time.UTC),
+	}
+
+	data, err := json.Marshal(house)
+	assert.Nil(t, err)
+
+	var decoded House
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, house, decoded)
+}
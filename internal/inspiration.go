@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"math"
+	"time"
+)
+
+// inspirationPriorVotes is how many votes the note overlap of two perfumes is
+// worth before the community has voted on their link.
+const inspirationPriorVotes = 5
+
+// Inspiration links a perfume to the original it imitates, such as a cheap
+// dupe to the designer perfume it clones. The community votes on whether the
+// link holds.
+type Inspiration struct {
+	ID          int       `json:"-"`
+	PublicId    string    `json:"id"`
+	PerfumeId   string    `json:"perfume_id"`
+	OriginalId  string    `json:"original_id"`
+	CreatedBy   int       `json:"-"`
+	Agrees      int       `json:"agrees"`
+	Disagrees   int       `json:"disagrees"`
+	NoteOverlap float64   `json:"note_overlap"`
+	Confidence  float64   `json:"confidence"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (i Inspiration) GetID() int {
+	return i.ID
+}
+
+// InspirationConfidence returns how likely the link holds, from 0 to 1. The
+// note overlap of the two perfumes counts as inspirationPriorVotes votes
+// that agree in proportion to the overlap, so that a link starts out at its
+// overlap and moves towards the share of agreeing votes as votes come in.
+func InspirationConfidence(agrees, disagrees int, noteOverlap float64) float64 {
+	overlap := math.Min(math.Max(noteOverlap, 0), 1)
+	votes := float64(agrees + disagrees)
+
+	return (float64(agrees) + inspirationPriorVotes*overlap) / (votes + inspirationPriorVotes)
+}
+
+type InspirationService interface {
+	Save(inspiration *Inspiration) error
+	Find(publicId string) (*Inspiration, error)
+	Delete(inspiration *Inspiration) error
+	Vote(inspiration *Inspiration, userId int, agrees bool) error
+	RetractVote(inspiration *Inspiration, userId int) error
+	Dupes(originalId string) ([]Inspiration, error)
+	InspiredBy(perfumeId string) ([]Inspiration, error)
+}
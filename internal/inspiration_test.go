@@ -0,0 +1,22 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInspirationConfidence(t *testing.T) {
+	assert.InDelta(t, 0.6, InspirationConfidence(0, 0, 0.6), 1e-9, "without votes the overlap decides")
+	assert.InDelta(t, 0.8, InspirationConfidence(5, 0, 0.6), 1e-9)
+	assert.InDelta(t, 0.3, InspirationConfidence(0, 5, 0.6), 1e-9)
+	assert.InDelta(t, 1.0, InspirationConfidence(3, 0, 1.4), 1e-9, "the overlap is capped at 1")
+	assert.InDelta(t, 0.0, InspirationConfidence(0, 2, -0.5), 1e-9)
+}
+
+func TestInspirationConfidence_VotesOutweighOverlap(t *testing.T) {
+	few := InspirationConfidence(1, 0, 0.1)
+	many := InspirationConfidence(100, 0, 0.1)
+
+	assert.Less(t, few, many)
+	assert.Greater(t, many, 0.95)
+}
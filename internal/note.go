@@ -12,6 +12,7 @@ type Note struct {
 	NoteGroupId string    `json:"note_group_id"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int       `json:"version"`
 }
 
 func (n Note) GetID() int {
@@ -33,8 +34,10 @@ func NewNote(Name, Description, ImageURL, NoteGroupId string) *Note {
 
 type NoteService interface {
 	List(cursor, perPage int) ([]Note, error)
-	Save(note *Note) error
+	Save(note *Note, meta ChangeMeta) error
 	Find(publicId string) (*Note, error)
 	FindBySlug(s string) (*Note, error)
 	FindMany(publicIds []string) ([]*Note, error)
+	Delete(publicId string) error
+	Restore(publicId string) error
 }
@@ -11,6 +11,7 @@ type NoteGroup struct {
 	ImageURL    string    `json:"image_url"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int       `json:"version"`
 }
 
 func (n NoteGroup) GetID() int {
@@ -31,7 +32,9 @@ func NewNoteGroup(Name, Description, ImageURL string) *NoteGroup {
 
 type NoteGroupService interface {
 	List(cursor, perPage int) ([]NoteGroup, error)
-	Save(noteGroup *NoteGroup) error
+	Save(noteGroup *NoteGroup, meta ChangeMeta) error
 	Find(publicId string) (*NoteGroup, error)
 	FindBySlug(s string) (*NoteGroup, error)
+	Delete(publicId string) error
+	Restore(publicId string) error
 }
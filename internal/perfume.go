@@ -16,10 +16,22 @@ type Perfume struct {
 	House            *House                   `json:"house"`
 	Perfumers        []*Perfumer              `json:"perfumers"`
 	Notes            map[NoteCategory][]*Note `json:"notes"`
+	Rating           Rating                   `json:"rating"`
+	Votes            VoteDistribution         `json:"votes"`
+	Accords          []AccordStrength         `json:"accords"`
+	Relations        []RelatedPerfume         `json:"relations"`
+	PrimaryFamily    *Family                  `json:"primary_family"`
+	SecondaryFamily  *Family                  `json:"secondary_family"`
+	FamilyOverridden bool                     `json:"family_overridden"`
 	YearReleased     time.Time                `json:"year_released"`
 	YearDiscontinued time.Time                `json:"year_discontinued"`
 	CreatedAt        time.Time                `json:"created_at"`
 	UpdatedAt        time.Time                `json:"updated_at"`
+	Version          int                      `json:"version"`
+}
+
+func (p Perfume) GetID() int {
+	return p.ID
 }
 
 func (p Perfume) MarshalJSON() ([]byte, error) {
@@ -43,6 +55,33 @@ func (p Perfume) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (p *Perfume) UnmarshalJSON(data []byte) error {
+	type Alias Perfume
+
+	aux := &struct {
+		*Alias
+		YearReleased     string `json:"year_released"`
+		YearDiscontinued string `json:"year_discontinued"`
+	}{
+		Alias: (*Alias)(p),
+	}
+
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	var err error
+	if p.YearReleased, err = parseOptionalTime("2006", aux.YearReleased); err != nil {
+		return err
+	}
+
+	if p.YearDiscontinued, err = parseOptionalTime("2006", aux.YearDiscontinued); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 type PerfumeOption func(*Perfume)
 
 func WithName(name string) PerfumeOption {
@@ -99,10 +138,26 @@ func WithYearDiscontinued(yearDiscontinued time.Time) PerfumeOption {
 	}
 }
 
+type PerfumeFilter struct {
+	HouseId          string
+	PerfumerId       string
+	NoteIds          []string
+	Concentration    *Concentration
+	YearReleasedFrom int
+	YearReleasedTo   int
+	Discontinued     *bool
+	// AccordSlug keeps the perfumes whose profile has the accord with a
+	// strength of at least MinAccordStrength.
+	AccordSlug        string
+	MinAccordStrength int
+}
+
 type PerfumeService interface {
-	List(cursor, perPage int) ([]*Perfume, error)
-	Save(note *Perfume) error
+	List(cursor, perPage int, filter PerfumeFilter) ([]*Perfume, error)
+	Save(perfume *Perfume, meta ChangeMeta) error
 	Find(publicId string) (*Perfume, error)
 	FindBySlug(s string) (*Perfume, error)
 	FindMany(publicIds []string) ([]*Perfume, error)
+	Delete(publicId string) error
+	Restore(publicId string) error
 }
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PerfumeRelationType is how a perfume relates to another one. Relations are
+// directed: in "A flanker_of B", B is the original perfume.
+type PerfumeRelationType string
+
+const (
+	FlankerOf        PerfumeRelationType = "flanker_of"
+	ReformulationOf  PerfumeRelationType = "reformulation_of"
+	ReissueOf        PerfumeRelationType = "reissue_of"
+	PartOfCollection PerfumeRelationType = "part_of_collection"
+)
+
+var PerfumeRelationTypeMap = map[string]PerfumeRelationType{
+	"flanker_of":         FlankerOf,
+	"reformulation_of":   ReformulationOf,
+	"reissue_of":         ReissueOf,
+	"part_of_collection": PartOfCollection,
+}
+
+// inverseRelations names the relations as seen from the related perfume.
+var inverseRelations = map[PerfumeRelationType]string{
+	FlankerOf:        "has_flanker",
+	ReformulationOf:  "reformulated_as",
+	ReissueOf:        "reissued_as",
+	PartOfCollection: "collection_includes",
+}
+
+func PerfumeRelationTypeFromString(s string) (PerfumeRelationType, error) {
+	relationType, ok := PerfumeRelationTypeMap[strings.ToLower(strings.ReplaceAll(s, "-", "_"))]
+	if !ok {
+		return "", fmt.Errorf("unknown perfume relation type: %s", s)
+	}
+
+	return relationType, nil
+}
+
+func (t PerfumeRelationType) String() string {
+	return string(t)
+}
+
+// Inverse returns the name of the relation as seen from the related perfume,
+// such as "has_flanker" for "flanker_of".
+func (t PerfumeRelationType) Inverse() string {
+	return inverseRelations[t]
+}
+
+type PerfumeRelation struct {
+	PerfumeId string              `json:"perfume_id"`
+	RelatedId string              `json:"related_id"`
+	Type      PerfumeRelationType `json:"type"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+func NewPerfumeRelation(perfumeId string, relationType PerfumeRelationType, relatedId string) *PerfumeRelation {
+	return &PerfumeRelation{
+		PerfumeId: perfumeId,
+		RelatedId: relatedId,
+		Type:      relationType,
+		CreatedAt: time.Now(),
+	}
+}
+
+// PerfumeReference identifies a perfume without its relations, for listing
+// the perfumes related to another one.
+type PerfumeReference struct {
+	PublicId string `json:"id"`
+	Slug     string `json:"slug"`
+	Name     string `json:"name"`
+}
+
+// RelatedPerfume is a relation as shown on a perfume. Relation is the type of
+// the relation when the perfume is its source, and the inverse name of the
+// type when the perfume is its target.
+type RelatedPerfume struct {
+	Relation string           `json:"relation"`
+	Perfume  PerfumeReference `json:"perfume"`
+}
+
+type PerfumeRelationService interface {
+	Relate(relation *PerfumeRelation) error
+	Unrelate(relation *PerfumeRelation) error
+}
@@ -0,0 +1,28 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPerfumeRelationTypeFromString(t *testing.T) {
+	flanker, err := PerfumeRelationTypeFromString("flanker_of")
+	assert.Nil(t, err)
+	assert.Equal(t, FlankerOf, flanker)
+
+	collection, err := PerfumeRelationTypeFromString("Part-Of-Collection")
+	assert.Nil(t, err)
+	assert.Equal(t, PartOfCollection, collection)
+
+	_, err = PerfumeRelationTypeFromString("sibling_of")
+	assert.NotNil(t, err)
+}
+
+func TestPerfumeRelationType_Inverse(t *testing.T) {
+	for _, relationType := range PerfumeRelationTypeMap {
+		assert.NotEmpty(t, relationType.Inverse())
+	}
+
+	assert.Equal(t, "has_flanker", FlankerOf.Inverse())
+	assert.Equal(t, "reformulated_as", ReformulationOf.Inverse())
+}
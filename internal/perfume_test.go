@@ -1,11 +1,22 @@
 package internal
 
 import (
+	"encoding/json"
+	"github.com/ej-agas/perfume-db/nanoid"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
 )
 
+func newPerfume(t *testing.T, opts ...PerfumeOption) *Perfume {
+	factory := Factory{IdGenerator: nanoid.NewNanoIdGenerator("0123456789abcdefghijklmnopqrstuvwxyz", 12)}
+
+	perfume, err := factory.NewPerfume(opts...)
+	assert.Nil(t, err)
+
+	return perfume
+}
+
 func TestNewPerfume(t *testing.T) {
 	Slug := "perfume-abc-eau-de-parfum"
 	Name := "Perfume ABC"
@@ -18,7 +29,7 @@ func TestNewPerfume(t *testing.T) {
 	house := &House{ID: 1000}
 	perfumers := []*Perfumer{{ID: 1}, {ID: 2}, {ID: 3}}
 
-	perfume := NewPerfume(
+	perfume := newPerfume(t,
 		WithName(Name),
 		WithDescription(Description),
 		WithConcentration(concentration),
@@ -40,3 +51,31 @@ func TestNewPerfume(t *testing.T) {
 	assert.Equal(t, house, perfume.House)
 	assert.Equal(t, perfumers, perfume.Perfumers)
 }
+
+func TestPerfume_JSONRoundTrip(t *testing.T) {
+	perfume := newPerfume(t,
+		WithName("Perfume ABC"),
+		WithConcentration(EauDeParfum),
+		WithYearReleased(time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		WithHouse(&House{PublicId: "house1", YearFounded: time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC)}),
+		WithPerfumers(&Perfumer{PublicId: "perfumer1"}),
+		WithNotes(map[NoteCategory][]*Note{
+			TopNote:  {{PublicId: "bergamot"}},
+			BaseNote: {{PublicId: "vetiver"}, {PublicId: "musk"}},
+		}),
+	)
+
+	data, err := json.Marshal(perfume)
+	assert.Nil(t, err)
+
+	var decoded Perfume
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, perfume.Name, decoded.Name)
+	assert.Equal(t, perfume.Concentration, decoded.Concentration)
+	assert.Equal(t, perfume.YearReleased, decoded.YearReleased)
+	assert.True(t, decoded.YearDiscontinued.IsZero())
+	assert.Equal(t, "house1", decoded.House.PublicId)
+	assert.Equal(t, "perfumer1", decoded.Perfumers[0].PublicId)
+	assert.Equal(t, "bergamot", decoded.Notes[TopNote][0].PublicId)
+	assert.Len(t, decoded.Notes[BaseNote], 2)
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// VariantType is the kind of product a perfume is sold as.
+type VariantType string
+
+const (
+	BottleVariant    VariantType = "bottle"
+	RefillVariant    VariantType = "refill"
+	TravelVariant    VariantType = "travel"
+	MiniatureVariant VariantType = "miniature"
+	TesterVariant    VariantType = "tester"
+	GiftSetVariant   VariantType = "gift_set"
+)
+
+var VariantTypeMap = map[string]VariantType{
+	"bottle":    BottleVariant,
+	"refill":    RefillVariant,
+	"travel":    TravelVariant,
+	"miniature": MiniatureVariant,
+	"tester":    TesterVariant,
+	"gift_set":  GiftSetVariant,
+}
+
+func VariantTypeFromString(s string) (VariantType, error) {
+	variantType, ok := VariantTypeMap[strings.ToLower(s)]
+	if !ok {
+		return "", fmt.Errorf("unknown variant type: %s", s)
+	}
+
+	return variantType, nil
+}
+
+func (t VariantType) String() string {
+	return string(t)
+}
+
+// PerfumeVariant is a product a perfume is sold as, such as the 100 ml
+// bottle or a gift set. Gift sets may leave the size empty. The barcode is
+// stored as entered, and looked up by its GTIN-14 form.
+type PerfumeVariant struct {
+	ID             int         `json:"-"`
+	PublicId       string      `json:"id"`
+	PerfumeId      string      `json:"perfume_id"`
+	Type           VariantType `json:"type"`
+	SizeMl         *int        `json:"size_ml"`
+	Barcode        string      `json:"barcode"`
+	LaunchedOn     *time.Time  `json:"launched_on"`
+	DiscontinuedOn *time.Time  `json:"discontinued_on"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+func (v PerfumeVariant) GetID() int {
+	return v.ID
+}
+
+// MarshalJSON renders the launch and discontinuation dates in the same
+// YYYY-MM-DD format they are submitted in.
+func (v PerfumeVariant) MarshalJSON() ([]byte, error) {
+	type Alias PerfumeVariant
+
+	format := func(t *time.Time) *string {
+		if t == nil {
+			return nil
+		}
+
+		formatted := t.Format("2006-01-02")
+		return &formatted
+	}
+
+	return json.Marshal(&struct {
+		*Alias
+		LaunchedOn     *string `json:"launched_on"`
+		DiscontinuedOn *string `json:"discontinued_on"`
+	}{
+		Alias:          (*Alias)(&v),
+		LaunchedOn:     format(v.LaunchedOn),
+		DiscontinuedOn: format(v.DiscontinuedOn),
+	})
+}
+
+type PerfumeVariantService interface {
+	Save(variant *PerfumeVariant) error
+	Find(publicId string) (*PerfumeVariant, error)
+	FindByBarcode(code string) (*PerfumeVariant, error)
+	List(perfumeId string) ([]PerfumeVariant, error)
+	Delete(variant *PerfumeVariant) error
+}
@@ -0,0 +1,27 @@
+package internal
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestVariantTypeFromString(t *testing.T) {
+	giftSet, err := VariantTypeFromString("Gift_Set")
+	assert.Nil(t, err)
+	assert.Equal(t, GiftSetVariant, giftSet)
+
+	_, err = VariantTypeFromString("sample")
+	assert.NotNil(t, err)
+}
+
+func TestPerfumeVariant_MarshalJSON(t *testing.T) {
+	launchedOn := time.Date(2015, time.September, 1, 0, 0, 0, 0, time.UTC)
+	variant := PerfumeVariant{Type: BottleVariant, LaunchedOn: &launchedOn}
+
+	data, err := json.Marshal(variant)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"launched_on":"2015-09-01"`)
+	assert.Contains(t, string(data), `"discontinued_on":null`)
+}
@@ -15,6 +15,7 @@ type Perfumer struct {
 	ImageURL    string    `json:"image_url"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int       `json:"version"`
 }
 
 func (p Perfumer) GetID() int {
@@ -33,6 +34,26 @@ func (p Perfumer) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (p *Perfumer) UnmarshalJSON(data []byte) error {
+	type Alias Perfumer
+
+	aux := &struct {
+		*Alias
+		BirthDate string `json:"birth_date"`
+	}{
+		Alias: (*Alias)(p),
+	}
+
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	var err error
+	p.BirthDate, err = parseOptionalTime("January 2, 2006", aux.BirthDate)
+
+	return err
+}
+
 func NewPerfumer(Name, Nationality, photoURL string, birthDate time.Time) *Perfumer {
 	now := time.Now()
 	return &Perfumer{
@@ -48,8 +69,10 @@ func NewPerfumer(Name, Nationality, photoURL string, birthDate time.Time) *Perfu
 
 type PerfumerService interface {
 	List(cursor, perPage int) ([]Perfumer, error)
-	Save(note *Perfumer) error
+	Save(perfumer *Perfumer, meta ChangeMeta) error
 	Find(publicId string) (*Perfumer, error)
 	FindBySlug(s string) (*Perfumer, error)
 	FindMany(publicIds ...string) ([]*Perfumer, error)
+	Delete(publicId string) error
+	Restore(publicId string) error
 }
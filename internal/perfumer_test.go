@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -18,6 +19,26 @@ func TestNewPerfumer(t *testing.T) {
 	assert.Equal(t, Slug, perfumer.Slug)
 	assert.Equal(t, Name, perfumer.Name)
 	assert.Equal(t, Nationality, perfumer.Nationality)
-	assert.Equal(t, PhotoURL, perfumer.PhotoURL)
+	assert.Equal(t, PhotoURL, perfumer.ImageURL)
 	assert.Equal(t, BirthDate, perfumer.BirthDate)
 }
+
+func TestPerfumer_JSONRoundTrip(t *testing.T) {
+	perfumer := Perfumer{
+		PublicId:    "abc123",
+		Slug:        "john-mcdoe-doe-iii",
+		Name:        "John McDoe Doe III",
+		Nationality: "France",
+		BirthDate:   time.Date(1999, time.January, 20, 0, 0, 0, 0, time.UTC),
+		ImageURL:    "/images/john-mcdoe-doe-iii.png",
+	}
+
+	data, err := json.Marshal(perfumer)
+	assert.Nil(t, err)
+
+	var decoded Perfumer
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, perfumer.BirthDate, decoded.BirthDate)
+	assert.Equal(t, perfumer.Name, decoded.Name)
+	assert.Equal(t, perfumer.PublicId, decoded.PublicId)
+}
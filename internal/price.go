@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"math"
+	"time"
+)
+
+// PriceSource is how a price observation entered the database.
+type PriceSource string
+
+const (
+	PriceSubmitted PriceSource = "api"
+	PriceImported  PriceSource = "import"
+)
+
+// Retailer is a shop that sells perfume variants.
+type Retailer struct {
+	ID        int       `json:"-"`
+	PublicId  string    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	Website   string    `json:"website"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (r Retailer) GetID() int {
+	return r.ID
+}
+
+// PriceObservation is the price a retailer asked for a variant at a point in
+// time. Observations are never edited: a new price is a new observation.
+type PriceObservation struct {
+	ID         int         `json:"-"`
+	PublicId   string      `json:"id"`
+	VariantId  string      `json:"variant_id"`
+	RetailerId string      `json:"retailer_id"`
+	Price      float64     `json:"price"`
+	Currency   string      `json:"currency"`
+	PricePerMl *float64    `json:"price_per_ml"`
+	Source     PriceSource `json:"source"`
+	ObservedAt time.Time   `json:"observed_at"`
+	CreatedBy  *int        `json:"-"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+func (p PriceObservation) GetID() int {
+	return p.ID
+}
+
+// PricePerMl normalises a price by the size of the variant, so that a
+// travel spray and a full bottle can be compared. It returns nil when the
+// variant has no size, such as a gift set.
+func PricePerMl(price float64, sizeMl *int) *float64 {
+	if sizeMl == nil || *sizeMl <= 0 {
+		return nil
+	}
+
+	perMl := math.Round(price/float64(*sizeMl)*10000) / 10000
+	return &perMl
+}
+
+// PriceOffer is the latest price a retailer asks for a variant.
+type PriceOffer struct {
+	Variant    *PerfumeVariant `json:"variant"`
+	Retailer   *Retailer       `json:"retailer"`
+	Price      float64         `json:"price"`
+	Currency   string          `json:"currency"`
+	PricePerMl *float64        `json:"price_per_ml"`
+	ObservedAt time.Time       `json:"observed_at"`
+}
+
+// PriceHistoryFilter narrows the price history of a perfume to a variant, a
+// retailer and the days from From to To, inclusive. Empty fields do not
+// filter.
+type PriceHistoryFilter struct {
+	VariantId  string
+	RetailerId string
+	From       time.Time
+	To         time.Time
+}
+
+type RetailerService interface {
+	List(cursor, perPage int) ([]Retailer, error)
+	Save(retailer *Retailer) error
+	Find(publicId string) (*Retailer, error)
+	FindBySlug(s string) (*Retailer, error)
+	Delete(retailer *Retailer) error
+}
+
+type PriceService interface {
+	Record(observations []*PriceObservation) error
+	Lowest(perfumeId string, since time.Time) ([]PriceOffer, error)
+	History(perfumeId string, filter PriceHistoryFilter) ([]PriceObservation, error)
+}
@@ -0,0 +1,31 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricePerMl(t *testing.T) {
+	size := func(ml int) *int { return &ml }
+	perMl := func(price float64) *float64 { return &price }
+
+	tests := []struct {
+		name     string
+		price    float64
+		sizeMl   *int
+		expected *float64
+	}{
+		{"full bottle", 150, size(100), perMl(1.5)},
+		{"travel spray", 35, size(10), perMl(3.5)},
+		{"rounds to four decimals", 99.99, size(30), perMl(3.333)},
+		{"no size", 120, nil, nil},
+		{"zero size", 120, size(0), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PricePerMl(tt.price, tt.sizeMl))
+		})
+	}
+}
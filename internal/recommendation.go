@@ -0,0 +1,26 @@
+package internal
+
+import "fmt"
+
+// RecommendationExclusion is a house or a note a user never wants to be
+// recommended.
+type RecommendationExclusion struct {
+	Type     EntityType `json:"type"`
+	EntityId string     `json:"id"`
+}
+
+// NewRecommendationExclusion checks that perfumes can be excluded by the
+// given entity type.
+func NewRecommendationExclusion(entityType EntityType, entityId string) (RecommendationExclusion, error) {
+	if entityType != HouseEntity && entityType != NoteEntity {
+		return RecommendationExclusion{}, fmt.Errorf("recommendations cannot exclude entity type: %s", entityType)
+	}
+
+	return RecommendationExclusion{Type: entityType, EntityId: entityId}, nil
+}
+
+type RecommendationExclusionService interface {
+	Exclusions(userId int) ([]RecommendationExclusion, error)
+	Exclude(userId int, exclusion RecommendationExclusion) error
+	Include(userId int, exclusion RecommendationExclusion) error
+}
@@ -0,0 +1,19 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewRecommendationExclusion(t *testing.T) {
+	house, err := NewRecommendationExclusion(HouseEntity, "house-id")
+	assert.NoError(t, err)
+	assert.Equal(t, RecommendationExclusion{Type: HouseEntity, EntityId: "house-id"}, house)
+
+	note, err := NewRecommendationExclusion(NoteEntity, "note-id")
+	assert.NoError(t, err)
+	assert.Equal(t, NoteEntity, note.Type)
+
+	_, err = NewRecommendationExclusion(PerfumerEntity, "perfumer-id")
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	MinReviewScore = 1
+	MaxReviewScore = 10
+)
+
+// Review is a user's verdict on a perfume. Longevity, sillage and value are
+// optional 1–5 ratings alongside the overall score.
+type Review struct {
+	ID        int       `json:"-"`
+	PublicId  string    `json:"id"`
+	PerfumeId string    `json:"perfume_id"`
+	UserId    int       `json:"-"`
+	Author    string    `json:"author"`
+	Score     int       `json:"score"`
+	Text      string    `json:"text"`
+	Longevity *int      `json:"longevity"`
+	Sillage   *int      `json:"sillage"`
+	Value     *int      `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (r Review) GetID() int {
+	return r.ID
+}
+
+// IsWrittenBy reports whether the review belongs to the user.
+func (r Review) IsWrittenBy(user *User) bool {
+	return !user.IsAnonymous() && r.UserId == user.ID
+}
+
+// Rating is the aggregate of the reviews of a perfume. The histogram is keyed
+// by score and always holds every score from MinReviewScore to MaxReviewScore.
+type Rating struct {
+	Average   float64     `json:"average"`
+	Count     int         `json:"count"`
+	Histogram map[int]int `json:"histogram"`
+}
+
+// NewRating builds a rating from the stored review count, the sum of their
+// scores and the per-score counts, where histogram[0] holds the number of
+// reviews that scored MinReviewScore.
+func NewRating(count, sum int, histogram []int) Rating {
+	rating := Rating{
+		Count:     count,
+		Histogram: make(map[int]int, MaxReviewScore),
+	}
+
+	for score := MinReviewScore; score <= MaxReviewScore; score++ {
+		if i := score - MinReviewScore; i < len(histogram) {
+			rating.Histogram[score] = histogram[i]
+		} else {
+			rating.Histogram[score] = 0
+		}
+	}
+
+	if count > 0 {
+		rating.Average = math.Round(float64(sum)/float64(count)*100) / 100
+	}
+
+	return rating
+}
+
+type ReviewFilter struct {
+	PerfumeId string
+	UserId    int
+}
+
+type ReviewService interface {
+	Save(review *Review) error
+	Find(publicId string) (*Review, error)
+	List(filter ReviewFilter, cursor, perPage int) ([]Review, error)
+	Delete(review *Review) error
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewRating(t *testing.T) {
+	rating := NewRating(3, 22, []int{0, 0, 0, 0, 0, 0, 1, 1, 0, 1})
+
+	assert.Equal(t, 3, rating.Count)
+	assert.Equal(t, 7.33, rating.Average)
+	assert.Len(t, rating.Histogram, MaxReviewScore)
+	assert.Equal(t, 1, rating.Histogram[7])
+	assert.Equal(t, 1, rating.Histogram[8])
+	assert.Equal(t, 1, rating.Histogram[10])
+	assert.Equal(t, 0, rating.Histogram[1])
+}
+
+func TestNewRating_NoReviews(t *testing.T) {
+	rating := NewRating(0, 0, nil)
+
+	assert.Equal(t, 0, rating.Count)
+	assert.Equal(t, 0.0, rating.Average)
+	assert.Len(t, rating.Histogram, MaxReviewScore)
+
+	for score := MinReviewScore; score <= MaxReviewScore; score++ {
+		assert.Equal(t, 0, rating.Histogram[score])
+	}
+}
+
+func TestReview_IsWrittenBy(t *testing.T) {
+	review := Review{UserId: 3}
+
+	assert.True(t, review.IsWrittenBy(&User{ID: 3}))
+	assert.False(t, review.IsWrittenBy(&User{ID: 4}))
+	assert.False(t, review.IsWrittenBy(AnonymousUser))
+}
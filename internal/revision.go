@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Revision is a JSON snapshot of an entity taken every time it is saved.
+type Revision struct {
+	ID         int             `json:"-"`
+	EntityType EntityType      `json:"entity_type"`
+	EntityId   string          `json:"entity_id"`
+	Number     int             `json:"revision"`
+	Snapshot   json.RawMessage `json:"snapshot"`
+	Actor      string          `json:"actor"`
+	Reason     string          `json:"reason"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func (r Revision) GetID() int {
+	return r.ID
+}
+
+// ChangeMeta describes who made a change and why. It is stored alongside the
+// revision created by a save.
+type ChangeMeta struct {
+	Actor  string
+	Reason string
+}
+
+type RevisionService interface {
+	List(entityType EntityType, entityId string, cursor, perPage int) ([]Revision, error)
+	Find(entityType EntityType, entityId string, number int) (*Revision, error)
+}
@@ -0,0 +1,51 @@
+package internal
+
+import "fmt"
+
+// Role is the permission level of a user. Roles are hierarchical: each role
+// grants everything the roles below it grant.
+type Role string
+
+const (
+	RoleViewer      Role = "viewer"
+	RoleContributor Role = "contributor"
+	RoleEditor      Role = "editor"
+	RoleAdmin       Role = "admin"
+)
+
+var RoleMap = map[string]Role{
+	"viewer":      RoleViewer,
+	"contributor": RoleContributor,
+	"editor":      RoleEditor,
+	"admin":       RoleAdmin,
+}
+
+var roleRanks = map[Role]int{
+	RoleViewer:      1,
+	RoleContributor: 2,
+	RoleEditor:      3,
+	RoleAdmin:       4,
+}
+
+func RoleFromString(s string) (Role, error) {
+	role, ok := RoleMap[s]
+	if !ok {
+		return "", fmt.Errorf("unknown role: %s", s)
+	}
+
+	return role, nil
+}
+
+// Includes reports whether the role grants the permissions of the required role.
+func (r Role) Includes(required Role) bool {
+	rank, ok := roleRanks[r]
+	if !ok {
+		return false
+	}
+
+	return rank >= roleRanks[required]
+}
+
+func (r Role) String() string {
+	return string(r)
+}
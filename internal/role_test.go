@@ -0,0 +1,26 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRoleFromString(t *testing.T) {
+	editor, err := RoleFromString("editor")
+	assert.Nil(t, err)
+	assert.Equal(t, RoleEditor, editor)
+
+	unknown, err := RoleFromString("superuser")
+	assert.Error(t, err)
+	assert.Equal(t, Role(""), unknown)
+}
+
+func TestRole_Includes(t *testing.T) {
+	assert.True(t, RoleAdmin.Includes(RoleViewer))
+	assert.True(t, RoleAdmin.Includes(RoleAdmin))
+	assert.True(t, RoleEditor.Includes(RoleContributor))
+	assert.True(t, RoleContributor.Includes(RoleContributor))
+	assert.False(t, RoleContributor.Includes(RoleEditor))
+	assert.False(t, RoleViewer.Includes(RoleContributor))
+	assert.False(t, Role("").Includes(RoleViewer))
+}
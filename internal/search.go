@@ -0,0 +1,13 @@
+package internal
+
+// SearchResult is a single ranked hit of a full-text search. Data holds the
+// matched model and is serialized with that model's own JSON representation.
+type SearchResult struct {
+	Type EntityType `json:"type"`
+	Rank float64    `json:"rank"`
+	Data any        `json:"data"`
+}
+
+type SearchService interface {
+	Search(query string, limit int) ([]SearchResult, error)
+}
@@ -0,0 +1,50 @@
+package similarity
+
+import (
+	"math"
+	"sort"
+)
+
+// AccordWeight is how strongly a note expresses an accord, from 1 to 100.
+type AccordWeight struct {
+	ID     string
+	Weight int
+}
+
+type AccordStrength struct {
+	ID       string
+	Strength int
+}
+
+// AccordProfile returns the main accords of the perfume, strongest first.
+// Every note adds its accord weights, scaled by the weight of the category
+// it appears in, and the sums are scaled so that the strongest accord is 100.
+// Accords that would round down to 0 are left out.
+func AccordProfile(perfume Perfume, noteAccords map[string][]AccordWeight, weights Weights) []AccordStrength {
+	sums := make(map[string]float64)
+	strongest := 0.0
+	for _, note := range perfume.Notes {
+		for _, accord := range noteAccords[note.ID] {
+			sums[accord.ID] += weights.category(note.Category) * float64(accord.Weight)
+			strongest = math.Max(strongest, sums[accord.ID])
+		}
+	}
+
+	profile := make([]AccordStrength, 0, len(sums))
+	for id, sum := range sums {
+		strength := int(math.Round(sum / strongest * 100))
+		if strength > 0 {
+			profile = append(profile, AccordStrength{ID: id, Strength: strength})
+		}
+	}
+
+	sort.Slice(profile, func(i, j int) bool {
+		if profile[i].Strength != profile[j].Strength {
+			return profile[i].Strength > profile[j].Strength
+		}
+
+		return profile[i].ID < profile[j].ID
+	})
+
+	return profile
+}
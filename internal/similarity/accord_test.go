@@ -0,0 +1,44 @@
+package similarity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var noteAccords = map[string][]AccordWeight{
+	"bergamot": {{ID: "citrus", Weight: 100}, {ID: "fresh", Weight: 40}},
+	"lemon":    {{ID: "citrus", Weight: 100}},
+	"vetiver":  {{ID: "woody", Weight: 80}, {ID: "earthy", Weight: 60}},
+}
+
+func TestAccordProfile(t *testing.T) {
+	perfume := Perfume{ID: "a", Notes: []Note{bergamot, lemon, vetiver}}
+
+	profile := AccordProfile(perfume, noteAccords, DefaultWeights)
+
+	assert.Equal(t, []AccordStrength{
+		{ID: "citrus", Strength: 100},
+		{ID: "woody", Strength: 80},
+		{ID: "earthy", Strength: 60},
+		{ID: "fresh", Strength: 20},
+	}, profile)
+}
+
+func TestAccordProfile_CategoryWeights(t *testing.T) {
+	perfume := Perfume{ID: "a", Notes: []Note{bergamot, vetiver}}
+
+	profile := AccordProfile(perfume, noteAccords, DefaultWeights)
+
+	assert.Equal(t, []AccordStrength{
+		{ID: "woody", Strength: 100},
+		{ID: "earthy", Strength: 75},
+		{ID: "citrus", Strength: 63},
+		{ID: "fresh", Strength: 25},
+	}, profile, "the base note outweighs the stronger top note")
+}
+
+func TestAccordProfile_NoAccords(t *testing.T) {
+	perfume := Perfume{ID: "a", Notes: []Note{rose}}
+
+	assert.Empty(t, AccordProfile(perfume, noteAccords, DefaultWeights))
+}
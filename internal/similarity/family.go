@@ -0,0 +1,90 @@
+package similarity
+
+import "sort"
+
+// minFamilyShare is the share of a perfume's note weight a family needs to be
+// proposed, so that a single trace note does not classify a perfume.
+const minFamilyShare = 0.1
+
+// Family is an olfactive family with the note groups that are typical of it.
+// ParentID is empty for top level families.
+type Family struct {
+	ID         string
+	ParentID   string
+	NoteGroups []string
+}
+
+type FamilyScore struct {
+	ID    string
+	Score float64
+}
+
+// Classification is the family proposal for a perfume. Primary and Secondary
+// are empty when no family reaches minFamilyShare.
+type Classification struct {
+	PerfumeID string
+	Primary   string
+	Secondary string
+	Scores    []FamilyScore
+}
+
+// Classify proposes a primary and a secondary family for the perfume. A
+// family scores the share of the perfume's category-weighted notes that
+// belong to its note groups. On equal scores a subfamily wins over a top
+// level family, being more specific. The secondary family is never the parent
+// or a subfamily of the primary one.
+func Classify(perfume Perfume, families []Family, weights Weights) Classification {
+	classification := Classification{PerfumeID: perfume.ID, Scores: make([]FamilyScore, 0)}
+
+	total := weights.total(perfume)
+	if total == 0 {
+		return classification
+	}
+
+	byGroup := make(map[string]float64)
+	for _, note := range perfume.Notes {
+		if note.GroupID != "" {
+			byGroup[note.GroupID] += weights.category(note.Category)
+		}
+	}
+
+	parents := make(map[string]string, len(families))
+	for _, family := range families {
+		parents[family.ID] = family.ParentID
+
+		score := 0.0
+		for _, group := range family.NoteGroups {
+			score += byGroup[group]
+		}
+
+		if score/total >= minFamilyShare {
+			classification.Scores = append(classification.Scores, FamilyScore{ID: family.ID, Score: score / total})
+		}
+	}
+
+	scores := classification.Scores
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+
+		iSub, jSub := parents[scores[i].ID] != "", parents[scores[j].ID] != ""
+		if iSub != jSub {
+			return iSub
+		}
+
+		return scores[i].ID < scores[j].ID
+	})
+
+	for _, score := range scores {
+		switch {
+		case classification.Primary == "":
+			classification.Primary = score.ID
+		case parents[score.ID] != classification.Primary && parents[classification.Primary] != score.ID:
+			classification.Secondary = score.ID
+			return classification
+		}
+	}
+
+	return classification
+}
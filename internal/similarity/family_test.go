@@ -0,0 +1,64 @@
+package similarity
+
+import (
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/stretchr/testify/assert"
+	"slices"
+	"testing"
+)
+
+var families = []Family{
+	{ID: "fresh", NoteGroups: []string{"citrus"}},
+	{ID: "citrus-fresh", ParentID: "fresh", NoteGroups: []string{"citrus"}},
+	{ID: "floral", NoteGroups: []string{"floral"}},
+	{ID: "woody", NoteGroups: []string{"woody"}},
+	{ID: "oriental", NoteGroups: []string{"gourmand"}},
+	{ID: "woody-oriental", ParentID: "oriental", NoteGroups: []string{"woody", "gourmand"}},
+}
+
+func TestClassify(t *testing.T) {
+	perfume := Perfume{ID: "a", Notes: []Note{bergamot, rose, vetiver, vanilla}}
+
+	classification := Classify(perfume, families, DefaultWeights)
+
+	assert.Equal(t, "a", classification.PerfumeID)
+	assert.Equal(t, "woody-oriental", classification.Primary)
+	assert.Equal(t, "woody", classification.Secondary, "the parent of the primary family is skipped")
+	assert.InDelta(t, 4/6.5, classification.Scores[0].Score, 1e-9)
+}
+
+func TestClassify_PrefersSubfamiliesOnTies(t *testing.T) {
+	perfume := Perfume{ID: "a", Notes: []Note{bergamot, lemon, rose}}
+
+	classification := Classify(perfume, families, DefaultWeights)
+
+	assert.Equal(t, "citrus-fresh", classification.Primary)
+	assert.Equal(t, "floral", classification.Secondary)
+}
+
+func TestClassify_OnlyFamiliesSharingNoteGroups(t *testing.T) {
+	perfume := Perfume{ID: "a", Notes: []Note{bergamot, rose}}
+	before := Classify(perfume, families, DefaultWeights)
+
+	edited := slices.Clone(families)
+	edited[4] = Family{ID: "oriental", NoteGroups: []string{"gourmand", "amber"}}
+	assert.Equal(t, before, Classify(perfume, edited, DefaultWeights), "a family without the perfume's note groups cannot move it")
+
+	edited[3] = Family{ID: "woody", NoteGroups: []string{"woody", "floral"}}
+	assert.NotEqual(t, before, Classify(perfume, edited, DefaultWeights))
+}
+
+func TestClassify_MinimumShare(t *testing.T) {
+	notes := []Note{bergamot}
+	for _, id := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		notes = append(notes, Note{ID: id, Category: internal.TopNote})
+	}
+
+	classification := Classify(Perfume{ID: "a", Notes: notes}, families, DefaultWeights)
+
+	assert.Empty(t, classification.Primary)
+	assert.Empty(t, classification.Secondary)
+	assert.Empty(t, classification.Scores)
+
+	assert.Empty(t, Classify(Perfume{ID: "empty"}, families, DefaultWeights).Primary)
+}
@@ -0,0 +1,222 @@
+package similarity
+
+import (
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/ej-agas/perfume-db/internal"
+)
+
+// maxReasons is how many notes explain a recommendation.
+const maxReasons = 3
+
+// shelfWeights is how strongly having a perfume on a shelf says the user
+// likes it. Tried and sold perfumes say nothing either way.
+var shelfWeights = map[internal.Shelf]float64{
+	internal.ShelfOwned:    1,
+	internal.ShelfWishlist: 0.6,
+}
+
+// Interaction is what a user did with a perfume: the shelves it sits on and
+// the score they gave it, where a zero score means no review.
+type Interaction struct {
+	PerfumeID   string
+	Shelves     []internal.Shelf
+	ReviewScore int
+}
+
+// Weight turns the interaction into a preference between -1 and 1. A review
+// outweighs shelves, so that a disliked bottle on the owned shelf still
+// counts against its notes.
+func (interaction Interaction) Weight() float64 {
+	if interaction.ReviewScore != 0 {
+		middle := float64(internal.MinReviewScore+internal.MaxReviewScore) / 2
+		return (float64(interaction.ReviewScore) - middle) / (float64(internal.MaxReviewScore) - middle)
+	}
+
+	weight := 0.0
+	for _, shelf := range interaction.Shelves {
+		weight = math.Max(weight, shelfWeights[shelf])
+	}
+
+	return weight
+}
+
+// Profile is a user's taste, as a preference between -1 and 1 for each note
+// and note group they have an opinion on.
+type Profile struct {
+	Notes  map[string]float64
+	Groups map[string]float64
+}
+
+// Profile builds a preference profile from the interactions of a user. Every
+// note of an interacted perfume receives the interaction's weight, scaled by
+// the weight of the category the note appears in.
+func (index *Index) Profile(interactions []Interaction) Profile {
+	profile := Profile{Notes: make(map[string]float64), Groups: make(map[string]float64)}
+
+	for _, interaction := range interactions {
+		perfume, ok := index.perfumes[interaction.PerfumeID]
+		if !ok {
+			continue
+		}
+
+		weight := interaction.Weight()
+		for _, note := range perfume.Notes {
+			contribution := weight * index.weights.category(note.Category)
+			profile.Notes[note.ID] += contribution
+
+			if note.GroupID != "" {
+				profile.Groups[note.GroupID] += contribution
+			}
+		}
+	}
+
+	normalize(profile.Notes)
+	normalize(profile.Groups)
+
+	return profile
+}
+
+// normalize scales the preferences so that the strongest one is 1 or -1.
+func normalize(preferences map[string]float64) {
+	strongest := 0.0
+	for _, preference := range preferences {
+		strongest = math.Max(strongest, math.Abs(preference))
+	}
+
+	if strongest == 0 {
+		return
+	}
+
+	for key := range preferences {
+		preferences[key] /= strongest
+	}
+}
+
+// Exclusions lists what must not be recommended: perfumes the user already
+// knows, and the houses and notes they dislike.
+type Exclusions struct {
+	Perfumes map[string]bool
+	Houses   map[string]bool
+	Notes    map[string]bool
+}
+
+func (exclusions Exclusions) excludes(perfume Perfume) bool {
+	if exclusions.Perfumes[perfume.ID] || exclusions.Houses[perfume.HouseID] {
+		return true
+	}
+
+	for _, note := range perfume.Notes {
+		if exclusions.Notes[note.ID] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reason is a note that drove a recommendation, with its share of the score.
+type Reason struct {
+	NoteID string
+	Weight float64
+}
+
+// Recommendation is a perfume scored against a profile, with the notes that
+// drove its score.
+type Recommendation struct {
+	ID      string
+	Score   float64
+	Reasons []Reason
+}
+
+// Recommend scores every perfume that shares a liked note or note group with
+// the profile and returns the best limit of them, best first. A perfume
+// scores the category-weighted average of the preferences for its notes,
+// where a liked note group counts for SameGroup of a liked note.
+func (index *Index) Recommend(profile Profile, exclusions Exclusions, limit int) []Recommendation {
+	candidates := make(map[string]bool)
+	for noteId, preference := range profile.Notes {
+		if preference > 0 {
+			for _, candidate := range index.byNote[noteId] {
+				candidates[candidate] = true
+			}
+		}
+	}
+
+	for groupId, preference := range profile.Groups {
+		if preference > 0 {
+			for _, candidate := range index.byGroup[groupId] {
+				candidates[candidate] = true
+			}
+		}
+	}
+
+	recommendations := make([]Recommendation, 0, len(candidates))
+	for candidate := range candidates {
+		perfume := index.perfumes[candidate]
+		if exclusions.excludes(perfume) {
+			continue
+		}
+
+		recommendation, ok := index.recommendation(profile, perfume)
+		if ok {
+			recommendations = append(recommendations, recommendation)
+		}
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].Score != recommendations[j].Score {
+			return recommendations[i].Score > recommendations[j].Score
+		}
+
+		return recommendations[i].ID < recommendations[j].ID
+	})
+
+	if limit >= 0 && len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+
+	return recommendations
+}
+
+func (index *Index) recommendation(profile Profile, perfume Perfume) (Recommendation, bool) {
+	total := index.weights.total(perfume)
+	if total == 0 {
+		return Recommendation{}, false
+	}
+
+	score := 0.0
+	reasons := make([]Reason, 0)
+	for _, note := range perfume.Notes {
+		preference := profile.Notes[note.ID] + index.weights.SameGroup*profile.Groups[note.GroupID]
+		contribution := index.weights.category(note.Category) * preference / total
+		score += contribution
+
+		if contribution > 0 {
+			reasons = append(reasons, Reason{NoteID: note.ID, Weight: contribution})
+		}
+	}
+
+	if score <= 0 {
+		return Recommendation{}, false
+	}
+
+	slices.SortStableFunc(reasons, func(a, b Reason) int {
+		switch {
+		case a.Weight > b.Weight:
+			return -1
+		case a.Weight < b.Weight:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	if len(reasons) > maxReasons {
+		reasons = reasons[:maxReasons]
+	}
+
+	return Recommendation{ID: perfume.ID, Score: score, Reasons: reasons}, true
+}
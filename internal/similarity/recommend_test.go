@@ -0,0 +1,78 @@
+package similarity
+
+import (
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInteraction_Weight(t *testing.T) {
+	assert.Equal(t, 1.0, Interaction{Shelves: []internal.Shelf{internal.ShelfOwned}}.Weight())
+	assert.Equal(t, 1.0, Interaction{Shelves: []internal.Shelf{internal.ShelfWishlist, internal.ShelfOwned}}.Weight())
+	assert.Equal(t, 0.6, Interaction{Shelves: []internal.Shelf{internal.ShelfWishlist}}.Weight())
+	assert.Equal(t, 0.0, Interaction{Shelves: []internal.Shelf{internal.ShelfSold}}.Weight())
+	assert.Equal(t, 1.0, Interaction{ReviewScore: 10}.Weight())
+	assert.Equal(t, -1.0, Interaction{ReviewScore: 1, Shelves: []internal.Shelf{internal.ShelfOwned}}.Weight())
+}
+
+func recommendationIndex() *Index {
+	return NewIndex([]Perfume{
+		{ID: "owned", HouseID: "h1", Notes: []Note{bergamot, vetiver}},
+		{ID: "disliked", HouseID: "h1", Notes: []Note{vanilla}},
+		{ID: "woody", HouseID: "h2", Notes: []Note{vetiver, rose}},
+		{ID: "citrus", HouseID: "h3", Notes: []Note{lemon, rose}},
+		{ID: "sweet", HouseID: "h2", Notes: []Note{vanilla, vetiver}},
+		{ID: "unrelated", HouseID: "h2", Notes: []Note{rose}},
+	}, DefaultWeights)
+}
+
+func TestIndex_Profile(t *testing.T) {
+	index := recommendationIndex()
+
+	profile := index.Profile([]Interaction{
+		{PerfumeID: "owned", Shelves: []internal.Shelf{internal.ShelfOwned}},
+		{PerfumeID: "disliked", ReviewScore: 1},
+		{PerfumeID: "missing", ReviewScore: 10},
+	})
+
+	assert.Equal(t, 1.0, profile.Notes["vetiver"])
+	assert.Equal(t, 0.5, profile.Notes["bergamot"])
+	assert.Equal(t, -1.0, profile.Notes["vanilla"])
+	assert.Equal(t, 1.0, profile.Groups["woody"])
+	assert.NotContains(t, profile.Notes, "rose")
+}
+
+func TestIndex_Recommend(t *testing.T) {
+	index := recommendationIndex()
+	profile := index.Profile([]Interaction{
+		{PerfumeID: "owned", Shelves: []internal.Shelf{internal.ShelfOwned}},
+		{PerfumeID: "disliked", ReviewScore: 1},
+	})
+	exclusions := Exclusions{Perfumes: map[string]bool{"owned": true, "disliked": true}}
+
+	recommendations := index.Recommend(profile, exclusions, 10)
+
+	ids := make([]string, 0, len(recommendations))
+	for _, recommendation := range recommendations {
+		ids = append(ids, recommendation.ID)
+	}
+
+	assert.Equal(t, []string{"woody", "citrus"}, ids)
+	assert.Equal(t, "vetiver", recommendations[0].Reasons[0].NoteID)
+	assert.Equal(t, "lemon", recommendations[1].Reasons[0].NoteID)
+}
+
+func TestIndex_Recommend_Exclusions(t *testing.T) {
+	index := recommendationIndex()
+	profile := index.Profile([]Interaction{{PerfumeID: "owned", Shelves: []internal.Shelf{internal.ShelfOwned}}})
+
+	byHouse := index.Recommend(profile, Exclusions{Perfumes: map[string]bool{"owned": true}, Houses: map[string]bool{"h2": true}}, 10)
+	for _, recommendation := range byHouse {
+		assert.NotEqual(t, "woody", recommendation.ID)
+		assert.NotEqual(t, "sweet", recommendation.ID)
+	}
+
+	byNote := index.Recommend(profile, Exclusions{Perfumes: map[string]bool{"owned": true}, Notes: map[string]bool{"rose": true}}, 10)
+	assert.Len(t, byNote, 1)
+	assert.Equal(t, "sweet", byNote[0].ID)
+}
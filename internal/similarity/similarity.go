@@ -0,0 +1,202 @@
+// Package similarity ranks perfumes by how much their notes overlap and
+// classifies them into olfactive families. It works on plain values so that
+// scoring can be tested without a database.
+package similarity
+
+import (
+	"sort"
+
+	"github.com/ej-agas/perfume-db/internal"
+)
+
+// Note is a note of a perfume, with the category it appears in and the note
+// group it belongs to.
+type Note struct {
+	ID       string
+	GroupID  string
+	Category internal.NoteCategory
+}
+
+type Perfume struct {
+	ID            string
+	HouseID       string
+	Concentration internal.Concentration
+	Notes         []Note
+}
+
+// Weights tune the scoring. Category weighs a shared note by the category it
+// appears in. A note that is not shared but has a note in the same group on
+// the other side counts for SameGroup times its category weight. Perfumes of
+// the same concentration get their score raised by SameConcentration times
+// itself.
+type Weights struct {
+	Category          map[internal.NoteCategory]float64
+	SameGroup         float64
+	SameConcentration float64
+}
+
+// DefaultWeights favour base notes, which define a perfume for most of its
+// wear, over the fleeting top notes.
+var DefaultWeights = Weights{
+	Category: map[internal.NoteCategory]float64{
+		internal.TopNote:           1,
+		internal.MiddleNote:        1.5,
+		internal.BaseNote:          2,
+		internal.UncategorizedNote: 1,
+	},
+	SameGroup:         0.25,
+	SameConcentration: 0.1,
+}
+
+func (weights Weights) category(category internal.NoteCategory) float64 {
+	if weight, ok := weights.Category[category]; ok {
+		return weight
+	}
+
+	return 1
+}
+
+func (weights Weights) total(perfume Perfume) float64 {
+	total := 0.0
+	for _, note := range perfume.Notes {
+		total += weights.category(note.Category)
+	}
+
+	return total
+}
+
+// Score returns how similar b is to a, from 0 for nothing in common. Shared
+// notes count for the average weight of the categories they appear in on
+// both sides, and the sum is normalised by the average total weight of both
+// perfumes, so that a perfume scores 1 against itself before the
+// concentration boost.
+func Score(a, b Perfume, weights Weights) float64 {
+	score, _ := score(a, b, weights)
+	return score
+}
+
+func score(a, b Perfume, weights Weights) (float64, []string) {
+	denominator := (weights.total(a) + weights.total(b)) / 2
+	if denominator == 0 {
+		return 0, nil
+	}
+
+	notes := make(map[string]Note, len(b.Notes))
+	groups := make(map[string]bool, len(b.Notes))
+	for _, note := range b.Notes {
+		notes[note.ID] = note
+		groups[note.GroupID] = true
+	}
+
+	shared := make([]string, 0)
+	matched := 0.0
+	for _, note := range a.Notes {
+		if other, ok := notes[note.ID]; ok {
+			matched += (weights.category(note.Category) + weights.category(other.Category)) / 2
+			shared = append(shared, note.ID)
+			continue
+		}
+
+		if note.GroupID != "" && groups[note.GroupID] {
+			matched += weights.SameGroup * weights.category(note.Category)
+		}
+	}
+
+	result := matched / denominator
+	if a.Concentration == b.Concentration {
+		result += weights.SameConcentration * result
+	}
+
+	return result, shared
+}
+
+type Match struct {
+	ID          string
+	Score       float64
+	SharedNotes []string
+}
+
+// Index answers similarity queries without scoring every perfume: only the
+// perfumes that share a note or a note group with the queried one are scored.
+type Index struct {
+	weights  Weights
+	perfumes map[string]Perfume
+	byNote   map[string][]string
+	byGroup  map[string][]string
+}
+
+func NewIndex(perfumes []Perfume, weights Weights) *Index {
+	index := &Index{
+		weights:  weights,
+		perfumes: make(map[string]Perfume, len(perfumes)),
+		byNote:   make(map[string][]string),
+		byGroup:  make(map[string][]string),
+	}
+
+	for _, perfume := range perfumes {
+		index.perfumes[perfume.ID] = perfume
+
+		groups := make(map[string]bool)
+		for _, note := range perfume.Notes {
+			index.byNote[note.ID] = append(index.byNote[note.ID], perfume.ID)
+
+			if note.GroupID != "" && !groups[note.GroupID] {
+				groups[note.GroupID] = true
+				index.byGroup[note.GroupID] = append(index.byGroup[note.GroupID], perfume.ID)
+			}
+		}
+	}
+
+	return index
+}
+
+// Len returns the number of indexed perfumes.
+func (index *Index) Len() int {
+	return len(index.perfumes)
+}
+
+// Similar returns up to limit perfumes most similar to the one with the given
+// ID, best first. Ties are broken by ID so that results are stable. It
+// returns nil when the perfume is not indexed.
+func (index *Index) Similar(id string, limit int) []Match {
+	perfume, ok := index.perfumes[id]
+	if !ok {
+		return nil
+	}
+
+	candidates := make(map[string]bool)
+	for _, note := range perfume.Notes {
+		for _, candidate := range index.byNote[note.ID] {
+			candidates[candidate] = true
+		}
+
+		for _, candidate := range index.byGroup[note.GroupID] {
+			candidates[candidate] = true
+		}
+	}
+	delete(candidates, id)
+
+	matches := make([]Match, 0, len(candidates))
+	for candidate := range candidates {
+		score, shared := score(perfume, index.perfumes[candidate], index.weights)
+		if score <= 0 {
+			continue
+		}
+
+		matches = append(matches, Match{ID: candidate, Score: score, SharedNotes: shared})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+
+		return matches[i].ID < matches[j].ID
+	})
+
+	if limit >= 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
@@ -0,0 +1,83 @@
+package similarity
+
+import (
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	bergamot = Note{ID: "bergamot", GroupID: "citrus", Category: internal.TopNote}
+	lemon    = Note{ID: "lemon", GroupID: "citrus", Category: internal.TopNote}
+	rose     = Note{ID: "rose", GroupID: "floral", Category: internal.MiddleNote}
+	vetiver  = Note{ID: "vetiver", GroupID: "woody", Category: internal.BaseNote}
+	vanilla  = Note{ID: "vanilla", GroupID: "gourmand", Category: internal.BaseNote}
+)
+
+func TestScore_Identical(t *testing.T) {
+	perfume := Perfume{ID: "a", Concentration: internal.EauDeParfum, Notes: []Note{bergamot, rose, vetiver}}
+	weights := DefaultWeights
+	weights.SameConcentration = 0
+
+	assert.InDelta(t, 1.0, Score(perfume, perfume, weights), 1e-9)
+}
+
+func TestScore_NothingInCommon(t *testing.T) {
+	a := Perfume{ID: "a", Notes: []Note{bergamot}}
+	b := Perfume{ID: "b", Notes: []Note{vanilla}}
+
+	assert.Equal(t, 0.0, Score(a, b, DefaultWeights))
+	assert.Equal(t, 0.0, Score(Perfume{ID: "empty"}, Perfume{ID: "empty"}, DefaultWeights))
+}
+
+func TestScore_CategoryWeights(t *testing.T) {
+	base := Perfume{ID: "base", Notes: []Note{bergamot, vetiver}}
+	sharesBase := Perfume{ID: "shares-base", Notes: []Note{lemon, vetiver}}
+	sharesTop := Perfume{ID: "shares-top", Notes: []Note{bergamot, vanilla}}
+
+	weights := DefaultWeights
+	weights.SameGroup = 0
+
+	assert.Greater(t, Score(base, sharesBase, weights), Score(base, sharesTop, weights))
+}
+
+func TestScore_SameGroupBoost(t *testing.T) {
+	a := Perfume{ID: "a", Notes: []Note{bergamot, vetiver}}
+	sameGroup := Perfume{ID: "same-group", Notes: []Note{lemon, vetiver}}
+	otherGroup := Perfume{ID: "other-group", Notes: []Note{rose, vetiver}}
+
+	assert.Greater(t, Score(a, sameGroup, DefaultWeights), Score(a, otherGroup, DefaultWeights))
+}
+
+func TestScore_SameConcentrationBoost(t *testing.T) {
+	a := Perfume{ID: "a", Concentration: internal.EauDeParfum, Notes: []Note{rose, vetiver}}
+	edp := Perfume{ID: "edp", Concentration: internal.EauDeParfum, Notes: []Note{rose, vanilla}}
+	edt := Perfume{ID: "edt", Concentration: internal.EauDeToilette, Notes: []Note{rose, vanilla}}
+
+	assert.InDelta(t, Score(a, edt, DefaultWeights)*1.1, Score(a, edp, DefaultWeights), 1e-9)
+}
+
+func TestIndex_Similar(t *testing.T) {
+	index := NewIndex([]Perfume{
+		{ID: "a", Notes: []Note{bergamot, rose, vetiver}},
+		{ID: "b", Notes: []Note{bergamot, rose, vetiver}},
+		{ID: "c", Notes: []Note{lemon, vanilla}},
+		{ID: "d", Notes: []Note{rose, vanilla}},
+		{ID: "e", Notes: []Note{vanilla}},
+	}, DefaultWeights)
+
+	assert.Equal(t, 5, index.Len())
+
+	matches := index.Similar("a", 10)
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.ID)
+	}
+
+	assert.Equal(t, []string{"b", "d", "c"}, ids)
+	assert.Equal(t, []string{"bergamot", "rose", "vetiver"}, matches[0].SharedNotes)
+	assert.Empty(t, matches[2].SharedNotes)
+
+	assert.Len(t, index.Similar("a", 1), 1)
+	assert.Nil(t, index.Similar("missing", 10))
+}
@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type SubmissionStatus string
+
+const (
+	SubmissionPending  SubmissionStatus = "pending"
+	SubmissionApproved SubmissionStatus = "approved"
+	SubmissionRejected SubmissionStatus = "rejected"
+)
+
+var SubmissionStatusMap = map[string]SubmissionStatus{
+	"pending":  SubmissionPending,
+	"approved": SubmissionApproved,
+	"rejected": SubmissionRejected,
+}
+
+var ErrSubmissionNotPending = errors.New("submission has already been reviewed")
+
+func SubmissionStatusFromString(s string) (SubmissionStatus, error) {
+	status, ok := SubmissionStatusMap[s]
+	if !ok {
+		return "", fmt.Errorf("unknown submission status: %s", s)
+	}
+
+	return status, nil
+}
+
+func (s SubmissionStatus) String() string {
+	return string(s)
+}
+
+// Submission is a contributor's proposal for a new catalogue entity. The
+// payload has the shape of the matching create request and only reaches the
+// catalogue once a moderator approves it.
+type Submission struct {
+	ID          int              `json:"-"`
+	PublicId    string           `json:"id"`
+	EntityType  EntityType       `json:"type"`
+	Payload     json.RawMessage  `json:"payload"`
+	Status      SubmissionStatus `json:"status"`
+	SubmitterId int              `json:"-"`
+	Submitter   string           `json:"submitted_by"`
+	ReviewerId  int              `json:"-"`
+	Reviewer    string           `json:"reviewed_by,omitempty"`
+	Comment     string           `json:"comment,omitempty"`
+	EntityId    string           `json:"entity_id,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ReviewedAt  *time.Time       `json:"reviewed_at"`
+}
+
+func (s Submission) GetID() int {
+	return s.ID
+}
+
+// Approve marks the submission as approved, recording the public ID of the
+// entity created from it.
+func (s *Submission) Approve(reviewer *User, entityId string) error {
+	return s.review(reviewer, SubmissionApproved, entityId, "")
+}
+
+// Reject marks the submission as rejected with the moderator's comment.
+func (s *Submission) Reject(reviewer *User, comment string) error {
+	return s.review(reviewer, SubmissionRejected, "", comment)
+}
+
+func (s *Submission) review(reviewer *User, status SubmissionStatus, entityId, comment string) error {
+	if s.Status != SubmissionPending {
+		return ErrSubmissionNotPending
+	}
+
+	now := time.Now()
+	s.Status = status
+	s.ReviewerId = reviewer.ID
+	s.Reviewer = reviewer.Username
+	s.EntityId = entityId
+	s.Comment = comment
+	s.ReviewedAt = &now
+
+	return nil
+}
+
+type SubmissionFilter struct {
+	Status      SubmissionStatus
+	SubmitterId int
+}
+
+type SubmissionService interface {
+	Save(submission *Submission) error
+	Review(submission *Submission) error
+	Find(publicId string) (*Submission, error)
+	List(filter SubmissionFilter, cursor, perPage int) ([]Submission, error)
+}
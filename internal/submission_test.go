@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSubmission_Approve(t *testing.T) {
+	reviewer := &User{ID: 7, Username: "moderator"}
+	submission := &Submission{Status: SubmissionPending}
+
+	assert.Nil(t, submission.Approve(reviewer, "perfume123"))
+	assert.Equal(t, SubmissionApproved, submission.Status)
+	assert.Equal(t, 7, submission.ReviewerId)
+	assert.Equal(t, "moderator", submission.Reviewer)
+	assert.Equal(t, "perfume123", submission.EntityId)
+	assert.NotNil(t, submission.ReviewedAt)
+
+	assert.ErrorIs(t, submission.Reject(reviewer, "too late"), ErrSubmissionNotPending)
+	assert.Equal(t, SubmissionApproved, submission.Status)
+}
+
+func TestSubmission_Reject(t *testing.T) {
+	reviewer := &User{ID: 7, Username: "moderator"}
+	submission := &Submission{Status: SubmissionPending}
+
+	assert.Nil(t, submission.Reject(reviewer, "Duplicate of an existing perfume."))
+	assert.Equal(t, SubmissionRejected, submission.Status)
+	assert.Equal(t, "Duplicate of an existing perfume.", submission.Comment)
+	assert.Empty(t, submission.EntityId)
+
+	assert.ErrorIs(t, submission.Approve(reviewer, "perfume123"), ErrSubmissionNotPending)
+}
+
+func TestSubmissionStatusFromString(t *testing.T) {
+	pending, err := SubmissionStatusFromString("pending")
+	assert.Nil(t, err)
+	assert.Equal(t, SubmissionPending, pending)
+
+	_, err = SubmissionStatusFromString("archived")
+	assert.Error(t, err)
+}
@@ -0,0 +1,13 @@
+package internal
+
+import "time"
+
+// parseOptionalTime parses value with layout, treating an empty value as the
+// zero time.
+func parseOptionalTime(layout, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(layout, value)
+}
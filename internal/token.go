@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"time"
+)
+
+// Token is a personal API token. Only the SHA-256 hash of the token is
+// stored; the plaintext is returned once, when the token is created.
+type Token struct {
+	ID         int        `json:"-"`
+	PublicId   string     `json:"id"`
+	UserId     int        `json:"-"`
+	Name       string     `json:"name"`
+	Plaintext  string     `json:"token,omitempty"`
+	Hash       []byte     `json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (t Token) GetID() int {
+	return t.ID
+}
+
+// HashToken returns the SHA-256 hash under which a token is stored.
+func HashToken(plaintext string) []byte {
+	hash := sha256.Sum256([]byte(plaintext))
+	return hash[:]
+}
+
+func generateTokenPlaintext() (string, error) {
+	randomBytes := make([]byte, 32)
+
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(randomBytes), nil
+}
+
+type TokenService interface {
+	Save(token *Token) error
+	ListForUser(userId int) ([]Token, error)
+	Revoke(userId int, publicId string) error
+}
@@ -0,0 +1,14 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHashToken(t *testing.T) {
+	expected := sha256.Sum256([]byte("plaintext"))
+
+	assert.Equal(t, expected[:], HashToken("plaintext"))
+	assert.NotEqual(t, HashToken("plaintext"), HashToken("other"))
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"errors"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AnonymousUser is attached to requests that carry no credentials.
+var AnonymousUser = &User{}
+
+type User struct {
+	ID           int       `json:"-"`
+	PublicId     string    `json:"id"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email"`
+	PasswordHash []byte    `json:"-"`
+	Role         Role      `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (u User) GetID() int {
+	return u.ID
+}
+
+func (u *User) IsAnonymous() bool {
+	return u == AnonymousUser
+}
+
+// SetPassword stores the bcrypt hash of the plaintext password.
+func (u *User) SetPassword(plaintext string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(plaintext), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	u.PasswordHash = hash
+
+	return nil
+}
+
+// Can reports whether the user's role grants the required role. The anonymous
+// user has no role.
+func (u *User) Can(required Role) bool {
+	if u.IsAnonymous() {
+		return false
+	}
+
+	return u.Role.Includes(required)
+}
+
+// PasswordMatches reports whether the plaintext password matches the stored hash.
+func (u *User) PasswordMatches(plaintext string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(plaintext))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+type UserService interface {
+	Save(user *User) error
+	Find(publicId string) (*User, error)
+	FindByUsername(username string) (*User, error)
+	FindByEmail(email string) (*User, error)
+	FindByToken(plaintext string) (*User, error)
+	List(cursor, perPage int) ([]User, error)
+}
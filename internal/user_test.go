@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUser_PasswordMatches(t *testing.T) {
+	user := &User{}
+	assert.Nil(t, user.SetPassword("correct horse battery staple"))
+	assert.NotEqual(t, "correct horse battery staple", string(user.PasswordHash))
+
+	matches, err := user.PasswordMatches("correct horse battery staple")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+
+	matches, err = user.PasswordMatches("wrong password")
+	assert.Nil(t, err)
+	assert.False(t, matches)
+}
+
+func TestUser_IsAnonymous(t *testing.T) {
+	assert.True(t, AnonymousUser.IsAnonymous())
+	assert.False(t, (&User{}).IsAnonymous())
+}
+
+func TestUser_Can(t *testing.T) {
+	editor := &User{Role: RoleEditor}
+	assert.True(t, editor.Can(RoleContributor))
+	assert.True(t, editor.Can(RoleEditor))
+	assert.False(t, editor.Can(RoleAdmin))
+
+	assert.False(t, AnonymousUser.Can(RoleViewer))
+}
@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// VoteDimension is an aspect of a perfume's performance that the community
+// votes on. Each user casts at most one vote per dimension and perfume.
+type VoteDimension string
+
+const (
+	LongevityVote VoteDimension = "longevity"
+	SillageVote   VoteDimension = "sillage"
+	SeasonVote    VoteDimension = "season"
+	TimeOfDayVote VoteDimension = "time_of_day"
+)
+
+var VoteDimensionMap = map[string]VoteDimension{
+	"longevity":   LongevityVote,
+	"sillage":     SillageVote,
+	"season":      SeasonVote,
+	"time_of_day": TimeOfDayVote,
+}
+
+// voteValues holds the options of every dimension, from weakest to strongest
+// where the options are ordered.
+var voteValues = map[VoteDimension][]string{
+	LongevityVote: {"very_weak", "weak", "moderate", "long_lasting", "eternal"},
+	SillageVote:   {"intimate", "moderate", "strong", "enormous"},
+	SeasonVote:    {"spring", "summer", "fall", "winter"},
+	TimeOfDayVote: {"day", "night"},
+}
+
+func VoteDimensionFromString(s string) (VoteDimension, error) {
+	dimension, ok := VoteDimensionMap[strings.ToLower(s)]
+	if !ok {
+		return "", fmt.Errorf("unknown vote dimension: %s", s)
+	}
+
+	return dimension, nil
+}
+
+func (d VoteDimension) String() string {
+	return string(d)
+}
+
+// Values returns the options that can be voted for on the dimension.
+func (d VoteDimension) Values() []string {
+	return slices.Clone(voteValues[d])
+}
+
+// Accepts reports whether value is one of the options of the dimension.
+func (d VoteDimension) Accepts(value string) bool {
+	return slices.Contains(voteValues[d], value)
+}
+
+type Vote struct {
+	PerfumeId string        `json:"perfume_id"`
+	UserId    int           `json:"-"`
+	Dimension VoteDimension `json:"dimension"`
+	Value     string        `json:"value"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+func NewVote(perfumeId string, user *User, dimension VoteDimension, value string) (*Vote, error) {
+	if !dimension.Accepts(value) {
+		return nil, fmt.Errorf("invalid %s vote: %s", dimension, value)
+	}
+
+	now := time.Now()
+
+	return &Vote{
+		PerfumeId: perfumeId,
+		UserId:    user.ID,
+		Dimension: dimension,
+		Value:     value,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// VoteDistribution counts the votes of a perfume per dimension and option.
+// Every option of every dimension is present, with a count of zero when
+// nobody voted for it.
+type VoteDistribution map[VoteDimension]map[string]int
+
+func NewVoteDistribution() VoteDistribution {
+	distribution := make(VoteDistribution, len(voteValues))
+
+	for dimension, values := range voteValues {
+		distribution[dimension] = make(map[string]int, len(values))
+		for _, value := range values {
+			distribution[dimension][value] = 0
+		}
+	}
+
+	return distribution
+}
+
+// Add records count votes for value. Options that are no longer offered are
+// ignored.
+func (distribution VoteDistribution) Add(dimension VoteDimension, value string, count int) {
+	if !dimension.Accepts(value) {
+		return
+	}
+
+	distribution[dimension][value] += count
+}
+
+type VoteService interface {
+	Cast(vote *Vote) error
+	Retract(perfumeId string, userId int, dimension VoteDimension) error
+}
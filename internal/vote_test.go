@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVoteDimensionFromString(t *testing.T) {
+	dimension, err := VoteDimensionFromString("Time_Of_Day")
+	assert.Nil(t, err)
+	assert.Equal(t, TimeOfDayVote, dimension)
+
+	_, err = VoteDimensionFromString("projection")
+	assert.NotNil(t, err)
+}
+
+func TestVoteDimension_Accepts(t *testing.T) {
+	assert.True(t, LongevityVote.Accepts("eternal"))
+	assert.True(t, SeasonVote.Accepts("winter"))
+	assert.False(t, SeasonVote.Accepts("eternal"))
+	assert.False(t, VoteDimension("projection").Accepts("strong"))
+}
+
+func TestNewVote(t *testing.T) {
+	user := &User{ID: 2}
+
+	vote, err := NewVote("perfume123", user, SillageVote, "strong")
+	assert.Nil(t, err)
+	assert.Equal(t, "perfume123", vote.PerfumeId)
+	assert.Equal(t, 2, vote.UserId)
+	assert.Equal(t, SillageVote, vote.Dimension)
+	assert.Equal(t, "strong", vote.Value)
+
+	_, err = NewVote("perfume123", user, SillageVote, "night")
+	assert.NotNil(t, err)
+}
+
+func TestVoteDistribution(t *testing.T) {
+	distribution := NewVoteDistribution()
+
+	assert.Len(t, distribution, 4)
+	assert.Equal(t, map[string]int{"day": 0, "night": 0}, distribution[TimeOfDayVote])
+
+	distribution.Add(TimeOfDayVote, "night", 3)
+	distribution.Add(TimeOfDayVote, "night", 1)
+	distribution.Add(TimeOfDayVote, "dusk", 5)
+
+	assert.Equal(t, map[string]int{"day": 0, "night": 4}, distribution[TimeOfDayVote])
+}
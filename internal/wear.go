@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// Wear is an entry of a user's wear diary: one wearing of a perfume on a day.
+type Wear struct {
+	ID        int       `json:"-"`
+	PublicId  string    `json:"id"`
+	UserId    int       `json:"-"`
+	PerfumeId string    `json:"perfume_id"`
+	Perfume   *Perfume  `json:"perfume,omitempty"`
+	WornOn    time.Time `json:"worn_on"`
+	Occasion  string    `json:"occasion"`
+	Weather   string    `json:"weather"`
+	Sprays    *int      `json:"sprays"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (w Wear) GetID() int {
+	return w.ID
+}
+
+func (w Wear) MarshalJSON() ([]byte, error) {
+	type Alias Wear
+
+	return json.Marshal(&struct {
+		*Alias
+		WornOn string `json:"worn_on"`
+	}{
+		Alias:  (*Alias)(&w),
+		WornOn: w.WornOn.Format("2006-01-02"),
+	})
+}
+
+type WearCount struct {
+	Perfume *Perfume `json:"perfume"`
+	Count   int      `json:"count"`
+}
+
+type MonthlyWearCount struct {
+	Month string `json:"month"`
+	Count int    `json:"count"`
+}
+
+// NoteCategoryShare is how often notes of a category appear in what a user
+// wears, as a count and as a share of all worn notes.
+type NoteCategoryShare struct {
+	Category string  `json:"category"`
+	Count    int     `json:"count"`
+	Share    float64 `json:"share"`
+}
+
+type WearStats struct {
+	TotalWears     int                 `json:"total_wears"`
+	MostWorn       []WearCount         `json:"most_worn"`
+	Unworn         []*Perfume          `json:"unworn"`
+	ByMonth        []MonthlyWearCount  `json:"by_month"`
+	NoteCategories []NoteCategoryShare `json:"note_categories"`
+}
+
+// NoteCategoryDistribution turns per-category note counts into shares, in
+// top, middle, base, uncategorized order. Shares are rounded to three
+// decimals and are all zero when nothing was worn.
+func NoteCategoryDistribution(counts map[NoteCategory]int) []NoteCategoryShare {
+	categories := []NoteCategory{TopNote, MiddleNote, BaseNote, UncategorizedNote}
+
+	total := 0
+	for _, category := range categories {
+		total += counts[category]
+	}
+
+	distribution := make([]NoteCategoryShare, 0, len(categories))
+	for _, category := range categories {
+		share := NoteCategoryShare{Category: category.String(), Count: counts[category]}
+		if total > 0 {
+			share.Share = math.Round(float64(share.Count)/float64(total)*1000) / 1000
+		}
+
+		distribution = append(distribution, share)
+	}
+
+	return distribution
+}
+
+// WearStatsFilter limits the wears that stats are computed from to the given
+// range of days. Zero times leave the range open.
+type WearStatsFilter struct {
+	From time.Time
+	To   time.Time
+}
+
+type WearService interface {
+	Save(wear *Wear) error
+	Find(userId int, publicId string) (*Wear, error)
+	List(userId int, cursor, perPage int) ([]Wear, error)
+	Delete(wear *Wear) error
+	Stats(userId int, filter WearStatsFilter) (*WearStats, error)
+}
@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNoteCategoryDistribution(t *testing.T) {
+	distribution := NoteCategoryDistribution(map[NoteCategory]int{
+		TopNote:    3,
+		MiddleNote: 2,
+		BaseNote:   1,
+	})
+
+	assert.Equal(t, []NoteCategoryShare{
+		{Category: "top", Count: 3, Share: 0.5},
+		{Category: "middle", Count: 2, Share: 0.333},
+		{Category: "base", Count: 1, Share: 0.167},
+		{Category: "uncategorized", Count: 0, Share: 0},
+	}, distribution)
+}
+
+func TestNoteCategoryDistribution_NothingWorn(t *testing.T) {
+	distribution := NoteCategoryDistribution(nil)
+
+	assert.Len(t, distribution, 4)
+	for _, share := range distribution {
+		assert.Equal(t, 0, share.Count)
+		assert.Equal(t, 0.0, share.Share)
+	}
+}
+
+func TestWear_MarshalJSON(t *testing.T) {
+	wear := Wear{WornOn: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)}
+
+	data, err := json.Marshal(wear)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"worn_on":"2024-02-29"`)
+}
@@ -0,0 +1,45 @@
+alter table perfumes add column search_vector tsvector generated always as (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+) stored;
+
+alter table houses add column search_vector tsvector generated always as (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(country, '')), 'C')
+) stored;
+
+alter table perfumers add column search_vector tsvector generated always as (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(nationality, '')), 'C')
+) stored;
+
+alter table notes add column search_vector tsvector generated always as (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+) stored;
+
+alter table note_groups add column search_vector tsvector generated always as (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+) stored;
+
+create index perfumes_search_vector__idx on perfumes using gin (search_vector);
+create index houses_search_vector__idx on houses using gin (search_vector);
+create index perfumers_search_vector__idx on perfumers using gin (search_vector);
+create index notes_search_vector__idx on notes using gin (search_vector);
+create index note_groups_search_vector__idx on note_groups using gin (search_vector);
+
+---- create above / drop below ----
+
+drop index perfumes_search_vector__idx;
+drop index houses_search_vector__idx;
+drop index perfumers_search_vector__idx;
+drop index notes_search_vector__idx;
+drop index note_groups_search_vector__idx;
+
+alter table perfumes drop column search_vector;
+alter table houses drop column search_vector;
+alter table perfumers drop column search_vector;
+alter table notes drop column search_vector;
+alter table note_groups drop column search_vector;
@@ -0,0 +1,17 @@
+create extension if not exists pg_trgm;
+
+create index perfumes_name_trgm__idx on perfumes using gin (name gin_trgm_ops);
+create index houses_name_trgm__idx on houses using gin (name gin_trgm_ops);
+create index perfumers_name_trgm__idx on perfumers using gin (name gin_trgm_ops);
+create index notes_name_trgm__idx on notes using gin (name gin_trgm_ops);
+create index note_groups_name_trgm__idx on note_groups using gin (name gin_trgm_ops);
+
+---- create above / drop below ----
+
+drop index perfumes_name_trgm__idx;
+drop index houses_name_trgm__idx;
+drop index perfumers_name_trgm__idx;
+drop index notes_name_trgm__idx;
+drop index note_groups_name_trgm__idx;
+
+drop extension if exists pg_trgm;
@@ -0,0 +1,64 @@
+alter table houses add column deleted_at timestamp;
+alter table perfumers add column deleted_at timestamp;
+alter table note_groups add column deleted_at timestamp;
+alter table notes add column deleted_at timestamp;
+alter table perfumes add column deleted_at timestamp;
+
+-- Deleted rows must not block re-using their slug or name.
+drop index houses_unique_slug__idx;
+drop index houses_unique_name__idx;
+create unique index houses_unique_slug__idx on houses (slug) where deleted_at is null;
+create unique index houses_unique_name__idx on houses (name) where deleted_at is null;
+
+drop index perfumers_unique_slug__idx;
+drop index perfumers_unique_name__idx;
+create unique index perfumers_unique_slug__idx on perfumers (slug) where deleted_at is null;
+create unique index perfumers_unique_name__idx on perfumers (name) where deleted_at is null;
+
+drop index note_groups_unique_slug__idx;
+drop index note_groups_unique_name__idx;
+create unique index note_groups_unique_slug__idx on note_groups (slug) where deleted_at is null;
+create unique index note_groups_unique_name__idx on note_groups (name) where deleted_at is null;
+
+drop index notes_unique_slug__idx;
+drop index notes_unique_name__idx;
+create unique index notes_unique_slug__idx on notes (slug) where deleted_at is null;
+create unique index notes_unique_name__idx on notes (name) where deleted_at is null;
+
+drop index perfumes_unique_slug__idx;
+drop index perfumes_unique_name__idx;
+create unique index perfumes_unique_slug__idx on perfumes (slug) where deleted_at is null;
+create unique index perfumes_unique_name__idx on perfumes (name) where deleted_at is null;
+
+---- create above / drop below ----
+
+drop index houses_unique_slug__idx;
+drop index houses_unique_name__idx;
+create unique index houses_unique_slug__idx on houses (slug);
+create unique index houses_unique_name__idx on houses (name);
+
+drop index perfumers_unique_slug__idx;
+drop index perfumers_unique_name__idx;
+create unique index perfumers_unique_slug__idx on perfumers (slug);
+create unique index perfumers_unique_name__idx on perfumers (name);
+
+drop index note_groups_unique_slug__idx;
+drop index note_groups_unique_name__idx;
+create unique index note_groups_unique_slug__idx on note_groups (slug);
+create unique index note_groups_unique_name__idx on note_groups (name);
+
+drop index notes_unique_slug__idx;
+drop index notes_unique_name__idx;
+create unique index notes_unique_slug__idx on notes (slug);
+create unique index notes_unique_name__idx on notes (name);
+
+drop index perfumes_unique_slug__idx;
+drop index perfumes_unique_name__idx;
+create unique index perfumes_unique_slug__idx on perfumes (slug);
+create unique index perfumes_unique_name__idx on perfumes (name);
+
+alter table houses drop column deleted_at;
+alter table perfumers drop column deleted_at;
+alter table note_groups drop column deleted_at;
+alter table notes drop column deleted_at;
+alter table perfumes drop column deleted_at;
@@ -0,0 +1,16 @@
+create table revisions(
+    id serial primary key,
+    entity_type varchar not null,
+    entity_id varchar not null,
+    revision int not null,
+    snapshot jsonb not null,
+    actor varchar not null,
+    reason text,
+    created_at timestamp
+);
+
+create unique index revisions_unique_entity_revision__idx on revisions (entity_type, entity_id, revision);
+
+---- create above / drop below ----
+
+drop table revisions;
@@ -0,0 +1,13 @@
+alter table houses add column version int not null default 1;
+alter table perfumers add column version int not null default 1;
+alter table note_groups add column version int not null default 1;
+alter table notes add column version int not null default 1;
+alter table perfumes add column version int not null default 1;
+
+---- create above / drop below ----
+
+alter table houses drop column version;
+alter table perfumers drop column version;
+alter table note_groups drop column version;
+alter table notes drop column version;
+alter table perfumes drop column version;
@@ -0,0 +1,35 @@
+create table users(
+    id serial primary key,
+    public_id varchar not null,
+    username varchar not null,
+    email varchar not null,
+    password_hash bytea not null,
+    created_at timestamp,
+    updated_at timestamp
+);
+
+create unique index users_unique_public_id__idx on users (public_id);
+create unique index users_unique_username__idx on users (lower(username));
+create unique index users_unique_email__idx on users (lower(email));
+
+create table tokens(
+    id serial primary key,
+    public_id varchar not null,
+    user_id int not null,
+    name varchar not null,
+    hash bytea not null,
+    expires_at timestamp,
+    last_used_at timestamp,
+    revoked_at timestamp,
+    created_at timestamp,
+    constraint fk_user_id foreign key (user_id) references users (id) on delete cascade
+);
+
+create unique index tokens_unique_public_id__idx on tokens (public_id);
+create unique index tokens_unique_hash__idx on tokens (hash);
+create index tokens_user_id__idx on tokens (user_id);
+
+---- create above / drop below ----
+
+drop table tokens;
+drop table users;
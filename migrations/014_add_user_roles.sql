@@ -0,0 +1,5 @@
+alter table users add column role varchar not null default 'contributor';
+
+---- create above / drop below ----
+
+alter table users drop column role;
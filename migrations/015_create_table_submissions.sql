@@ -0,0 +1,23 @@
+create table submissions(
+    id serial primary key,
+    public_id varchar not null,
+    entity_type varchar not null,
+    payload jsonb not null,
+    status varchar not null default 'pending',
+    submitter_id int not null,
+    reviewer_id int,
+    comment text,
+    entity_id varchar,
+    created_at timestamp,
+    reviewed_at timestamp,
+    constraint fk_submitter_id foreign key (submitter_id) references users (id),
+    constraint fk_reviewer_id foreign key (reviewer_id) references users (id)
+);
+
+create unique index submissions_unique_public_id__idx on submissions (public_id);
+create index submissions_status__idx on submissions (status, id);
+create index submissions_submitter_id__idx on submissions (submitter_id, id);
+
+---- create above / drop below ----
+
+drop table submissions;
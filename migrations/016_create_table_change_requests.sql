@@ -0,0 +1,25 @@
+create table change_requests(
+    id serial primary key,
+    public_id varchar not null,
+    entity_type varchar not null,
+    entity_id varchar not null,
+    patch jsonb not null,
+    reason text,
+    status varchar not null default 'pending',
+    proposer_id int not null,
+    reviewer_id int,
+    comment text,
+    created_at timestamp,
+    reviewed_at timestamp,
+    constraint fk_proposer_id foreign key (proposer_id) references users (id),
+    constraint fk_reviewer_id foreign key (reviewer_id) references users (id)
+);
+
+create unique index change_requests_unique_public_id__idx on change_requests (public_id);
+create index change_requests_status__idx on change_requests (status, id);
+create index change_requests_entity__idx on change_requests (entity_type, entity_id);
+create index change_requests_proposer_id__idx on change_requests (proposer_id, id);
+
+---- create above / drop below ----
+
+drop table change_requests;
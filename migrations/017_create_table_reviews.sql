@@ -0,0 +1,37 @@
+create table reviews(
+    id serial primary key,
+    public_id varchar not null,
+    perfume_id varchar not null,
+    user_id int not null,
+    score smallint not null,
+    text text not null default '',
+    longevity smallint,
+    sillage smallint,
+    value smallint,
+    created_at timestamp,
+    updated_at timestamp,
+    constraint fk_perfume_id foreign key (perfume_id) references perfumes (public_id),
+    constraint fk_user_id foreign key (user_id) references users (id),
+    constraint reviews_score__check check (score between 1 and 10),
+    constraint reviews_longevity__check check (longevity between 1 and 5),
+    constraint reviews_sillage__check check (sillage between 1 and 5),
+    constraint reviews_value__check check (value between 1 and 5)
+);
+
+create unique index reviews_unique_public_id__idx on reviews (public_id);
+create unique index reviews_unique_perfume_id_user_id__idx on reviews (perfume_id, user_id);
+create index reviews_perfume_id__idx on reviews (perfume_id, id);
+create index reviews_user_id__idx on reviews (user_id, id);
+
+create table perfume_ratings(
+    perfume_id varchar primary key,
+    count int not null default 0,
+    score_sum int not null default 0,
+    histogram int[] not null default array_fill(0, array[10]),
+    constraint fk_perfume_id foreign key (perfume_id) references perfumes (public_id)
+);
+
+---- create above / drop below ----
+
+drop table perfume_ratings;
+drop table reviews;
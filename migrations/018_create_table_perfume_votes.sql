@@ -0,0 +1,15 @@
+create table perfume_votes(
+    perfume_id varchar not null,
+    user_id int not null,
+    dimension varchar not null,
+    value varchar not null,
+    created_at timestamp,
+    updated_at timestamp,
+    constraint fk_perfume_id foreign key (perfume_id) references perfumes (public_id),
+    constraint fk_user_id foreign key (user_id) references users (id),
+    constraint unique_perfume_id_user_id_dimension unique (perfume_id, user_id, dimension)
+);
+
+---- create above / drop below ----
+
+drop table perfume_votes;
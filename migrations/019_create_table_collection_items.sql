@@ -0,0 +1,33 @@
+create table collection_items(
+    id serial primary key,
+    public_id varchar not null,
+    user_id int not null,
+    shelf varchar not null,
+    perfume_id varchar not null,
+    bottle_size_ml int,
+    purchase_date date,
+    price numeric(10, 2),
+    currency varchar(3),
+    notes text not null default '',
+    created_at timestamp,
+    updated_at timestamp,
+    constraint fk_user_id foreign key (user_id) references users (id) on delete cascade,
+    constraint fk_perfume_id foreign key (perfume_id) references perfumes (public_id),
+    constraint unique_user_id_shelf_perfume_id unique (user_id, shelf, perfume_id)
+);
+
+create unique index collection_items_unique_public_id__idx on collection_items (public_id);
+create index collection_items_user_id_shelf__idx on collection_items (user_id, shelf, id);
+
+create table shelves(
+    user_id int not null,
+    shelf varchar not null,
+    public boolean not null default false,
+    constraint fk_user_id foreign key (user_id) references users (id) on delete cascade,
+    constraint unique_user_id_shelf unique (user_id, shelf)
+);
+
+---- create above / drop below ----
+
+drop table shelves;
+drop table collection_items;
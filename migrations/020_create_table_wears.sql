@@ -0,0 +1,21 @@
+create table wears(
+    id serial primary key,
+    public_id varchar not null,
+    user_id int not null,
+    perfume_id varchar not null,
+    worn_on date not null,
+    occasion varchar not null default '',
+    weather varchar not null default '',
+    sprays int,
+    created_at timestamp,
+    constraint fk_user_id foreign key (user_id) references users (id) on delete cascade,
+    constraint fk_perfume_id foreign key (perfume_id) references perfumes (public_id)
+);
+
+create unique index wears_unique_public_id__idx on wears (public_id);
+create index wears_user_id_worn_on__idx on wears (user_id, worn_on);
+create index wears_user_id_perfume_id__idx on wears (user_id, perfume_id);
+
+---- create above / drop below ----
+
+drop table wears;
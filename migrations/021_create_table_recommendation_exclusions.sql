@@ -0,0 +1,12 @@
+create table recommendation_exclusions(
+    user_id int not null,
+    entity_type varchar not null,
+    entity_id varchar not null,
+    created_at timestamp,
+    constraint fk_user_id foreign key (user_id) references users (id) on delete cascade,
+    constraint unique_user_id_entity_type_entity_id unique (user_id, entity_type, entity_id)
+);
+
+---- create above / drop below ----
+
+drop table recommendation_exclusions;
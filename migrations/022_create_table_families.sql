@@ -0,0 +1,39 @@
+create table families(
+    id serial primary key,
+    public_id varchar not null,
+    parent_id varchar,
+    slug text not null,
+    name varchar not null,
+    description text not null default '',
+    created_at timestamp,
+    updated_at timestamp,
+    version int not null default 1
+);
+
+create unique index families_unique_public_id__idx on families (public_id);
+create unique index families_unique_slug__idx on families (slug);
+create unique index families_unique_name__idx on families (name);
+
+alter table families add constraint fk_parent_id foreign key (parent_id) references families (public_id);
+
+create table families_note_groups(
+    family_id varchar not null,
+    note_group_id varchar not null,
+    constraint fk_family_id foreign key (family_id) references families (public_id) on delete cascade,
+    constraint fk_note_group_id foreign key (note_group_id) references note_groups (public_id),
+    constraint unique_family_id_note_group_id unique (family_id, note_group_id)
+);
+
+alter table perfumes add column primary_family_id varchar;
+alter table perfumes add column secondary_family_id varchar;
+alter table perfumes add column family_overridden boolean not null default false;
+alter table perfumes add constraint fk_primary_family_id foreign key (primary_family_id) references families (public_id) on delete set null;
+alter table perfumes add constraint fk_secondary_family_id foreign key (secondary_family_id) references families (public_id) on delete set null;
+
+---- create above / drop below ----
+
+alter table perfumes drop column family_overridden;
+alter table perfumes drop column secondary_family_id;
+alter table perfumes drop column primary_family_id;
+drop table families_note_groups;
+drop table families;
@@ -0,0 +1,40 @@
+create table accords(
+    id serial primary key,
+    public_id varchar not null,
+    slug text not null,
+    name varchar not null,
+    description text not null default '',
+    created_at timestamp,
+    updated_at timestamp
+);
+
+create unique index accords_unique_public_id__idx on accords (public_id);
+create unique index accords_unique_slug__idx on accords (slug);
+create unique index accords_unique_name__idx on accords (name);
+
+create table notes_accords(
+    note_id varchar not null,
+    accord_id varchar not null,
+    weight smallint not null,
+    constraint fk_note_id foreign key (note_id) references notes (public_id),
+    constraint fk_accord_id foreign key (accord_id) references accords (public_id) on delete cascade,
+    constraint unique_note_id_accord_id unique (note_id, accord_id),
+    constraint check_weight check (weight between 1 and 100)
+);
+
+create table perfume_accords(
+    perfume_id varchar not null,
+    accord_id varchar not null,
+    strength smallint not null,
+    constraint fk_perfume_id foreign key (perfume_id) references perfumes (public_id),
+    constraint fk_accord_id foreign key (accord_id) references accords (public_id) on delete cascade,
+    constraint unique_perfume_id_accord_id unique (perfume_id, accord_id)
+);
+
+create index perfume_accords_accord_id_strength__idx on perfume_accords (accord_id, strength);
+
+---- create above / drop below ----
+
+drop table perfume_accords;
+drop table notes_accords;
+drop table accords;
@@ -0,0 +1,15 @@
+create table perfume_relations(
+    perfume_id varchar not null,
+    related_id varchar not null,
+    type varchar not null,
+    created_at timestamp,
+    constraint fk_perfume_id foreign key (perfume_id) references perfumes (public_id),
+    constraint fk_related_id foreign key (related_id) references perfumes (public_id),
+    constraint unique_perfume_id_related_id unique (perfume_id, related_id)
+);
+
+create index perfume_relations_related_id__idx on perfume_relations (related_id);
+
+---- create above / drop below ----
+
+drop table perfume_relations;
@@ -0,0 +1,30 @@
+create table inspirations(
+    id serial primary key,
+    public_id varchar not null,
+    perfume_id varchar not null,
+    original_id varchar not null,
+    created_by int,
+    created_at timestamp,
+    constraint fk_perfume_id foreign key (perfume_id) references perfumes (public_id),
+    constraint fk_original_id foreign key (original_id) references perfumes (public_id),
+    constraint fk_created_by foreign key (created_by) references users (id) on delete set null,
+    constraint unique_perfume_id_original_id unique (perfume_id, original_id)
+);
+
+create unique index inspirations_unique_public_id__idx on inspirations (public_id);
+create index inspirations_original_id__idx on inspirations (original_id);
+
+create table inspiration_votes(
+    inspiration_id int not null,
+    user_id int not null,
+    agrees boolean not null,
+    created_at timestamp,
+    constraint fk_inspiration_id foreign key (inspiration_id) references inspirations (id) on delete cascade,
+    constraint fk_user_id foreign key (user_id) references users (id) on delete cascade,
+    constraint unique_inspiration_id_user_id unique (inspiration_id, user_id)
+);
+
+---- create above / drop below ----
+
+drop table inspiration_votes;
+drop table inspirations;
@@ -0,0 +1,23 @@
+create table perfume_variants(
+    id serial primary key,
+    public_id varchar not null,
+    perfume_id varchar not null,
+    type varchar not null,
+    size_ml int,
+    barcode varchar not null default '',
+    gtin varchar(14),
+    launched_on date,
+    discontinued_on date,
+    created_at timestamp,
+    updated_at timestamp,
+    constraint fk_perfume_id foreign key (perfume_id) references perfumes (public_id),
+    constraint check_size_ml check (size_ml > 0)
+);
+
+create unique index perfume_variants_unique_public_id__idx on perfume_variants (public_id);
+create unique index perfume_variants_unique_gtin__idx on perfume_variants (gtin);
+create index perfume_variants_perfume_id__idx on perfume_variants (perfume_id);
+
+---- create above / drop below ----
+
+drop table perfume_variants;
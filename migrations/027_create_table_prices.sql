@@ -0,0 +1,38 @@
+create table retailers(
+    id serial primary key,
+    public_id varchar not null,
+    slug text not null,
+    name varchar not null,
+    website varchar not null default '',
+    created_at timestamp,
+    updated_at timestamp
+);
+
+create unique index retailers_unique_public_id__idx on retailers (public_id);
+create unique index retailers_unique_slug__idx on retailers (slug);
+
+create table price_observations(
+    id serial primary key,
+    public_id varchar not null,
+    variant_id varchar not null,
+    retailer_id varchar not null,
+    price numeric(10, 2) not null,
+    currency varchar(3) not null,
+    source varchar not null,
+    observed_at timestamp not null,
+    created_by int,
+    created_at timestamp,
+    constraint fk_variant_id foreign key (variant_id) references perfume_variants (public_id),
+    constraint fk_retailer_id foreign key (retailer_id) references retailers (public_id),
+    constraint fk_created_by foreign key (created_by) references users (id) on delete set null,
+    constraint unique_variant_id_retailer_id_observed_at unique (variant_id, retailer_id, observed_at),
+    constraint check_price check (price >= 0)
+);
+
+create unique index price_observations_unique_public_id__idx on price_observations (public_id);
+create index price_observations_retailer_id__idx on price_observations (retailer_id);
+
+---- create above / drop below ----
+
+drop table price_observations;
+drop table retailers;
@@ -0,0 +1,362 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/internal/similarity"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type AccordService struct {
+	db DB
+}
+
+var (
+	ErrAccordNotFound      = fmt.Errorf("accord not found")
+	ErrAccordAlreadyExists = fmt.Errorf("accord already exists")
+)
+
+var accordColumns = []string{
+	"id",
+	"public_id",
+	"slug",
+	"name",
+	"description",
+	"created_at",
+	"updated_at",
+}
+
+func accordFields(accord *internal.Accord) []any {
+	return []any{
+		&accord.ID,
+		&accord.PublicId,
+		&accord.Slug,
+		&accord.Name,
+		&accord.Description,
+		&accord.CreatedAt,
+		&accord.UpdatedAt,
+	}
+}
+
+func (service AccordService) List(cursor, perPage int) ([]internal.Accord, error) {
+	if cursor <= 0 {
+		cursor = 0
+	}
+
+	q := fmt.Sprintf(`SELECT %s FROM accords WHERE id > $1 ORDER BY id LIMIT $2`, columns("", accordColumns))
+
+	rows, err := service.db.Query(context.Background(), q, cursor, perPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	accords := make([]internal.Accord, 0)
+	for rows.Next() {
+		var accord internal.Accord
+		if err := rows.Scan(accordFields(&accord)...); err != nil {
+			return nil, err
+		}
+
+		accords = append(accords, accord)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return accords, nil
+}
+
+func (service AccordService) Save(accord *internal.Accord) error {
+	var err error
+	if accord.ID == 0 {
+		err = service.db.QueryRow(
+			context.Background(),
+			`
+			INSERT INTO accords (public_id, slug, name, description, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+			`,
+			accord.PublicId,
+			accord.Slug,
+			accord.Name,
+			accord.Description,
+			accord.CreatedAt,
+			accord.UpdatedAt,
+		).Scan(&accord.ID)
+	} else {
+		accord.UpdatedAt = time.Now()
+		_, err = service.db.Exec(
+			context.Background(),
+			`UPDATE accords SET slug = $2, name = $3, description = $4, updated_at = $5 WHERE id = $1`,
+			accord.ID,
+			accord.Slug,
+			accord.Name,
+			accord.Description,
+			accord.UpdatedAt,
+		)
+	}
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("database error: %w: %w", ErrAccordAlreadyExists, pgErr)
+		}
+
+		return fmt.Errorf("database error: save accord error: %w", err)
+	}
+
+	return nil
+}
+
+func (service AccordService) Find(publicId string) (*internal.Accord, error) {
+	return service.findBy("public_id", publicId)
+}
+
+func (service AccordService) FindBySlug(s string) (*internal.Accord, error) {
+	return service.findBy("slug", s)
+}
+
+func (service AccordService) findBy(column, value string) (*internal.Accord, error) {
+	var accord internal.Accord
+
+	q := fmt.Sprintf(`SELECT %s FROM accords WHERE %s = $1`, columns("", accordColumns), column)
+
+	if err := service.db.QueryRow(context.Background(), q, value).Scan(accordFields(&accord)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: accord with %s '%s' not found", ErrAccordNotFound, column, value)
+		}
+
+		return nil, err
+	}
+
+	return &accord, nil
+}
+
+// Delete removes the accord from the notes that express it and rescales the
+// profiles of the perfumes that had it.
+func (service AccordService) Delete(publicId string) error {
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	perfumeIds, err := collectStrings(tx, `SELECT perfume_id FROM perfume_accords WHERE accord_id = $1`, publicId)
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(context.Background(), `DELETE FROM accords WHERE public_id = $1`, publicId)
+	if err != nil {
+		return fmt.Errorf("database error: delete accord error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: accord with public_id '%s' not found", ErrAccordNotFound, publicId)
+	}
+
+	if err := refreshAccordProfiles(tx, perfumeIds); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
+
+func (service AccordService) NoteAccords(noteId string) ([]internal.NoteAccord, error) {
+	rows, err := service.db.Query(
+		context.Background(),
+		`SELECT accord_id, weight FROM notes_accords WHERE note_id = $1 ORDER BY weight DESC, accord_id`,
+		noteId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	accords := make([]internal.NoteAccord, 0)
+	for rows.Next() {
+		var accord internal.NoteAccord
+		if err := rows.Scan(&accord.AccordId, &accord.Weight); err != nil {
+			return nil, err
+		}
+
+		accords = append(accords, accord)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return accords, nil
+}
+
+// SetNoteAccords replaces the accords of the note and refreshes the profiles
+// of every perfume with the note.
+func (service AccordService) SetNoteAccords(noteId string, accords []internal.NoteAccord) error {
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	var exists bool
+	err = tx.QueryRow(context.Background(), `SELECT EXISTS (SELECT 1 FROM notes WHERE public_id = $1 AND deleted_at IS NULL)`, noteId).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: note with public_id '%s' not found", ErrNoteNotFound, noteId)
+	}
+
+	if _, err := tx.Exec(context.Background(), `DELETE FROM notes_accords WHERE note_id = $1`, noteId); err != nil {
+		return fmt.Errorf("database error: delete note accords error: %w", err)
+	}
+
+	accordIds := make([]string, 0, len(accords))
+	weights := make([]int, 0, len(accords))
+	for _, accord := range accords {
+		accordIds = append(accordIds, accord.AccordId)
+		weights = append(weights, accord.Weight)
+	}
+
+	_, err = tx.Exec(
+		context.Background(),
+		`
+		INSERT INTO notes_accords (note_id, accord_id, weight)
+		SELECT $1, accord_id, weight FROM unnest($2::varchar[], $3::int[]) AS a (accord_id, weight)
+		`,
+		noteId,
+		accordIds,
+		weights,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("database error: %w: %w", ErrAccordNotFound, pgErr)
+		}
+
+		return fmt.Errorf("database error: insert note accords error: %w", err)
+	}
+
+	perfumeIds, err := collectStrings(tx, `SELECT perfume_id FROM perfumes_notes WHERE note_id = $1`, noteId)
+	if err != nil {
+		return err
+	}
+
+	if err := refreshAccordProfiles(tx, perfumeIds); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
+
+// refreshAccordProfiles recomputes and stores the accord profiles of the
+// given perfumes.
+func refreshAccordProfiles(db DB, publicIds []string) error {
+	if len(publicIds) == 0 {
+		return nil
+	}
+
+	noteAccords, err := noteAccordWeights(db)
+	if err != nil {
+		return fmt.Errorf("load note accords error: %w", err)
+	}
+
+	perfumes, err := similarityPerfumes(db, publicIds)
+	if err != nil {
+		return fmt.Errorf("load perfume notes error: %w", err)
+	}
+
+	var perfumeIds, accordIds []string
+	var strengths []int
+	for _, perfume := range perfumes {
+		for _, accord := range similarity.AccordProfile(perfume, noteAccords, similarity.DefaultWeights) {
+			perfumeIds = append(perfumeIds, perfume.ID)
+			accordIds = append(accordIds, accord.ID)
+			strengths = append(strengths, accord.Strength)
+		}
+	}
+
+	if _, err := db.Exec(context.Background(), `DELETE FROM perfume_accords WHERE perfume_id = ANY($1)`, publicIds); err != nil {
+		return fmt.Errorf("database error: delete perfume accords error: %w", err)
+	}
+
+	if len(perfumeIds) == 0 {
+		return nil
+	}
+
+	_, err = db.Exec(
+		context.Background(),
+		`
+		INSERT INTO perfume_accords (perfume_id, accord_id, strength)
+		SELECT * FROM unnest($1::varchar[], $2::varchar[], $3::int[])
+		`,
+		perfumeIds,
+		accordIds,
+		strengths,
+	)
+	if err != nil {
+		return fmt.Errorf("database error: insert perfume accords error: %w", err)
+	}
+
+	return nil
+}
+
+func noteAccordWeights(db DB) (map[string][]similarity.AccordWeight, error) {
+	rows, err := db.Query(context.Background(), `SELECT note_id, accord_id, weight FROM notes_accords`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	noteAccords := make(map[string][]similarity.AccordWeight)
+	for rows.Next() {
+		var noteId string
+		var accord similarity.AccordWeight
+		if err := rows.Scan(&noteId, &accord.ID, &accord.Weight); err != nil {
+			return nil, err
+		}
+
+		noteAccords[noteId] = append(noteAccords[noteId], accord)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return noteAccords, nil
+}
+
+// collectStrings runs a query selecting a single text column.
+func collectStrings(db DB, q string, args ...any) ([]string, error) {
+	rows, err := db.Query(context.Background(), q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	values := make([]string, 0)
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,92 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ej-agas/perfume-db/internal"
+)
+
+type AutocompleteService struct {
+	db DB
+}
+
+// Suggest returns up to perTypeLimit items of every requested type whose name
+// either starts with the query or is trigram-similar to it.
+func (service AutocompleteService) Suggest(query string, types []internal.EntityType, perTypeLimit int, threshold float64) ([]internal.AutocompleteItem, error) {
+	items := make([]internal.AutocompleteItem, 0)
+
+	var subQueries []string
+	for _, entityType := range types {
+		table, ok := entityTables[entityType]
+		if !ok {
+			return nil, fmt.Errorf("unknown entity type: %s", entityType)
+		}
+
+		subQueries = append(subQueries, fmt.Sprintf(`
+			(SELECT '%s' AS type, public_id, slug, name,
+				GREATEST(similarity(name, $1), word_similarity($1, name)) + CASE WHEN name ILIKE $2 THEN 1 ELSE 0 END AS score
+			FROM %s
+			WHERE (name ILIKE $2 OR name %% $1 OR $1 <%% name) AND deleted_at IS NULL
+			ORDER BY score DESC, name
+			LIMIT $3)
+		`, entityType, table))
+	}
+
+	if len(subQueries) == 0 {
+		return items, nil
+	}
+
+	q := fmt.Sprintf(
+		"SELECT type, public_id, slug, name FROM (%s) suggestions ORDER BY score DESC, name",
+		strings.Join(subQueries, " UNION ALL "),
+	)
+
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	// The % and <% operators compare against these settings, which lets the
+	// trigram indexes serve the query while honouring the requested threshold.
+	thresholdSetting := strconv.FormatFloat(threshold, 'f', -1, 64)
+	_, err = tx.Exec(
+		context.Background(),
+		`SELECT set_config('pg_trgm.similarity_threshold', $1, true), set_config('pg_trgm.word_similarity_threshold', $1, true)`,
+		thresholdSetting,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set similarity threshold: %w", err)
+	}
+
+	rows, err := tx.Query(context.Background(), q, query, escapeLike(query)+"%", perTypeLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item internal.AutocompleteItem
+		var entityType string
+
+		if err := rows.Scan(&entityType, &item.PublicId, &item.Slug, &item.Name); err != nil {
+			return nil, err
+		}
+
+		item.Type = internal.EntityType(entityType)
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, tx.Commit(context.Background())
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,222 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
+)
+
+type ChangeRequestService struct {
+	db DB
+}
+
+var (
+	ErrChangeRequestNotFound = fmt.Errorf("change request not found")
+)
+
+const changeRequestSelectQuery = `
+	SELECT c.id,
+	       c.public_id,
+	       c.entity_type,
+	       c.entity_id,
+	       c.patch,
+	       c.reason,
+	       c.status,
+	       c.proposer_id,
+	       proposer.username,
+	       c.reviewer_id,
+	       reviewer.username,
+	       c.comment,
+	       c.created_at,
+	       c.reviewed_at
+	FROM change_requests c
+	JOIN users proposer ON proposer.id = c.proposer_id
+	LEFT JOIN users reviewer ON reviewer.id = c.reviewer_id
+`
+
+func scanChangeRequest(row pgx.Row) (*internal.ChangeRequest, error) {
+	var changeRequest internal.ChangeRequest
+	var entityType, status string
+	var patch []byte
+	var reviewerId *int
+	var reason, reviewer, comment *string
+
+	if err := row.Scan(
+		&changeRequest.ID,
+		&changeRequest.PublicId,
+		&entityType,
+		&changeRequest.EntityId,
+		&patch,
+		&reason,
+		&status,
+		&changeRequest.ProposerId,
+		&changeRequest.Proposer,
+		&reviewerId,
+		&reviewer,
+		&comment,
+		&changeRequest.CreatedAt,
+		&changeRequest.ReviewedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	changeRequest.EntityType = internal.EntityType(entityType)
+	changeRequest.Status = internal.ChangeRequestStatus(status)
+	changeRequest.Patch = patch
+
+	if reason != nil {
+		changeRequest.Reason = *reason
+	}
+	if reviewerId != nil {
+		changeRequest.ReviewerId = *reviewerId
+	}
+	if reviewer != nil {
+		changeRequest.Reviewer = *reviewer
+	}
+	if comment != nil {
+		changeRequest.Comment = *comment
+	}
+
+	return &changeRequest, nil
+}
+
+func (service ChangeRequestService) Save(changeRequest *internal.ChangeRequest) error {
+	err := service.db.QueryRow(
+		context.Background(),
+		`
+		INSERT INTO change_requests (public_id, entity_type, entity_id, patch, reason, status, proposer_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+		`,
+		changeRequest.PublicId,
+		changeRequest.EntityType.String(),
+		changeRequest.EntityId,
+		[]byte(changeRequest.Patch),
+		changeRequest.Reason,
+		changeRequest.Status.String(),
+		changeRequest.ProposerId,
+		changeRequest.CreatedAt,
+	).Scan(&changeRequest.ID)
+
+	if err != nil {
+		return fmt.Errorf("save change request error: %w", err)
+	}
+
+	return nil
+}
+
+// Review stores the outcome of a review. Only pending change requests can be
+// reviewed.
+func (service ChangeRequestService) Review(changeRequest *internal.ChangeRequest) error {
+	var reviewerId, comment any
+	if changeRequest.ReviewerId != 0 {
+		reviewerId = changeRequest.ReviewerId
+	}
+	if changeRequest.Comment != "" {
+		comment = changeRequest.Comment
+	}
+
+	tag, err := service.db.Exec(
+		context.Background(),
+		`
+		UPDATE change_requests
+		SET status = $2,
+		    reviewer_id = $3,
+		    comment = $4,
+		    reviewed_at = $5
+		WHERE id = $1 AND status = 'pending'
+		`,
+		changeRequest.ID,
+		changeRequest.Status.String(),
+		reviewerId,
+		comment,
+		changeRequest.ReviewedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("review change request error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: change request with public_id '%s'", internal.ErrChangeRequestNotPending, changeRequest.PublicId)
+	}
+
+	return nil
+}
+
+func (service ChangeRequestService) Find(publicId string) (*internal.ChangeRequest, error) {
+	changeRequest, err := scanChangeRequest(service.db.QueryRow(context.Background(), changeRequestSelectQuery+" WHERE c.public_id = $1", publicId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: change request with public_id '%s' not found", ErrChangeRequestNotFound, publicId)
+		}
+
+		return nil, err
+	}
+
+	return changeRequest, nil
+}
+
+func (service ChangeRequestService) List(filter internal.ChangeRequestFilter, cursor, perPage int) ([]internal.ChangeRequest, error) {
+	if cursor <= 0 {
+		cursor = 0
+	}
+
+	args := []any{cursor}
+	conditions := []string{"c.id > $1"}
+
+	placeholder := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "c.status = "+placeholder(filter.Status.String()))
+	}
+
+	if filter.ProposerId != 0 {
+		conditions = append(conditions, "c.proposer_id = "+placeholder(filter.ProposerId))
+	}
+
+	if filter.EntityType != "" {
+		conditions = append(conditions, "c.entity_type = "+placeholder(filter.EntityType.String()))
+	}
+
+	if filter.EntityId != "" {
+		conditions = append(conditions, "c.entity_id = "+placeholder(filter.EntityId))
+	}
+
+	q := fmt.Sprintf(
+		"%s WHERE %s ORDER BY c.id LIMIT %s",
+		changeRequestSelectQuery,
+		strings.Join(conditions, " AND "),
+		placeholder(perPage),
+	)
+
+	rows, err := service.db.Query(context.Background(), q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	changeRequests := make([]internal.ChangeRequest, 0)
+	for rows.Next() {
+		changeRequest, err := scanChangeRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		changeRequests = append(changeRequests, *changeRequest)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changeRequests, nil
+}
@@ -0,0 +1,321 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type CollectionService struct {
+	db DB
+}
+
+var (
+	ErrCollectionItemNotFound      = fmt.Errorf("collection item not found")
+	ErrCollectionItemAlreadyExists = fmt.Errorf("perfume is already on the shelf")
+)
+
+var collectionItemColumns = []string{
+	"id",
+	"public_id",
+	"user_id",
+	"shelf",
+	"perfume_id",
+	"bottle_size_ml",
+	"purchase_date",
+	"price",
+	"currency",
+	"notes",
+	"created_at",
+	"updated_at",
+}
+
+func scanCollectionItem(row pgx.Row) (*internal.CollectionItem, error) {
+	var item internal.CollectionItem
+	var shelf string
+	var currency *string
+
+	if err := row.Scan(
+		&item.ID,
+		&item.PublicId,
+		&item.UserId,
+		&shelf,
+		&item.PerfumeId,
+		&item.BottleSizeMl,
+		&item.PurchaseDate,
+		&item.Price,
+		&currency,
+		&item.Notes,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	item.Shelf = internal.Shelf(shelf)
+	if currency != nil {
+		item.Currency = *currency
+	}
+
+	return &item, nil
+}
+
+func (service CollectionService) Save(item *internal.CollectionItem) error {
+	var currency any
+	if item.Currency != "" {
+		currency = item.Currency
+	}
+
+	var err error
+	if item.ID == 0 {
+		err = service.db.QueryRow(
+			context.Background(),
+			`
+			INSERT INTO collection_items (public_id, user_id, shelf, perfume_id, bottle_size_ml, purchase_date, price, currency, notes, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING id
+			`,
+			item.PublicId,
+			item.UserId,
+			item.Shelf.String(),
+			item.PerfumeId,
+			item.BottleSizeMl,
+			item.PurchaseDate,
+			item.Price,
+			currency,
+			item.Notes,
+			item.CreatedAt,
+			item.UpdatedAt,
+		).Scan(&item.ID)
+	} else {
+		item.UpdatedAt = time.Now()
+		_, err = service.db.Exec(
+			context.Background(),
+			`
+			UPDATE collection_items
+			SET shelf = $2,
+			    bottle_size_ml = $3,
+			    purchase_date = $4,
+			    price = $5,
+			    currency = $6,
+			    notes = $7,
+			    updated_at = $8
+			WHERE id = $1
+			`,
+			item.ID,
+			item.Shelf.String(),
+			item.BottleSizeMl,
+			item.PurchaseDate,
+			item.Price,
+			currency,
+			item.Notes,
+			item.UpdatedAt,
+		)
+	}
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		ok := errors.As(err, &pgErr)
+		if !ok {
+			return fmt.Errorf("save collection item error: %w", err)
+		}
+
+		switch pgErr.Code {
+		case "23505":
+			return fmt.Errorf("database error: %w: %w", ErrCollectionItemAlreadyExists, pgErr)
+		case "23503":
+			return fmt.Errorf("database error: %w: %w", ErrPerfumeNotFound, pgErr)
+		default:
+			return fmt.Errorf("save collection item error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Find returns an item of the user's collection. Items of other users are
+// reported as not found.
+func (service CollectionService) Find(userId int, publicId string) (*internal.CollectionItem, error) {
+	q := fmt.Sprintf(`SELECT %s FROM collection_items WHERE user_id = $1 AND public_id = $2`, columns("", collectionItemColumns))
+
+	item, err := scanCollectionItem(service.db.QueryRow(context.Background(), q, userId, publicId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: collection item with public_id '%s' not found", ErrCollectionItemNotFound, publicId)
+		}
+
+		return nil, err
+	}
+
+	if err := service.loadPerfumes([]*internal.CollectionItem{item}); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (service CollectionService) List(filter internal.CollectionFilter, cursor, perPage int) ([]internal.CollectionItem, error) {
+	if cursor <= 0 {
+		cursor = 0
+	}
+
+	args := []any{cursor, filter.UserId}
+	conditions := []string{"c.id > $1", "c.user_id = $2"}
+
+	if filter.Shelf != "" {
+		args = append(args, filter.Shelf.String())
+		conditions = append(conditions, "c.shelf = $"+strconv.Itoa(len(args)))
+	}
+
+	if filter.PublicOnly {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM shelves s WHERE s.user_id = c.user_id AND s.shelf = c.shelf AND s.public)")
+	}
+
+	args = append(args, perPage)
+	q := fmt.Sprintf(
+		"SELECT %s FROM collection_items c WHERE %s ORDER BY c.id LIMIT $%d",
+		columns("c", collectionItemColumns),
+		strings.Join(conditions, " AND "),
+		len(args),
+	)
+
+	rows, err := service.db.Query(context.Background(), q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*internal.CollectionItem, 0)
+	for rows.Next() {
+		item, err := scanCollectionItem(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := service.loadPerfumes(items); err != nil {
+		return nil, err
+	}
+
+	result := make([]internal.CollectionItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, *item)
+	}
+
+	return result, nil
+}
+
+// loadPerfumes attaches the perfume of every item. Perfumes that have since
+// been deleted are left out, keeping only the item's perfume_id.
+func (service CollectionService) loadPerfumes(items []*internal.CollectionItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	perfumeIds := make([]string, 0, len(items))
+	for _, item := range items {
+		perfumeIds = append(perfumeIds, item.PerfumeId)
+	}
+
+	perfumes, err := PerfumeService{db: service.db}.byPublicId(perfumeIds)
+	if err != nil {
+		return fmt.Errorf("load collection perfumes error: %w", err)
+	}
+
+	for _, item := range items {
+		item.Perfume = perfumes[item.PerfumeId]
+	}
+
+	return nil
+}
+
+func (service CollectionService) Delete(item *internal.CollectionItem) error {
+	tag, err := service.db.Exec(context.Background(), `DELETE FROM collection_items WHERE id = $1`, item.ID)
+	if err != nil {
+		return fmt.Errorf("delete collection item error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: collection item with public_id '%s' not found", ErrCollectionItemNotFound, item.PublicId)
+	}
+
+	return nil
+}
+
+// Shelves returns the visibility and size of every shelf of the user,
+// including the ones they never touched.
+func (service CollectionService) Shelves(userId int) ([]internal.ShelfSettings, error) {
+	shelves := make([]string, 0, len(internal.Shelves))
+	for _, shelf := range internal.Shelves {
+		shelves = append(shelves, shelf.String())
+	}
+
+	rows, err := service.db.Query(
+		context.Background(),
+		`
+		SELECT names.shelf,
+		       COALESCE(s.public, false),
+		       (SELECT COUNT(*) FROM collection_items c WHERE c.user_id = $1 AND c.shelf = names.shelf)
+		FROM unnest($2::varchar[]) WITH ORDINALITY AS names (shelf, position)
+		LEFT JOIN shelves s ON s.user_id = $1 AND s.shelf = names.shelf
+		ORDER BY names.position
+		`,
+		userId,
+		shelves,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	settings := make([]internal.ShelfSettings, 0, len(internal.Shelves))
+	for rows.Next() {
+		var setting internal.ShelfSettings
+		var shelf string
+
+		if err := rows.Scan(&shelf, &setting.Public, &setting.Count); err != nil {
+			return nil, err
+		}
+
+		setting.Shelf = internal.Shelf(shelf)
+		settings = append(settings, setting)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (service CollectionService) SetVisibility(userId int, shelf internal.Shelf, public bool) error {
+	_, err := service.db.Exec(
+		context.Background(),
+		`
+		INSERT INTO shelves (user_id, shelf, public) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, shelf) DO UPDATE SET public = EXCLUDED.public
+		`,
+		userId,
+		shelf.String(),
+		public,
+	)
+
+	if err != nil {
+		return fmt.Errorf("set shelf visibility error: %w", err)
+	}
+
+	return nil
+}
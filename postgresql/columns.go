@@ -0,0 +1,18 @@
+package postgresql
+
+import "strings"
+
+// columns joins the given column names into a select list, qualifying each of
+// them with the table alias when one is given.
+func columns(alias string, names []string) string {
+	if alias == "" {
+		return strings.Join(names, ", ")
+	}
+
+	qualified := make([]string, len(names))
+	for i, name := range names {
+		qualified[i] = alias + "." + name
+	}
+
+	return strings.Join(qualified, ", ")
+}
@@ -0,0 +1,11 @@
+package postgresql
+
+import "github.com/ej-agas/perfume-db/internal"
+
+var entityTables = map[internal.EntityType]string{
+	internal.HouseEntity:     "houses",
+	internal.PerfumerEntity:  "perfumers",
+	internal.NoteEntity:      "notes",
+	internal.NoteGroupEntity: "note_groups",
+	internal.PerfumeEntity:   "perfumes",
+}
@@ -5,4 +5,5 @@ import "fmt"
 var (
 	ErrAcquiringConn = fmt.Errorf("error acquiring connection from database connection pool")
 	ErrStartingDBTx  = fmt.Errorf("error starting database transaction")
+	ErrStaleVersion  = fmt.Errorf("entity has been modified since it was read")
 )
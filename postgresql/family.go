@@ -0,0 +1,574 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/internal/similarity"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type FamilyService struct {
+	db DB
+}
+
+var (
+	ErrFamilyNotFound      = fmt.Errorf("family not found")
+	ErrFamilyAlreadyExists = fmt.Errorf("family already exists")
+	ErrFamilyInUse         = fmt.Errorf("family has subfamilies")
+	ErrFamilyNesting       = fmt.Errorf("subfamilies cannot have subfamilies")
+)
+
+const familySelectQuery = `
+	SELECT f.id,
+	       f.public_id,
+	       COALESCE(f.parent_id, ''),
+	       f.slug,
+	       f.name,
+	       f.description,
+	       COALESCE((SELECT array_agg(fng.note_group_id ORDER BY fng.note_group_id) FROM families_note_groups fng WHERE fng.family_id = f.public_id), '{}'),
+	       f.created_at,
+	       f.updated_at,
+	       f.version
+	FROM families f
+`
+
+func familyFields(family *internal.Family) []any {
+	return []any{
+		&family.ID,
+		&family.PublicId,
+		&family.ParentId,
+		&family.Slug,
+		&family.Name,
+		&family.Description,
+		&family.NoteGroupIds,
+		&family.CreatedAt,
+		&family.UpdatedAt,
+		&family.Version,
+	}
+}
+
+func (service FamilyService) List(cursor, perPage int) ([]internal.Family, error) {
+	if cursor <= 0 {
+		cursor = 0
+	}
+
+	rows, err := service.db.Query(context.Background(), familySelectQuery+" WHERE f.id > $1 ORDER BY f.id LIMIT $2", cursor, perPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	families := make([]internal.Family, 0)
+	for rows.Next() {
+		var family internal.Family
+		if err := rows.Scan(familyFields(&family)...); err != nil {
+			return nil, err
+		}
+
+		families = append(families, family)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return families, nil
+}
+
+// Save stores the family and its note groups, then reclassifies the perfumes
+// the change can move: those with notes in the family's old or new note
+// groups. Perfumes whose family was chosen by an editor are left alone.
+func (service FamilyService) Save(family *internal.Family) error {
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	if err := service.checkNesting(tx, family); err != nil {
+		return err
+	}
+
+	if family.ID == 0 {
+		err = service.saveNewFamily(tx, family)
+	} else {
+		err = service.updateFamily(tx, family)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// The old note groups are still stored at this point.
+	perfumeIds, err := familyPerfumes(tx, family.PublicId, family.NoteGroupIds)
+	if err != nil {
+		return err
+	}
+
+	if err := service.replaceNoteGroups(tx, family); err != nil {
+		return err
+	}
+
+	if err := classifyPerfumes(tx, perfumeIds); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
+
+// checkNesting keeps the taxonomy two levels deep: the parent of a family
+// must be a top level family, and a family with subfamilies cannot become a
+// subfamily itself.
+func (service FamilyService) checkNesting(tx pgx.Tx, family *internal.Family) error {
+	if !family.IsSubfamily() {
+		return nil
+	}
+
+	if family.ParentId == family.PublicId {
+		return fmt.Errorf("%w: family '%s' cannot be its own parent", ErrFamilyNesting, family.PublicId)
+	}
+
+	var grandparentId *string
+	err := tx.QueryRow(context.Background(), `SELECT parent_id FROM families WHERE public_id = $1`, family.ParentId).Scan(&grandparentId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: parent family with public_id '%s' not found", ErrFamilyNotFound, family.ParentId)
+		}
+
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if grandparentId != nil {
+		return fmt.Errorf("%w: family '%s' is a subfamily", ErrFamilyNesting, family.ParentId)
+	}
+
+	if family.ID == 0 {
+		return nil
+	}
+
+	var hasSubfamilies bool
+	err = tx.QueryRow(context.Background(), `SELECT EXISTS (SELECT 1 FROM families WHERE parent_id = $1)`, family.PublicId).Scan(&hasSubfamilies)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if hasSubfamilies {
+		return fmt.Errorf("%w: family '%s' has subfamilies", ErrFamilyNesting, family.PublicId)
+	}
+
+	return nil
+}
+
+func (service FamilyService) saveNewFamily(tx pgx.Tx, family *internal.Family) error {
+	err := tx.QueryRow(
+		context.Background(),
+		`
+		INSERT INTO families (public_id, parent_id, slug, name, description, created_at, updated_at)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7)
+		RETURNING id, version
+		`,
+		family.PublicId,
+		family.ParentId,
+		family.Slug,
+		family.Name,
+		family.Description,
+		family.CreatedAt,
+		family.UpdatedAt,
+	).Scan(&family.ID, &family.Version)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("database error: %w: %w", ErrFamilyAlreadyExists, pgErr)
+		}
+
+		return fmt.Errorf("database error: insert family error: %w", err)
+	}
+
+	return nil
+}
+
+func (service FamilyService) updateFamily(tx pgx.Tx, family *internal.Family) error {
+	family.UpdatedAt = time.Now()
+	tag, err := tx.Exec(
+		context.Background(),
+		`
+		UPDATE families
+		SET parent_id = NULLIF($2, ''),
+		    slug = $3,
+		    name = $4,
+		    description = $5,
+		    updated_at = $6,
+		    version = version + 1
+		WHERE id = $1 AND version = $7
+		`,
+		family.ID,
+		family.ParentId,
+		family.Slug,
+		family.Name,
+		family.Description,
+		family.UpdatedAt,
+		family.Version,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("database error: %w: %w", ErrFamilyAlreadyExists, pgErr)
+		}
+
+		return fmt.Errorf("database error: update family error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: family with public_id '%s'", ErrStaleVersion, family.PublicId)
+	}
+
+	family.Version++
+
+	return nil
+}
+
+func (service FamilyService) replaceNoteGroups(tx pgx.Tx, family *internal.Family) error {
+	if _, err := tx.Exec(context.Background(), `DELETE FROM families_note_groups WHERE family_id = $1`, family.PublicId); err != nil {
+		return fmt.Errorf("database error: delete family note groups error: %w", err)
+	}
+
+	_, err := tx.Exec(
+		context.Background(),
+		`
+		INSERT INTO families_note_groups (family_id, note_group_id)
+		SELECT $1, note_group_id FROM unnest($2::varchar[]) AS note_group_id
+		ON CONFLICT DO NOTHING
+		`,
+		family.PublicId,
+		family.NoteGroupIds,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("database error: %w: %w", ErrNoteGroupNotFound, pgErr)
+		}
+
+		return fmt.Errorf("database error: insert family note groups error: %w", err)
+	}
+
+	return nil
+}
+
+func (service FamilyService) Find(publicId string) (*internal.Family, error) {
+	var family internal.Family
+
+	if err := service.db.QueryRow(context.Background(), familySelectQuery+" WHERE f.public_id = $1", publicId).
+		Scan(familyFields(&family)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: family with public_id '%s' not found", ErrFamilyNotFound, publicId)
+		}
+
+		return nil, err
+	}
+
+	return &family, nil
+}
+
+func (service FamilyService) FindMany(publicIds []string) ([]*internal.Family, error) {
+	families := make([]*internal.Family, 0)
+
+	if len(publicIds) == 0 {
+		return families, nil
+	}
+
+	rows, err := service.db.Query(context.Background(), familySelectQuery+" WHERE f.public_id = ANY($1)", publicIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	found := make(map[string]bool)
+	for _, id := range publicIds {
+		found[id] = false
+	}
+
+	for rows.Next() {
+		var family internal.Family
+		if err := rows.Scan(familyFields(&family)...); err != nil {
+			return nil, err
+		}
+
+		found[family.PublicId] = true
+		families = append(families, &family)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for id, ok := range found {
+		if !ok {
+			return nil, fmt.Errorf("%w: family with public_id '%s' not found", ErrFamilyNotFound, id)
+		}
+	}
+
+	return families, nil
+}
+
+func (service FamilyService) FindBySlug(s string) (*internal.Family, error) {
+	var family internal.Family
+
+	if err := service.db.QueryRow(context.Background(), familySelectQuery+" WHERE f.slug = $1", s).
+		Scan(familyFields(&family)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: family with slug '%s' not found", ErrFamilyNotFound, s)
+		}
+
+		return nil, err
+	}
+
+	return &family, nil
+}
+
+// Delete removes a family without subfamilies. Perfumes classified into it
+// are reclassified, and perfumes an editor put into it lose that family.
+func (service FamilyService) Delete(publicId string) error {
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	perfumeIds, err := familyPerfumes(tx, publicId, nil)
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(context.Background(), `DELETE FROM families WHERE public_id = $1`, publicId)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("database error: %w: %w", ErrFamilyInUse, pgErr)
+		}
+
+		return fmt.Errorf("database error: delete family error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: family with public_id '%s' not found", ErrFamilyNotFound, publicId)
+	}
+
+	if err := classifyPerfumes(tx, perfumeIds); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
+
+// Override puts the perfume into the given families regardless of what the
+// classifier proposes. The secondary family is optional.
+func (service FamilyService) Override(perfumeId, primaryId, secondaryId string) error {
+	tag, err := service.db.Exec(
+		context.Background(),
+		`
+		UPDATE perfumes
+		SET primary_family_id = $2, secondary_family_id = NULLIF($3, ''), family_overridden = true
+		WHERE public_id = $1 AND deleted_at IS NULL
+		`,
+		perfumeId,
+		primaryId,
+		secondaryId,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("database error: %w: %w", ErrFamilyNotFound, pgErr)
+		}
+
+		return fmt.Errorf("database error: override perfume families error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: perfume with public_id '%s' not found", ErrPerfumeNotFound, perfumeId)
+	}
+
+	return nil
+}
+
+// ClearOverride hands the families of the perfume back to the classifier.
+func (service FamilyService) ClearOverride(perfumeId string) error {
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	tag, err := tx.Exec(
+		context.Background(),
+		`UPDATE perfumes SET family_overridden = false WHERE public_id = $1 AND deleted_at IS NULL`,
+		perfumeId,
+	)
+	if err != nil {
+		return fmt.Errorf("database error: clear perfume families override error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: perfume with public_id '%s' not found", ErrPerfumeNotFound, perfumeId)
+	}
+
+	if err := classifyPerfumes(tx, []string{perfumeId}); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
+
+// Propose returns what the classifier makes of the perfume's notes, whether
+// or not an editor overrode its families.
+func (service FamilyService) Propose(perfumeId string) (similarity.Classification, error) {
+	families, err := familyTaxonomy(service.db)
+	if err != nil {
+		return similarity.Classification{}, err
+	}
+
+	perfumes, err := similarityPerfumes(service.db, []string{perfumeId})
+	if err != nil {
+		return similarity.Classification{}, err
+	}
+
+	if len(perfumes) == 0 {
+		return similarity.Classify(similarity.Perfume{ID: perfumeId}, families, similarity.DefaultWeights), nil
+	}
+
+	return similarity.Classify(perfumes[0], families, similarity.DefaultWeights), nil
+}
+
+func familyTaxonomy(db DB) ([]similarity.Family, error) {
+	rows, err := db.Query(
+		context.Background(),
+		`
+		SELECT f.public_id, COALESCE(f.parent_id, ''), COALESCE(array_agg(fng.note_group_id) FILTER (WHERE fng.note_group_id IS NOT NULL), '{}')
+		FROM families f
+		LEFT JOIN families_note_groups fng ON fng.family_id = f.public_id
+		GROUP BY f.id
+		ORDER BY f.id
+		`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	families := make([]similarity.Family, 0)
+	for rows.Next() {
+		var family similarity.Family
+		if err := rows.Scan(&family.ID, &family.ParentID, &family.NoteGroups); err != nil {
+			return nil, err
+		}
+
+		families = append(families, family)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return families, nil
+}
+
+// familyPerfumes returns the classifier-managed perfumes a change to the
+// family can move: those in the family now, and those with notes in its stored
+// note groups or in noteGroupIds. A family only scores perfumes sharing its
+// note groups, so the rest of the catalogue keeps its families.
+func familyPerfumes(db DB, familyId string, noteGroupIds []string) ([]string, error) {
+	perfumeIds, err := collectStrings(
+		db,
+		`
+		SELECT p.public_id
+		FROM perfumes p
+		WHERE p.deleted_at IS NULL
+		  AND NOT p.family_overridden
+		  AND (
+		    p.primary_family_id = $1
+		    OR p.secondary_family_id = $1
+		    OR EXISTS (
+		      SELECT 1
+		      FROM perfumes_notes pn
+		      JOIN notes n ON n.public_id = pn.note_id
+		      WHERE pn.perfume_id = p.public_id
+		        AND (n.note_group_id = ANY($2::varchar[]) OR n.note_group_id IN (SELECT note_group_id FROM families_note_groups WHERE family_id = $1))
+		    )
+		  )
+		ORDER BY p.id
+		`,
+		familyId,
+		noteGroupIds,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("database error: load family perfumes error: %w", err)
+	}
+
+	return perfumeIds, nil
+}
+
+// classifyPerfumes stores the classifier's proposal as the families of the
+// given perfumes, or of every perfume when publicIds is nil. Perfumes whose
+// families were chosen by an editor are left alone.
+func classifyPerfumes(db DB, publicIds []string) error {
+	if publicIds != nil && len(publicIds) == 0 {
+		return nil
+	}
+
+	families, err := familyTaxonomy(db)
+	if err != nil {
+		return fmt.Errorf("load family taxonomy error: %w", err)
+	}
+
+	perfumes, err := similarityPerfumes(db, publicIds)
+	if err != nil {
+		return fmt.Errorf("load perfume notes error: %w", err)
+	}
+
+	// Perfumes without notes are not returned, but still need their
+	// families cleared.
+	classified := make(map[string]bool, len(perfumes))
+	classifications := make([]similarity.Classification, 0, len(publicIds))
+	for _, perfume := range perfumes {
+		classified[perfume.ID] = true
+		classifications = append(classifications, similarity.Classify(perfume, families, similarity.DefaultWeights))
+	}
+
+	for _, id := range publicIds {
+		if !classified[id] {
+			classifications = append(classifications, similarity.Classification{PerfumeID: id})
+		}
+	}
+
+	if len(classifications) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, classification := range classifications {
+		batch.Queue(
+			`
+			UPDATE perfumes
+			SET primary_family_id = NULLIF($2, ''), secondary_family_id = NULLIF($3, '')
+			WHERE public_id = $1 AND NOT family_overridden
+			`,
+			classification.PerfumeID,
+			classification.Primary,
+			classification.Secondary,
+		)
+	}
+
+	if err := db.SendBatch(context.Background(), batch).Close(); err != nil {
+		return fmt.Errorf("database error: classify perfumes error: %w", err)
+	}
+
+	return nil
+}
@@ -7,21 +7,50 @@ import (
 	"time"
 
 	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type HouseService struct {
-	db *pgxpool.Pool
+	db DB
 }
 
 var (
 	ErrHouseAlreadyExists = fmt.Errorf("error house already exists")
 	ErrHouseNotFound      = fmt.Errorf("house not found")
+	ErrHouseInUse         = fmt.Errorf("house is referenced by a perfume")
 )
 
+var houseColumns = []string{
+	"id",
+	"public_id",
+	"slug",
+	"name",
+	"country",
+	"description",
+	"year_founded",
+	"created_at",
+	"updated_at",
+	"version",
+}
+
+func houseFields(house *internal.House) []any {
+	return []any{
+		&house.ID,
+		&house.PublicId,
+		&house.Slug,
+		&house.Name,
+		&house.Country,
+		&house.Description,
+		&house.YearFounded,
+		&house.CreatedAt,
+		&house.UpdatedAt,
+		&house.Version,
+	}
+}
+
 func (service HouseService) List(cursor, perPage int) ([]internal.House, error) {
-	q := `SELECT * FROM houses WHERE id > $1 ORDER BY id LIMIT $2`
+	q := fmt.Sprintf(`SELECT %s FROM houses WHERE id > $1 AND deleted_at IS NULL ORDER BY id LIMIT $2`, columns("", houseColumns))
 	if cursor <= 0 {
 		cursor = 0
 	}
@@ -35,17 +64,7 @@ func (service HouseService) List(cursor, perPage int) ([]internal.House, error)
 	var houses []internal.House
 	for rows.Next() {
 		var house internal.House
-		err := rows.Scan(
-			&house.ID,
-			&house.PublicId,
-			&house.Slug,
-			&house.Name,
-			&house.Country,
-			&house.Description,
-			&house.YearFounded,
-			&house.CreatedAt,
-			&house.UpdatedAt,
-		)
+		err := rows.Scan(houseFields(&house)...)
 		if err != nil {
 			return nil, err
 		}
@@ -59,20 +78,37 @@ func (service HouseService) List(cursor, perPage int) ([]internal.House, error)
 	return houses, nil
 }
 
-func (service HouseService) Save(house *internal.House) error {
+func (service HouseService) Save(house *internal.House, meta internal.ChangeMeta) error {
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
 	if house.ID == 0 {
-		return service.saveNewHouse(house)
+		err = service.saveNewHouse(tx, house)
+	} else {
+		err = service.updateHouse(tx, house)
+	}
+
+	if err != nil {
+		return err
 	}
 
-	return service.updateHouse(house)
+	if err := recordRevision(tx, internal.HouseEntity, house.PublicId, house, meta); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
 }
 
-func (service HouseService) saveNewHouse(house *internal.House) error {
+func (service HouseService) saveNewHouse(tx pgx.Tx, house *internal.House) error {
 	q := `
 		INSERT INTO houses (public_id, slug, name, country, description, year_founded, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, version
 	`
-	_, err := service.db.Exec(
+	err := tx.QueryRow(
 		context.Background(),
 		q,
 		house.PublicId,
@@ -83,7 +119,7 @@ func (service HouseService) saveNewHouse(house *internal.House) error {
 		house.YearFounded,
 		house.CreatedAt,
 		house.UpdatedAt,
-	)
+	).Scan(&house.ID, &house.Version)
 
 	if err == nil {
 		return nil
@@ -102,7 +138,7 @@ func (service HouseService) saveNewHouse(house *internal.House) error {
 	return err
 }
 
-func (service HouseService) updateHouse(house *internal.House) error {
+func (service HouseService) updateHouse(tx pgx.Tx, house *internal.House) error {
 	q := `
 		UPDATE houses 
 		SET slug = $2,
@@ -110,12 +146,13 @@ func (service HouseService) updateHouse(house *internal.House) error {
 		    country = $4,
 		    description = $5,
 		    year_founded = $6,
-		    updated_at = $7
-		WHERE id = $1
+		    updated_at = $7,
+		    version = version + 1
+		WHERE id = $1 AND version = $8
 	`
 
 	house.UpdatedAt = time.Now()
-	_, err := service.db.Exec(context.Background(),
+	tag, err := tx.Exec(context.Background(),
 		q,
 		house.ID,
 		house.Slug,
@@ -124,32 +161,34 @@ func (service HouseService) updateHouse(house *internal.House) error {
 		house.Description,
 		house.YearFounded,
 		house.UpdatedAt,
+		house.Version,
 	)
 
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("database error: %w: %w", ErrHouseAlreadyExists, pgErr)
+		}
+
 		return fmt.Errorf("update house error: %w", err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: house with public_id '%s'", ErrStaleVersion, house.PublicId)
+	}
+
+	house.Version++
+
 	return nil
 }
 
 func (service HouseService) Find(publicId string) (*internal.House, error) {
 	var house internal.House
 
-	q := `SELECT * FROM houses WHERE public_id = $1`
+	q := fmt.Sprintf(`SELECT %s FROM houses WHERE public_id = $1 AND deleted_at IS NULL`, columns("", houseColumns))
 
 	if err := service.db.QueryRow(context.Background(), q, publicId).
-		Scan(
-			&house.ID,
-			&house.PublicId,
-			&house.Slug,
-			&house.Name,
-			&house.Country,
-			&house.Description,
-			&house.YearFounded,
-			&house.CreatedAt,
-			&house.UpdatedAt,
-		); err != nil {
+		Scan(houseFields(&house)...); err != nil {
 		return nil, err
 	}
 
@@ -159,22 +198,23 @@ func (service HouseService) Find(publicId string) (*internal.House, error) {
 func (service HouseService) FindBySlug(s string) (*internal.House, error) {
 	var house internal.House
 
-	q := `SELECT * FROM houses WHERE slug = $1`
+	q := fmt.Sprintf(`SELECT %s FROM houses WHERE slug = $1 AND deleted_at IS NULL`, columns("", houseColumns))
 
 	if err := service.db.QueryRow(context.Background(), q, s).
-		Scan(
-			&house.ID,
-			&house.PublicId,
-			&house.Slug,
-			&house.Name,
-			&house.Country,
-			&house.Description,
-			&house.YearFounded,
-			&house.CreatedAt,
-			&house.UpdatedAt,
-		); err != nil {
+		Scan(houseFields(&house)...); err != nil {
 		return nil, err
 	}
 
 	return &house, nil
 }
+
+func (service HouseService) Delete(publicId string) error {
+	return softDelete(service.db, "houses", publicId, ErrHouseNotFound, guard{
+		query: `SELECT 1 FROM perfumes WHERE house_id = $1 AND deleted_at IS NULL`,
+		err:   ErrHouseInUse,
+	})
+}
+
+func (service HouseService) Restore(publicId string) error {
+	return restore(service.db, "houses", publicId, ErrHouseNotFound, ErrHouseAlreadyExists)
+}
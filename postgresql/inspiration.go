@@ -0,0 +1,279 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/internal/similarity"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type InspirationService struct {
+	db DB
+}
+
+var (
+	ErrInspirationNotFound      = fmt.Errorf("inspiration not found")
+	ErrInspirationAlreadyExists = fmt.Errorf("inspiration already exists")
+	ErrInspirationSelf          = fmt.Errorf("perfume cannot be inspired by itself")
+	ErrInspirationVoteNotFound  = fmt.Errorf("inspiration vote not found")
+)
+
+// inspirationSelectQuery selects the links between live perfumes with their
+// vote counts. The %s placeholder takes the WHERE clause.
+const inspirationSelectQuery = `
+	SELECT i.id,
+	       i.public_id,
+	       i.perfume_id,
+	       i.original_id,
+	       COALESCE(i.created_by, 0),
+	       i.created_at,
+	       COUNT(v.user_id) FILTER (WHERE v.agrees),
+	       COUNT(v.user_id) FILTER (WHERE NOT v.agrees)
+	FROM inspirations i
+	JOIN perfumes p ON p.public_id = i.perfume_id AND p.deleted_at IS NULL
+	JOIN perfumes o ON o.public_id = i.original_id AND o.deleted_at IS NULL
+	LEFT JOIN inspiration_votes v ON v.inspiration_id = i.id
+	%s
+	GROUP BY i.id
+`
+
+// Save records the link together with the proposer's agreeing vote.
+func (service InspirationService) Save(inspiration *internal.Inspiration) error {
+	if inspiration.PerfumeId == inspiration.OriginalId {
+		return fmt.Errorf("%w: perfume with public_id '%s'", ErrInspirationSelf, inspiration.PerfumeId)
+	}
+
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	var live int
+	err = tx.QueryRow(
+		context.Background(),
+		`SELECT COUNT(*) FROM perfumes WHERE public_id IN ($1, $2) AND deleted_at IS NULL`,
+		inspiration.PerfumeId,
+		inspiration.OriginalId,
+	).Scan(&live)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if live != 2 {
+		return fmt.Errorf("%w: perfume with public_id '%s' or '%s' not found", ErrPerfumeNotFound, inspiration.PerfumeId, inspiration.OriginalId)
+	}
+
+	err = tx.QueryRow(
+		context.Background(),
+		`
+		INSERT INTO inspirations (public_id, perfume_id, original_id, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+		`,
+		inspiration.PublicId,
+		inspiration.PerfumeId,
+		inspiration.OriginalId,
+		inspiration.CreatedBy,
+		inspiration.CreatedAt,
+	).Scan(&inspiration.ID)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("database error: %w: %w", ErrInspirationAlreadyExists, pgErr)
+		}
+
+		return fmt.Errorf("database error: insert inspiration error: %w", err)
+	}
+
+	if err := service.vote(tx, inspiration.ID, inspiration.CreatedBy, true); err != nil {
+		return err
+	}
+
+	inspiration.Agrees, inspiration.Disagrees = 1, 0
+	if err := scoreInspirations(tx, []*internal.Inspiration{inspiration}); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
+
+func (service InspirationService) Find(publicId string) (*internal.Inspiration, error) {
+	inspirations, err := service.query(fmt.Sprintf(inspirationSelectQuery, "WHERE i.public_id = $1"), publicId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(inspirations) == 0 {
+		return nil, fmt.Errorf("%w: inspiration with public_id '%s' not found", ErrInspirationNotFound, publicId)
+	}
+
+	return &inspirations[0], nil
+}
+
+func (service InspirationService) Delete(inspiration *internal.Inspiration) error {
+	tag, err := service.db.Exec(context.Background(), `DELETE FROM inspirations WHERE id = $1`, inspiration.ID)
+	if err != nil {
+		return fmt.Errorf("database error: delete inspiration error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: inspiration with public_id '%s' not found", ErrInspirationNotFound, inspiration.PublicId)
+	}
+
+	return nil
+}
+
+// Vote records whether the user agrees with the link, replacing their
+// earlier vote.
+func (service InspirationService) Vote(inspiration *internal.Inspiration, userId int, agrees bool) error {
+	return service.vote(service.db, inspiration.ID, userId, agrees)
+}
+
+func (service InspirationService) vote(db DB, inspirationId, userId int, agrees bool) error {
+	_, err := db.Exec(
+		context.Background(),
+		`
+		INSERT INTO inspiration_votes (inspiration_id, user_id, agrees, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (inspiration_id, user_id) DO UPDATE SET agrees = EXCLUDED.agrees
+		`,
+		inspirationId,
+		userId,
+		agrees,
+		time.Now(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("database error: inspiration vote error: %w", err)
+	}
+
+	return nil
+}
+
+func (service InspirationService) RetractVote(inspiration *internal.Inspiration, userId int) error {
+	tag, err := service.db.Exec(
+		context.Background(),
+		`DELETE FROM inspiration_votes WHERE inspiration_id = $1 AND user_id = $2`,
+		inspiration.ID,
+		userId,
+	)
+
+	if err != nil {
+		return fmt.Errorf("database error: retract inspiration vote error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: inspiration with public_id '%s'", ErrInspirationVoteNotFound, inspiration.PublicId)
+	}
+
+	return nil
+}
+
+// Dupes returns the links of the perfumes imitating the original, most
+// confident first.
+func (service InspirationService) Dupes(originalId string) ([]internal.Inspiration, error) {
+	return service.query(fmt.Sprintf(inspirationSelectQuery, "WHERE i.original_id = $1"), originalId)
+}
+
+// InspiredBy returns the links of the originals the perfume imitates, most
+// confident first.
+func (service InspirationService) InspiredBy(perfumeId string) ([]internal.Inspiration, error) {
+	return service.query(fmt.Sprintf(inspirationSelectQuery, "WHERE i.perfume_id = $1"), perfumeId)
+}
+
+// query runs an inspiration select query, scores the returned links and
+// sorts them by confidence.
+func (service InspirationService) query(q string, args ...any) ([]internal.Inspiration, error) {
+	rows, err := service.db.Query(context.Background(), q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	defer rows.Close()
+
+	inspirations := make([]internal.Inspiration, 0)
+	for rows.Next() {
+		var inspiration internal.Inspiration
+		if err := rows.Scan(
+			&inspiration.ID,
+			&inspiration.PublicId,
+			&inspiration.PerfumeId,
+			&inspiration.OriginalId,
+			&inspiration.CreatedBy,
+			&inspiration.CreatedAt,
+			&inspiration.Agrees,
+			&inspiration.Disagrees,
+		); err != nil {
+			return nil, err
+		}
+
+		inspirations = append(inspirations, inspiration)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Scoring queries the same connection when running in a transaction.
+	rows.Close()
+
+	scored := make([]*internal.Inspiration, 0, len(inspirations))
+	for i := range inspirations {
+		scored = append(scored, &inspirations[i])
+	}
+
+	if err := scoreInspirations(service.db, scored); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(inspirations, func(i, j int) bool {
+		if inspirations[i].Confidence != inspirations[j].Confidence {
+			return inspirations[i].Confidence > inspirations[j].Confidence
+		}
+
+		return inspirations[i].ID < inspirations[j].ID
+	})
+
+	return inspirations, nil
+}
+
+// scoreInspirations sets the note overlap and confidence of the links from
+// the notes of their perfumes and their vote counts.
+func scoreInspirations(db DB, inspirations []*internal.Inspiration) error {
+	if len(inspirations) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(inspirations)*2)
+	for _, inspiration := range inspirations {
+		ids = append(ids, inspiration.PerfumeId, inspiration.OriginalId)
+	}
+
+	perfumes, err := similarityPerfumes(db, ids)
+	if err != nil {
+		return fmt.Errorf("load perfume notes error: %w", err)
+	}
+
+	byId := make(map[string]similarity.Perfume, len(perfumes))
+	for _, perfume := range perfumes {
+		byId[perfume.ID] = perfume
+	}
+
+	for _, inspiration := range inspirations {
+		overlap := similarity.Score(byId[inspiration.OriginalId], byId[inspiration.PerfumeId], similarity.DefaultWeights)
+		confidence := internal.InspirationConfidence(inspiration.Agrees, inspiration.Disagrees, overlap)
+
+		inspiration.NoteOverlap = math.Round(overlap*1000) / 1000
+		inspiration.Confidence = math.Round(confidence*1000) / 1000
+	}
+
+	return nil
+}
@@ -8,26 +8,55 @@ import (
 	"time"
 
 	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type NoteService struct {
-	db *pgxpool.Pool
+	db DB
 }
 
 var (
 	ErrNoteAlreadyExists = fmt.Errorf("note already exists")
 	ErrNoteGroupNotFound = fmt.Errorf("note group not found")
 	ErrNoteNotFound      = fmt.Errorf("note not found")
+	ErrNoteInUse         = fmt.Errorf("note is referenced by a perfume")
 )
 
+var noteColumns = []string{
+	"id",
+	"public_id",
+	"slug",
+	"name",
+	"description",
+	"image_url",
+	"note_group_id",
+	"created_at",
+	"updated_at",
+	"version",
+}
+
+func noteFields(note *internal.Note) []any {
+	return []any{
+		&note.ID,
+		&note.PublicId,
+		&note.Slug,
+		&note.Name,
+		&note.Description,
+		&note.ImageURL,
+		&note.NoteGroupId,
+		&note.CreatedAt,
+		&note.UpdatedAt,
+		&note.Version,
+	}
+}
+
 func (service NoteService) List(cursor, perPage int) ([]internal.Note, error) {
 	if cursor <= 0 {
 		cursor = 0
 	}
 
-	q := `SELECT * FROM notes WHERE id > $1 ORDER BY id LIMIT $2`
+	q := fmt.Sprintf(`SELECT %s FROM notes WHERE id > $1 AND deleted_at IS NULL ORDER BY id LIMIT $2`, columns("", noteColumns))
 	rows, err := service.db.Query(context.Background(), q, cursor, perPage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
@@ -38,17 +67,7 @@ func (service NoteService) List(cursor, perPage int) ([]internal.Note, error) {
 
 	for rows.Next() {
 		var note internal.Note
-		if err := rows.Scan(
-			&note.ID,
-			&note.PublicId,
-			&note.Slug,
-			&note.Name,
-			&note.Description,
-			&note.ImageURL,
-			&note.NoteGroupId,
-			&note.CreatedAt,
-			&note.UpdatedAt,
-		); err != nil {
+		if err := rows.Scan(noteFields(&note)...); err != nil {
 			return nil, err
 		}
 
@@ -62,20 +81,37 @@ func (service NoteService) List(cursor, perPage int) ([]internal.Note, error) {
 	return notes, nil
 }
 
-func (service NoteService) Save(note *internal.Note) error {
+func (service NoteService) Save(note *internal.Note, meta internal.ChangeMeta) error {
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
 	if note.ID == 0 {
-		return service.saveNewNote(note)
+		err = service.saveNewNote(tx, note)
+	} else {
+		err = service.updateNote(tx, note)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := recordRevision(tx, internal.NoteEntity, note.PublicId, note, meta); err != nil {
+		return err
 	}
 
-	return service.updateNote(note)
+	return tx.Commit(context.Background())
 }
 
-func (service NoteService) saveNewNote(note *internal.Note) error {
+func (service NoteService) saveNewNote(tx pgx.Tx, note *internal.Note) error {
 	q := `
 		INSERT INTO notes (public_id, slug, name, description, image_url, note_group_id, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, version
 	`
-	_, err := service.db.Exec(
+	err := tx.QueryRow(
 		context.Background(),
 		q,
 		note.PublicId,
@@ -86,7 +122,7 @@ func (service NoteService) saveNewNote(note *internal.Note) error {
 		note.NoteGroupId,
 		note.CreatedAt,
 		note.UpdatedAt,
-	)
+	).Scan(&note.ID, &note.Version)
 
 	if err == nil {
 		return nil
@@ -108,52 +144,62 @@ func (service NoteService) saveNewNote(note *internal.Note) error {
 	}
 }
 
-func (service NoteService) updateNote(note *internal.Note) error {
+func (service NoteService) updateNote(tx pgx.Tx, note *internal.Note) error {
 	q := `
 		UPDATE notes 
 		SET slug = $2,
 		    name = $3,
 		    description = $4,
-		    note_group_id = $5,
-		    updated_at = $6
-		WHERE id = $1
+		    image_url = $5,
+		    note_group_id = $6,
+		    updated_at = $7,
+		    version = version + 1
+		WHERE id = $1 AND version = $8
 	`
 
 	note.UpdatedAt = time.Now()
-	_, err := service.db.Exec(context.Background(),
+	tag, err := tx.Exec(context.Background(),
 		q,
 		note.ID,
 		note.Slug,
 		note.Name,
 		note.Description,
+		note.ImageURL,
 		note.NoteGroupId,
 		note.UpdatedAt,
+		note.Version,
 	)
 
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return fmt.Errorf("database error: %w: %w", ErrNoteAlreadyExists, pgErr)
+			case "23503":
+				return fmt.Errorf("database error: %w: %w", ErrNoteGroupNotFound, pgErr)
+			}
+		}
+
 		return fmt.Errorf("database error: update note error: %w", err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: note with public_id '%s'", ErrStaleVersion, note.PublicId)
+	}
+
+	note.Version++
+
 	return nil
 }
 
 func (service NoteService) Find(publicId string) (*internal.Note, error) {
 	var note internal.Note
 
-	q := `SELECT * FROM notes WHERE public_id = $1`
+	q := fmt.Sprintf(`SELECT %s FROM notes WHERE public_id = $1 AND deleted_at IS NULL`, columns("", noteColumns))
 
 	if err := service.db.QueryRow(context.Background(), q, publicId).
-		Scan(
-			&note.ID,
-			&note.PublicId,
-			&note.Slug,
-			&note.Name,
-			&note.Description,
-			&note.ImageURL,
-			&note.NoteGroupId,
-			&note.CreatedAt,
-			&note.UpdatedAt,
-		); err != nil {
+		Scan(noteFields(&note)...); err != nil {
 		return nil, err
 	}
 
@@ -163,20 +209,10 @@ func (service NoteService) Find(publicId string) (*internal.Note, error) {
 func (service NoteService) FindBySlug(s string) (*internal.Note, error) {
 	var note internal.Note
 
-	q := `SELECT * FROM notes WHERE slug = $1`
+	q := fmt.Sprintf(`SELECT %s FROM notes WHERE slug = $1 AND deleted_at IS NULL`, columns("", noteColumns))
 
 	if err := service.db.QueryRow(context.Background(), q, s).
-		Scan(
-			&note.ID,
-			&note.PublicId,
-			&note.Slug,
-			&note.Name,
-			&note.Description,
-			&note.ImageURL,
-			&note.NoteGroupId,
-			&note.CreatedAt,
-			&note.UpdatedAt,
-		); err != nil {
+		Scan(noteFields(&note)...); err != nil {
 		return nil, err
 	}
 
@@ -196,7 +232,7 @@ func (service NoteService) FindMany(publicIds []string) ([]*internal.Note, error
 		placeholders[i] = fmt.Sprintf("$%d", i+1)
 		args[i] = id
 	}
-	q := fmt.Sprintf("SELECT * FROM notes WHERE public_id IN (%s)", strings.Join(placeholders, ", "))
+	q := fmt.Sprintf("SELECT %s FROM notes WHERE public_id IN (%s) AND deleted_at IS NULL", columns("", noteColumns), strings.Join(placeholders, ", "))
 
 	rows, err := service.db.Query(context.Background(), q, args...)
 	if err != nil {
@@ -211,17 +247,7 @@ func (service NoteService) FindMany(publicIds []string) ([]*internal.Note, error
 
 	for rows.Next() {
 		var note internal.Note
-		if err := rows.Scan(
-			&note.ID,
-			&note.PublicId,
-			&note.Slug,
-			&note.Name,
-			&note.Description,
-			&note.ImageURL,
-			&note.NoteGroupId,
-			&note.CreatedAt,
-			&note.UpdatedAt,
-		); err != nil {
+		if err := rows.Scan(noteFields(&note)...); err != nil {
 			return nil, err
 		}
 
@@ -240,3 +266,25 @@ func (service NoteService) FindMany(publicIds []string) ([]*internal.Note, error
 
 	return notes, nil
 }
+
+func (service NoteService) Delete(publicId string) error {
+	return softDelete(service.db, "notes", publicId, ErrNoteNotFound, guard{
+		query: `
+			SELECT 1 FROM perfumes_notes pn
+			JOIN perfumes p ON pn.perfume_id = p.public_id
+			WHERE pn.note_id = $1 AND p.deleted_at IS NULL
+		`,
+		err: ErrNoteInUse,
+	})
+}
+
+func (service NoteService) Restore(publicId string) error {
+	return restore(service.db, "notes", publicId, ErrNoteNotFound, ErrNoteAlreadyExists, guard{
+		query: `
+			SELECT 1 FROM notes n
+			JOIN note_groups ng ON n.note_group_id = ng.public_id
+			WHERE n.public_id = $1 AND ng.deleted_at IS NOT NULL
+		`,
+		err: ErrNoteGroupNotFound,
+	})
+}
@@ -7,18 +7,47 @@ import (
 	"time"
 
 	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type NoteGroupService struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-var ErrNoteGroupAlreadyExists = fmt.Errorf("note group already exists")
+var (
+	ErrNoteGroupAlreadyExists = fmt.Errorf("note group already exists")
+	ErrNoteGroupInUse         = fmt.Errorf("note group is referenced by a note")
+)
+
+var noteGroupColumns = []string{
+	"id",
+	"public_id",
+	"slug",
+	"name",
+	"description",
+	"image_url",
+	"created_at",
+	"updated_at",
+	"version",
+}
+
+func noteGroupFields(noteGroup *internal.NoteGroup) []any {
+	return []any{
+		&noteGroup.ID,
+		&noteGroup.PublicId,
+		&noteGroup.Slug,
+		&noteGroup.Name,
+		&noteGroup.Description,
+		&noteGroup.ImageURL,
+		&noteGroup.CreatedAt,
+		&noteGroup.UpdatedAt,
+		&noteGroup.Version,
+	}
+}
 
 func (service NoteGroupService) List(cursor, perPage int) ([]internal.NoteGroup, error) {
-	q := `SELECT * FROM note_groups WHERE id > $1 ORDER BY id LIMIT $2`
+	q := fmt.Sprintf(`SELECT %s FROM note_groups WHERE id > $1 AND deleted_at IS NULL ORDER BY id LIMIT $2`, columns("", noteGroupColumns))
 	if cursor <= 0 {
 		cursor = 0
 	}
@@ -33,16 +62,7 @@ func (service NoteGroupService) List(cursor, perPage int) ([]internal.NoteGroup,
 
 	for rows.Next() {
 		var note internal.NoteGroup
-		err := rows.Scan(
-			&note.ID,
-			&note.PublicId,
-			&note.Slug,
-			&note.Name,
-			&note.Description,
-			&note.ImageURL,
-			&note.CreatedAt,
-			&note.UpdatedAt,
-		)
+		err := rows.Scan(noteGroupFields(&note)...)
 		if err != nil {
 			return nil, err
 		}
@@ -56,20 +76,37 @@ func (service NoteGroupService) List(cursor, perPage int) ([]internal.NoteGroup,
 	return noteGroups, nil
 }
 
-func (service NoteGroupService) Save(note *internal.NoteGroup) error {
-	if note.ID == 0 {
-		return service.saveNewNoteGroup(note)
+func (service NoteGroupService) Save(noteGroup *internal.NoteGroup, meta internal.ChangeMeta) error {
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	if noteGroup.ID == 0 {
+		err = service.saveNewNoteGroup(tx, noteGroup)
+	} else {
+		err = service.updateNoteGroup(tx, noteGroup)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := recordRevision(tx, internal.NoteGroupEntity, noteGroup.PublicId, noteGroup, meta); err != nil {
+		return err
 	}
 
-	return service.updateNoteGroup(note)
+	return tx.Commit(context.Background())
 }
 
-func (service NoteGroupService) saveNewNoteGroup(noteGroup *internal.NoteGroup) error {
+func (service NoteGroupService) saveNewNoteGroup(tx pgx.Tx, noteGroup *internal.NoteGroup) error {
 	q := `
 		INSERT INTO note_groups (public_id, slug, name, description, image_url, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, version
 	`
-	_, err := service.db.Exec(
+	err := tx.QueryRow(
 		context.Background(),
 		q,
 		noteGroup.PublicId,
@@ -79,7 +116,7 @@ func (service NoteGroupService) saveNewNoteGroup(noteGroup *internal.NoteGroup)
 		noteGroup.ImageURL,
 		noteGroup.CreatedAt,
 		noteGroup.UpdatedAt,
-	)
+	).Scan(&noteGroup.ID, &noteGroup.Version)
 
 	if err == nil {
 		return nil
@@ -98,19 +135,20 @@ func (service NoteGroupService) saveNewNoteGroup(noteGroup *internal.NoteGroup)
 	return err
 }
 
-func (service NoteGroupService) updateNoteGroup(noteGroup *internal.NoteGroup) error {
+func (service NoteGroupService) updateNoteGroup(tx pgx.Tx, noteGroup *internal.NoteGroup) error {
 	q := `
 		UPDATE note_groups 
 		SET slug = $2,
 		    name = $3,
 		    description = $4,
 		    image_url = $5,
-		    updated_at = $6
-		WHERE id = $1
+		    updated_at = $6,
+		    version = version + 1
+		WHERE id = $1 AND version = $7
 	`
 
 	noteGroup.UpdatedAt = time.Now()
-	_, err := service.db.Exec(context.Background(),
+	tag, err := tx.Exec(context.Background(),
 		q,
 		noteGroup.ID,
 		noteGroup.Slug,
@@ -118,31 +156,34 @@ func (service NoteGroupService) updateNoteGroup(noteGroup *internal.NoteGroup) e
 		noteGroup.Description,
 		noteGroup.ImageURL,
 		noteGroup.UpdatedAt,
+		noteGroup.Version,
 	)
 
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("database error: %w: %w", ErrNoteGroupAlreadyExists, pgErr)
+		}
+
 		return fmt.Errorf("database error: update note group error: %w", err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: note group with public_id '%s'", ErrStaleVersion, noteGroup.PublicId)
+	}
+
+	noteGroup.Version++
+
 	return nil
 }
 
 func (service NoteGroupService) Find(publicId string) (*internal.NoteGroup, error) {
 	var noteGroup internal.NoteGroup
 
-	q := `SELECT * FROM note_groups WHERE public_id = $1`
+	q := fmt.Sprintf(`SELECT %s FROM note_groups WHERE public_id = $1 AND deleted_at IS NULL`, columns("", noteGroupColumns))
 
 	if err := service.db.QueryRow(context.Background(), q, publicId).
-		Scan(
-			&noteGroup.ID,
-			&noteGroup.PublicId,
-			&noteGroup.Name,
-			&noteGroup.Slug,
-			&noteGroup.Description,
-			&noteGroup.ImageURL,
-			&noteGroup.CreatedAt,
-			&noteGroup.UpdatedAt,
-		); err != nil {
+		Scan(noteGroupFields(&noteGroup)...); err != nil {
 		return nil, err
 	}
 
@@ -152,21 +193,23 @@ func (service NoteGroupService) Find(publicId string) (*internal.NoteGroup, erro
 func (service NoteGroupService) FindBySlug(s string) (*internal.NoteGroup, error) {
 	var noteGroup internal.NoteGroup
 
-	q := `SELECT * FROM note_groups WHERE slug = $1`
+	q := fmt.Sprintf(`SELECT %s FROM note_groups WHERE slug = $1 AND deleted_at IS NULL`, columns("", noteGroupColumns))
 
 	if err := service.db.QueryRow(context.Background(), q, s).
-		Scan(
-			&noteGroup.ID,
-			&noteGroup.PublicId,
-			&noteGroup.Name,
-			&noteGroup.Slug,
-			&noteGroup.Description,
-			&noteGroup.ImageURL,
-			&noteGroup.CreatedAt,
-			&noteGroup.UpdatedAt,
-		); err != nil {
+		Scan(noteGroupFields(&noteGroup)...); err != nil {
 		return nil, err
 	}
 
 	return &noteGroup, nil
 }
+
+func (service NoteGroupService) Delete(publicId string) error {
+	return softDelete(service.db, "note_groups", publicId, ErrNoteGroupNotFound, guard{
+		query: `SELECT 1 FROM notes WHERE note_group_id = $1 AND deleted_at IS NULL`,
+		err:   ErrNoteGroupInUse,
+	})
+}
+
+func (service NoteGroupService) Restore(publicId string) error {
+	return restore(service.db, "note_groups", publicId, ErrNoteGroupNotFound, ErrNoteGroupAlreadyExists)
+}
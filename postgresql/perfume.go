@@ -5,44 +5,68 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var (
 	ErrPerfumeAlreadyExists = fmt.Errorf("perfume already exists")
+	ErrPerfumeNotFound      = fmt.Errorf("perfume not found")
 )
 
 type PerfumeService struct {
-	db          *pgxpool.Pool
+	db          DB
 	noteService NoteService
 }
 
-func (service PerfumeService) Save(perfume *internal.Perfume) error {
+func (service PerfumeService) Save(perfume *internal.Perfume, meta internal.ChangeMeta) error {
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
 	if perfume.ID == 0 {
-		return service.saveNewPerfume(perfume)
+		err = service.saveNewPerfume(tx, perfume)
+	} else {
+		err = service.updatePerfume(tx, perfume)
 	}
 
-	return service.updatePerfume(perfume)
-}
+	if err != nil {
+		return err
+	}
 
-func (service PerfumeService) saveNewPerfume(perfume *internal.Perfume) error {
-	tx, err := service.db.Begin(context.Background())
+	if err := service.replaceRelations(tx, perfume); err != nil {
+		return err
+	}
 
-	if err != nil {
+	if err := classifyPerfumes(tx, []string{perfume.PublicId}); err != nil {
+		return err
+	}
+
+	if err := refreshAccordProfiles(tx, []string{perfume.PublicId}); err != nil {
+		return err
+	}
+
+	if err := recordRevision(tx, internal.PerfumeEntity, perfume.PublicId, perfume, meta); err != nil {
 		return err
 	}
-	defer tx.Rollback(context.Background())
 
-	_, err = tx.Exec(
+	return tx.Commit(context.Background())
+}
+
+func (service PerfumeService) saveNewPerfume(tx pgx.Tx, perfume *internal.Perfume) error {
+	err := tx.QueryRow(
 		context.Background(),
 		`
 		INSERT INTO perfumes (public_id, slug, name, description, concentration, image_url, house_id, year_released, year_discontinued, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, version
 	`,
 		perfume.PublicId,
 		perfume.Slug,
@@ -55,7 +79,7 @@ func (service PerfumeService) saveNewPerfume(perfume *internal.Perfume) error {
 		service.convertToNullIfZeroValue(perfume.YearDiscontinued),
 		perfume.CreatedAt,
 		perfume.UpdatedAt,
-	)
+	).Scan(&perfume.ID, &perfume.Version)
 
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -74,62 +98,12 @@ func (service PerfumeService) saveNewPerfume(perfume *internal.Perfume) error {
 		}
 	}
 
-	for category, notes := range perfume.Notes {
-		for _, note := range notes {
-			_, err = tx.Exec(
-				context.Background(),
-				`
-                INSERT INTO perfumes_notes (perfume_id, note_id, category)
-                VALUES ($1, $2, $3)
-                `,
-				perfume.PublicId,
-				note.PublicId,
-				category,
-			)
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	for _, perfumer := range perfume.Perfumers {
-		_, err = tx.Exec(
-			context.Background(),
-			`
-			INSERT INTO perfumes_perfumers (perfume_id, perfumer_id)
-			VALUES ($1, $2)
-			`,
-			perfume.PublicId,
-			perfumer.PublicId,
-		)
-		if err != nil {
-			return err
-		}
-	}
-
-	err = tx.Commit(context.Background())
-
-	return err
+	return nil
 }
 
-func (service PerfumeService) updatePerfume(perfume *internal.Perfume) error {
-	conn, err := service.db.Acquire(context.Background())
-
-	if err != nil {
-		return fmt.Errorf("%w: %w", ErrAcquiringConn, err)
-	}
-
-	defer conn.Release()
-
-	tx, err := conn.Begin(context.Background())
-
-	if err != nil {
-		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
-	}
-
-	defer tx.Rollback(context.Background())
-
-	_, err = tx.Exec(
+func (service PerfumeService) updatePerfume(tx pgx.Tx, perfume *internal.Perfume) error {
+	perfume.UpdatedAt = time.Now()
+	tag, err := tx.Exec(
 		context.Background(),
 		`
 		UPDATE perfumes SET
@@ -141,9 +115,9 @@ func (service PerfumeService) updatePerfume(perfume *internal.Perfume) error {
 			 house_id = $6,
 			 year_released = $7,
 			 year_discontinued = $8,
-			 created_at = $9,
-			 updated_at = $10
-		WHERE public_id = $11
+			 updated_at = $9,
+			 version = version + 1
+		WHERE public_id = $10 AND version = $11
 	`,
 		perfume.Slug,
 		perfume.Name,
@@ -153,9 +127,9 @@ func (service PerfumeService) updatePerfume(perfume *internal.Perfume) error {
 		perfume.House.PublicId,
 		perfume.YearReleased,
 		service.convertToNullIfZeroValue(perfume.YearDiscontinued),
-		perfume.CreatedAt,
 		perfume.UpdatedAt,
 		perfume.PublicId,
+		perfume.Version,
 	)
 
 	if err != nil {
@@ -175,76 +149,46 @@ func (service PerfumeService) updatePerfume(perfume *internal.Perfume) error {
 		}
 	}
 
-	for category, notes := range perfume.Notes {
-		placeholders := make([]string, len(notes))
-		args := make([]interface{}, len(notes))
-		for i, note := range notes {
-			placeholders[i] = fmt.Sprintf("$%d", i+1)
-			args[i] = note.PublicId
-		}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: perfume with public_id '%s'", ErrStaleVersion, perfume.PublicId)
+	}
 
-		var q string
-		if len(args) > 0 {
-			q = fmt.Sprintf(
-				`DELETE FROM perfumes_notes WHERE note_id NOT IN (%s) AND perfume_id = $%d AND category = $%d`,
-				strings.Join(placeholders, ", "),
-				len(args)+1,
-				len(args)+2,
-			)
+	perfume.Version++
 
-			args = append(args, perfume.PublicId, category)
-		} else {
-			q = `DELETE FROM perfumes_notes WHERE perfume_id = $1 AND category = $2`
-			args = append(args, perfume.PublicId, category)
-		}
+	return nil
+}
 
-		if _, err := tx.Exec(context.Background(), q, args...); err != nil {
-			return fmt.Errorf("update perfume error: delete from perfume_notes query error: %w", err)
-		}
+// replaceRelations makes the perfumes_notes and perfumes_perfumers rows of the
+// perfume match its Notes and Perfumers exactly.
+func (service PerfumeService) replaceRelations(tx pgx.Tx, perfume *internal.Perfume) error {
+	if _, err := tx.Exec(context.Background(), `DELETE FROM perfumes_notes WHERE perfume_id = $1`, perfume.PublicId); err != nil {
+		return fmt.Errorf("save perfume error: delete from perfumes_notes query error: %w", err)
+	}
 
+	for category, notes := range perfume.Notes {
 		for _, note := range notes {
-			_, err = tx.Exec(
+			_, err := tx.Exec(
 				context.Background(),
 				`
-	           INSERT INTO perfumes_notes (perfume_id, note_id, category)
-	           VALUES ($1, $2, $3) ON CONFLICT (perfume_id, note_id) DO NOTHING 
-	           `,
+				INSERT INTO perfumes_notes (perfume_id, note_id, category)
+				VALUES ($1, $2, $3) ON CONFLICT (perfume_id, note_id) DO NOTHING
+				`,
 				perfume.PublicId,
 				note.PublicId,
-				category,
+				category.String(),
 			)
 			if err != nil {
-				return fmt.Errorf("update perfume error: insert into perfume_notes query error: %w", err)
+				return fmt.Errorf("save perfume error: insert into perfumes_notes query error: %w", err)
 			}
 		}
 	}
 
-	placeholders := make([]string, len(perfume.Perfumers))
-	args := make([]interface{}, len(perfume.Perfumers))
-	for i, perfumer := range perfume.Perfumers {
-		placeholders[i] = fmt.Sprintf("$%d", i+1)
-		args[i] = perfumer.PublicId
-	}
-
-	var q string
-	if len(args) > 0 {
-		q = fmt.Sprintf(
-			"DELETE FROM perfumes_perfumers WHERE perfumer_id NOT IN (%s) AND perfume_id = $%d",
-			strings.Join(placeholders, ", "),
-			len(args)+1,
-		)
-		args = append(args, perfume.PublicId)
-	} else {
-		q = `DELETE FROM perfumes_perfumers WHERE perfume_id = $1`
-		args = append(args, perfume.PublicId)
-	}
-	fmt.Println(q, args)
-	if _, err := service.db.Exec(context.Background(), q, args...); err != nil {
-		return fmt.Errorf("update perfume error: delete from perfume_perfumers query error: %w", err)
+	if _, err := tx.Exec(context.Background(), `DELETE FROM perfumes_perfumers WHERE perfume_id = $1`, perfume.PublicId); err != nil {
+		return fmt.Errorf("save perfume error: delete from perfumes_perfumers query error: %w", err)
 	}
 
 	for _, perfumer := range perfume.Perfumers {
-		_, err = tx.Exec(
+		_, err := tx.Exec(
 			context.Background(),
 			`
 			INSERT INTO perfumes_perfumers (perfume_id, perfumer_id)
@@ -254,13 +198,11 @@ func (service PerfumeService) updatePerfume(perfume *internal.Perfume) error {
 			perfumer.PublicId,
 		)
 		if err != nil {
-			return fmt.Errorf("update perfume error: insert into perfume_perfumers query error: %w", err)
+			return fmt.Errorf("save perfume error: insert into perfumes_perfumers query error: %w", err)
 		}
 	}
 
-	err = tx.Commit(context.Background())
-
-	return err
+	return nil
 }
 
 func (service PerfumeService) convertToNullIfZeroValue(t time.Time) sql.NullTime {
@@ -271,13 +213,7 @@ func (service PerfumeService) convertToNullIfZeroValue(t time.Time) sql.NullTime
 	return sql.NullTime{Time: t, Valid: true}
 }
 
-func (service PerfumeService) Find(publicId string) (*internal.Perfume, error) {
-	var perfume internal.Perfume
-	perfume.House = &internal.House{}
-
-	var yearDiscontinued sql.NullTime
-
-	perfumeQuery := `
+const perfumeSelectQuery = `
         SELECT p.id, 
                p.public_id, 
                p.slug, 
@@ -289,188 +225,220 @@ func (service PerfumeService) Find(publicId string) (*internal.Perfume, error) {
                p.year_discontinued, 
                p.created_at, 
                p.updated_at,
-			   p.house_id,
-               h.slug AS house_slug,
-               h.name AS house_name,
-               h.country AS house_country,
-               h.description AS house_description,
-               h.year_founded AS house_year_founded,
-               h.created_at AS house_created_at,
-               h.updated_at AS house_updated_at
+               p.version,
+               p.house_id,
+               p.primary_family_id,
+               p.secondary_family_id,
+               p.family_overridden
         FROM perfumes p
-        LEFT JOIN houses h ON p.house_id = h.public_id
-        WHERE p.public_id = $1
-	`
+`
 
-	row := service.db.QueryRow(context.Background(), perfumeQuery, publicId)
-	err := row.Scan(
-		&perfume.ID,
-		&perfume.PublicId,
-		&perfume.Slug,
-		&perfume.Name,
-		&perfume.Description,
-		&perfume.Concentration,
-		&perfume.ImageURL,
-		&perfume.YearReleased,
-		&yearDiscontinued,
-		&perfume.CreatedAt,
-		&perfume.UpdatedAt,
-		&perfume.House.PublicId,
-		&perfume.House.Slug,
-		&perfume.House.Name,
-		&perfume.House.Country,
-		&perfume.House.Description,
-		&perfume.House.YearFounded,
-		&perfume.House.CreatedAt,
-		&perfume.House.UpdatedAt,
+func (service PerfumeService) List(cursor, perPage int, filter internal.PerfumeFilter) ([]*internal.Perfume, error) {
+	if cursor <= 0 {
+		cursor = 0
+	}
+
+	conditions := []string{"p.id > $1", "p.deleted_at IS NULL"}
+	args := []interface{}{cursor}
+
+	placeholder := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.HouseId != "" {
+		conditions = append(conditions, "p.house_id = "+placeholder(filter.HouseId))
+	}
+
+	if filter.PerfumerId != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"p.public_id IN (SELECT perfume_id FROM perfumes_perfumers WHERE perfumer_id = %s)",
+			placeholder(filter.PerfumerId),
+		))
+	}
+
+	if len(filter.NoteIds) > 0 {
+		// A repeated note would never be matched by the distinct count.
+		noteIds := slices.Clone(filter.NoteIds)
+		slices.Sort(noteIds)
+		noteIds = slices.Compact(noteIds)
+
+		conditions = append(conditions, fmt.Sprintf(
+			`p.public_id IN (
+				SELECT perfume_id FROM perfumes_notes
+				WHERE note_id = ANY(%s)
+				GROUP BY perfume_id
+				HAVING COUNT(DISTINCT note_id) = %s
+			)`,
+			placeholder(noteIds),
+			placeholder(len(noteIds)),
+		))
+	}
+
+	if filter.Concentration != nil {
+		conditions = append(conditions, "p.concentration = "+placeholder(*filter.Concentration))
+	}
+
+	if filter.YearReleasedFrom != 0 {
+		conditions = append(conditions, "EXTRACT(YEAR FROM p.year_released) >= "+placeholder(filter.YearReleasedFrom))
+	}
+
+	if filter.YearReleasedTo != 0 {
+		conditions = append(conditions, "EXTRACT(YEAR FROM p.year_released) <= "+placeholder(filter.YearReleasedTo))
+	}
+
+	if filter.Discontinued != nil {
+		if *filter.Discontinued {
+			conditions = append(conditions, "p.year_discontinued IS NOT NULL")
+		} else {
+			conditions = append(conditions, "p.year_discontinued IS NULL")
+		}
+	}
+
+	if filter.AccordSlug != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			`p.public_id IN (
+				SELECT pa.perfume_id FROM perfume_accords pa
+				JOIN accords a ON a.public_id = pa.accord_id
+				WHERE a.slug = %s AND pa.strength >= %s
+			)`,
+			placeholder(filter.AccordSlug),
+			placeholder(filter.MinAccordStrength),
+		))
+	}
+
+	q := fmt.Sprintf(
+		"%s WHERE %s ORDER BY p.id LIMIT %s",
+		perfumeSelectQuery,
+		strings.Join(conditions, " AND "),
+		placeholder(perPage),
 	)
 
+	return service.query(q, args...)
+}
+
+func (service PerfumeService) Find(publicId string) (*internal.Perfume, error) {
+	perfumes, err := service.query(perfumeSelectQuery+" WHERE p.public_id = $1 AND p.deleted_at IS NULL", publicId)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("perfume with public_id '%s' not found", publicId)
-		}
 		return nil, err
 	}
 
-	if yearDiscontinued.Valid {
-		perfume.YearDiscontinued = yearDiscontinued.Time
+	if len(perfumes) == 0 {
+		return nil, fmt.Errorf("%w: perfume with public_id '%s' not found", ErrPerfumeNotFound, publicId)
 	}
 
-	perfumersQuery := `
-		SELECT
-			p.id,
-			p.public_id,
-			p.slug,
-			p.name,
-			p.nationality,
-			p.image_url,
-			p.birth_date,
-			p.created_at,
-			p.updated_at
-		FROM perfumes_perfumers
-				 LEFT JOIN perfumers p ON perfumes_perfumers.perfumer_id = p.public_id
-		WHERE perfume_id = $1;
-`
-	perfumerRows, err := service.db.Query(context.Background(), perfumersQuery, perfume.PublicId)
+	return perfumes[0], nil
+}
+
+func (service PerfumeService) FindBySlug(slug string) (*internal.Perfume, error) {
+	perfumes, err := service.query(perfumeSelectQuery+" WHERE p.slug = $1 AND p.deleted_at IS NULL", slug)
 	if err != nil {
 		return nil, err
 	}
-	defer perfumerRows.Close()
-
-	for perfumerRows.Next() {
-		var perfumer internal.Perfumer
-		err := perfumerRows.Scan(
-			&perfumer.ID,
-			&perfumer.PublicId,
-			&perfumer.Slug,
-			&perfumer.Name,
-			&perfumer.Nationality,
-			&perfumer.ImageURL,
-			&perfumer.BirthDate,
-			&perfumer.CreatedAt,
-			&perfumer.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
+
+	if len(perfumes) == 0 {
+		return nil, fmt.Errorf("%w: perfume with slug '%s' not found", ErrPerfumeNotFound, slug)
+	}
+
+	return perfumes[0], nil
+}
+
+// FindMany returns the perfumes in the same order as the given public IDs.
+func (service PerfumeService) FindMany(publicIds []string) ([]*internal.Perfume, error) {
+	if len(publicIds) == 0 {
+		return make([]*internal.Perfume, 0), nil
+	}
+
+	found, err := service.byPublicId(publicIds)
+	if err != nil {
+		return nil, err
+	}
+
+	perfumes := make([]*internal.Perfume, 0, len(publicIds))
+	for _, id := range publicIds {
+		perfume, ok := found[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: perfume with public_id '%s' not found", ErrPerfumeNotFound, id)
 		}
 
-		perfume.Perfumers = append(perfume.Perfumers, &perfumer)
-	}
-
-	notesQuery := `
-		SELECT
-		   category,
-		   n.id,
-		   n.public_id,
-		   n.slug,
-		   n.name,
-		   n.description,
-		   n.image_url,
-		   n.note_group_id
-		FROM perfumes_notes
-				 LEFT JOIN notes n ON perfumes_notes.note_id = n.public_id
-		WHERE perfume_id = $1;
-`
-	noteRows, err := service.db.Query(context.Background(), notesQuery, perfume.PublicId)
+		perfumes = append(perfumes, perfume)
+	}
+
+	return perfumes, nil
+}
+
+// FindAvailable returns the perfumes in the same order as the given public
+// IDs, skipping the ones that no longer exist.
+func (service PerfumeService) FindAvailable(publicIds []string) ([]*internal.Perfume, error) {
+	found, err := service.byPublicId(publicIds)
 	if err != nil {
 		return nil, err
 	}
-	defer noteRows.Close()
-
-	// Initialize the map to store notes
-	perfume.Notes = make(map[internal.NoteCategory][]*internal.Note)
-
-	// Process the notes
-	for noteRows.Next() {
-		var note internal.Note
-		var category string
-		err := noteRows.Scan(
-			&category,
-			&note.ID,
-			&note.PublicId,
-			&note.Slug,
-			&note.Name,
-			&note.Description,
-			&note.ImageURL,
-			&note.NoteGroupId,
-		)
-		if err != nil {
-			return nil, err
+
+	perfumes := make([]*internal.Perfume, 0, len(found))
+	for _, id := range publicIds {
+		if perfume, ok := found[id]; ok {
+			perfumes = append(perfumes, perfume)
 		}
+	}
+
+	return perfumes, nil
+}
+
+// byPublicId returns the live perfumes among the given public IDs, keyed by
+// public ID. Unlike FindMany, missing perfumes are silently left out.
+func (service PerfumeService) byPublicId(publicIds []string) (map[string]*internal.Perfume, error) {
+	perfumes, err := service.query(perfumeSelectQuery+" WHERE p.public_id = ANY($1) AND p.deleted_at IS NULL", publicIds)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]*internal.Perfume, len(perfumes))
+	for _, perfume := range perfumes {
+		found[perfume.PublicId] = perfume
+	}
 
-		noteCategory, err := internal.NoteCategoryFromString(category)
+	return found, nil
+}
+
+// query runs a perfume select query and hydrates the relations of every
+// returned row in a constant number of round trips.
+func (service PerfumeService) query(q string, args ...interface{}) ([]*internal.Perfume, error) {
+	rows, err := service.db.Query(context.Background(), q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
 
+	perfumes := make([]*internal.Perfume, 0)
+	for rows.Next() {
+		perfume, err := service.scanPerfume(rows)
 		if err != nil {
-			return nil, fmt.Errorf("error: invalid note category '%s': %w", category, err)
+			return nil, err
 		}
 
-		// Map the note to the appropriate category
-		perfume.Notes[noteCategory] = append(perfume.Notes[noteCategory], &note)
+		perfumes = append(perfumes, perfume)
 	}
 
-	return &perfume, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := (perfumeRelationLoader{db: service.db}).Load(perfumes); err != nil {
+		return nil, err
+	}
+
+	return perfumes, nil
 }
 
-func (service PerfumeService) FindBySlug(slug string) (*internal.Perfume, error) {
+func (service PerfumeService) scanPerfume(row pgx.Row) (*internal.Perfume, error) {
 	var perfume internal.Perfume
 	perfume.House = &internal.House{}
 
 	var yearDiscontinued sql.NullTime
+	var primaryFamilyId, secondaryFamilyId *string
 
-	perfumeQuery := `
-        SELECT p.id, 
-               p.public_id, 
-               p.slug, 
-               p.name, 
-               p.description, 
-               p.concentration, 
-               p.image_url, 
-               p.year_released, 
-               p.year_discontinued, 
-               p.created_at, 
-               p.updated_at,
-			   p.house_id,
-               h.slug AS house_slug,
-               h.name AS house_name,
-               h.country AS house_country,
-               h.description AS house_description,
-               h.year_founded AS house_year_founded,
-               h.created_at AS house_created_at,
-               h.updated_at AS house_updated_at
-        FROM perfumes p
-        LEFT JOIN houses h ON p.house_id = h.public_id
-        WHERE p.slug = $1
-	`
-
-	conn, err := service.db.Acquire(context.Background())
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrAcquiringConn, err)
-	}
-
-	defer conn.Release()
-
-	row := conn.QueryRow(context.Background(), perfumeQuery, slug)
-	err = row.Scan(
+	err := row.Scan(
 		&perfume.ID,
 		&perfume.PublicId,
 		&perfume.Slug,
@@ -482,20 +450,14 @@ func (service PerfumeService) FindBySlug(slug string) (*internal.Perfume, error)
 		&yearDiscontinued,
 		&perfume.CreatedAt,
 		&perfume.UpdatedAt,
+		&perfume.Version,
 		&perfume.House.PublicId,
-		&perfume.House.Slug,
-		&perfume.House.Name,
-		&perfume.House.Country,
-		&perfume.House.Description,
-		&perfume.House.YearFounded,
-		&perfume.House.CreatedAt,
-		&perfume.House.UpdatedAt,
+		&primaryFamilyId,
+		&secondaryFamilyId,
+		&perfume.FamilyOverridden,
 	)
 
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("perfume with slug '%s' not found", slug)
-		}
 		return nil, err
 	}
 
@@ -503,100 +465,53 @@ func (service PerfumeService) FindBySlug(slug string) (*internal.Perfume, error)
 		perfume.YearDiscontinued = yearDiscontinued.Time
 	}
 
-	perfumersQuery := `
-		SELECT
-			p.id,
-			p.public_id,
-			p.slug,
-			p.name,
-			p.nationality,
-			p.image_url,
-			p.birth_date,
-			p.created_at,
-			p.updated_at
-		FROM perfumes_perfumers
-				 LEFT JOIN perfumers p ON perfumes_perfumers.perfumer_id = p.public_id
-		WHERE perfume_id = $1;
-`
-	perfumerRows, err := service.db.Query(context.Background(), perfumersQuery, perfume.PublicId)
-	if err != nil {
-		return nil, err
-	}
-	defer perfumerRows.Close()
-
-	for perfumerRows.Next() {
-		var perfumer internal.Perfumer
-		err := perfumerRows.Scan(
-			&perfumer.ID,
-			&perfumer.PublicId,
-			&perfumer.Slug,
-			&perfumer.Name,
-			&perfumer.Nationality,
-			&perfumer.ImageURL,
-			&perfumer.BirthDate,
-			&perfumer.CreatedAt,
-			&perfumer.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		perfume.Perfumers = append(perfume.Perfumers, &perfumer)
-	}
-
-	notesQuery := `
-		SELECT
-		   category,
-		   n.id,
-		   n.public_id,
-		   n.slug,
-		   n.name,
-		   n.description,
-		   n.image_url,
-		   n.note_group_id
-		FROM perfumes_notes
-				 LEFT JOIN notes n ON perfumes_notes.note_id = n.public_id
-		WHERE perfume_id = $1;
-`
-	noteRows, err := service.db.Query(context.Background(), notesQuery, perfume.PublicId)
-	if err != nil {
-		return nil, err
+	if primaryFamilyId != nil {
+		perfume.PrimaryFamily = &internal.Family{PublicId: *primaryFamilyId}
 	}
-	defer noteRows.Close()
-
-	// Initialize the map to store notes
-	perfume.Notes = make(map[internal.NoteCategory][]*internal.Note)
-
-	// Process the notes
-	for noteRows.Next() {
-		var note internal.Note
-		var category string
-		err := noteRows.Scan(
-			&category,
-			&note.ID,
-			&note.PublicId,
-			&note.Slug,
-			&note.Name,
-			&note.Description,
-			&note.ImageURL,
-			&note.NoteGroupId,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		noteCategory, err := internal.NoteCategoryFromString(category)
-
-		if err != nil {
-			return nil, fmt.Errorf("error: invalid note category '%s': %w", category, err)
-		}
 
-		// Map the note to the appropriate category
-		perfume.Notes[noteCategory] = append(perfume.Notes[noteCategory], &note)
+	if secondaryFamilyId != nil {
+		perfume.SecondaryFamily = &internal.Family{PublicId: *secondaryFamilyId}
 	}
 
 	return &perfume, nil
 }
 
-//func (service PerfumeService) FindBySlug(s string) (*Perfume, error)           {}
-//func (service PerfumeService) FindMany(publicIds []string) ([]*Perfume, error) {}
+func (service PerfumeService) Delete(publicId string) error {
+	return softDelete(service.db, "perfumes", publicId, ErrPerfumeNotFound)
+}
+
+// Restore brings back a deleted perfume as long as everything it references
+// is still live.
+func (service PerfumeService) Restore(publicId string) error {
+	return restore(
+		service.db,
+		"perfumes",
+		publicId,
+		ErrPerfumeNotFound,
+		ErrPerfumeAlreadyExists,
+		guard{
+			query: `
+				SELECT 1 FROM perfumes p
+				JOIN houses h ON p.house_id = h.public_id
+				WHERE p.public_id = $1 AND h.deleted_at IS NOT NULL
+			`,
+			err: ErrHouseNotFound,
+		},
+		guard{
+			query: `
+				SELECT 1 FROM perfumes_perfumers pp
+				JOIN perfumers p ON pp.perfumer_id = p.public_id
+				WHERE pp.perfume_id = $1 AND p.deleted_at IS NOT NULL
+			`,
+			err: ErrPerfumerNotFound,
+		},
+		guard{
+			query: `
+				SELECT 1 FROM perfumes_notes pn
+				JOIN notes n ON pn.note_id = n.public_id
+				WHERE pn.perfume_id = $1 AND n.deleted_at IS NOT NULL
+			`,
+			err: ErrNoteNotFound,
+		},
+	)
+}
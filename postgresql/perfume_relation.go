@@ -0,0 +1,135 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ej-agas/perfume-db/internal"
+)
+
+type PerfumeRelationService struct {
+	db DB
+}
+
+var (
+	ErrPerfumeRelationNotFound = fmt.Errorf("perfume relation not found")
+	ErrPerfumeRelationSelf     = fmt.Errorf("perfume cannot be related to itself")
+	ErrPerfumeRelationCycle    = fmt.Errorf("perfume relation would create a cycle")
+	ErrPerfumeRelationExists   = fmt.Errorf("perfumes are already related with another type")
+)
+
+// Relate records the relation. Two perfumes are related at most once:
+// relating them again with the same type is a no-op, with another type an
+// ErrPerfumeRelationExists, so the existing relation is never silently
+// retyped. Relations of any type form a single directed
+// graph, which must stay free of cycles: a perfume cannot be a flanker of
+// its own reformulation.
+func (service PerfumeRelationService) Relate(relation *internal.PerfumeRelation) error {
+	if relation.PerfumeId == relation.RelatedId {
+		return fmt.Errorf("%w: perfume with public_id '%s'", ErrPerfumeRelationSelf, relation.PerfumeId)
+	}
+
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	// Two concurrent relations could each be acyclic on their own and
+	// still close a cycle together.
+	if _, err := tx.Exec(context.Background(), `LOCK TABLE perfume_relations IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("database error: lock perfume relations error: %w", err)
+	}
+
+	var live int
+	err = tx.QueryRow(
+		context.Background(),
+		`SELECT COUNT(*) FROM perfumes WHERE public_id IN ($1, $2) AND deleted_at IS NULL`,
+		relation.PerfumeId,
+		relation.RelatedId,
+	).Scan(&live)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if live != 2 {
+		return fmt.Errorf("%w: perfume with public_id '%s' or '%s' not found", ErrPerfumeNotFound, relation.PerfumeId, relation.RelatedId)
+	}
+
+	var cycle bool
+	err = tx.QueryRow(
+		context.Background(),
+		`
+		WITH RECURSIVE reachable (id) AS (
+			SELECT related_id FROM perfume_relations WHERE perfume_id = $1
+			UNION
+			SELECT r.related_id FROM perfume_relations r JOIN reachable ON r.perfume_id = reachable.id
+		)
+		SELECT EXISTS (SELECT 1 FROM reachable WHERE id = $2)
+		`,
+		relation.RelatedId,
+		relation.PerfumeId,
+	).Scan(&cycle)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if cycle {
+		return fmt.Errorf("%w: '%s' %s '%s'", ErrPerfumeRelationCycle, relation.PerfumeId, relation.Type, relation.RelatedId)
+	}
+
+	tag, err := tx.Exec(
+		context.Background(),
+		`
+		INSERT INTO perfume_relations (perfume_id, related_id, type, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (perfume_id, related_id) DO NOTHING
+		`,
+		relation.PerfumeId,
+		relation.RelatedId,
+		relation.Type.String(),
+		relation.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("database error: insert perfume relation error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		var existing string
+		err = tx.QueryRow(
+			context.Background(),
+			`SELECT type FROM perfume_relations WHERE perfume_id = $1 AND related_id = $2`,
+			relation.PerfumeId,
+			relation.RelatedId,
+		).Scan(&existing)
+		if err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+
+		if existing != relation.Type.String() {
+			return fmt.Errorf("%w: '%s' %s '%s'", ErrPerfumeRelationExists, relation.PerfumeId, existing, relation.RelatedId)
+		}
+	}
+
+	return tx.Commit(context.Background())
+}
+
+func (service PerfumeRelationService) Unrelate(relation *internal.PerfumeRelation) error {
+	tag, err := service.db.Exec(
+		context.Background(),
+		`DELETE FROM perfume_relations WHERE perfume_id = $1 AND related_id = $2 AND type = $3`,
+		relation.PerfumeId,
+		relation.RelatedId,
+		relation.Type.String(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("database error: delete perfume relation error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: '%s' %s '%s'", ErrPerfumeRelationNotFound, relation.PerfumeId, relation.Type, relation.RelatedId)
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPerfumeRelationService_Relate_Self(t *testing.T) {
+	relation := &internal.PerfumeRelation{PerfumeId: "original", RelatedId: "original", Type: internal.FlankerOf}
+
+	err := PerfumeRelationService{}.Relate(relation)
+
+	assert.ErrorIs(t, err, ErrPerfumeRelationSelf, "rejected before the database is used")
+}
@@ -0,0 +1,355 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
+)
+
+// perfumeRelationLoader hydrates everything a perfume references from other
+// tables for a slice of perfumes, using a single batched round trip
+// regardless of the slice length.
+type perfumeRelationLoader struct {
+	db DB
+}
+
+func (loader perfumeRelationLoader) Load(perfumes []*internal.Perfume) error {
+	if len(perfumes) == 0 {
+		return nil
+	}
+
+	byPublicId := make(map[string]*internal.Perfume, len(perfumes))
+	publicIds := make([]string, 0, len(perfumes))
+	houseIds := make([]string, 0, len(perfumes))
+	familyIds := make([]string, 0)
+
+	for _, perfume := range perfumes {
+		byPublicId[perfume.PublicId] = perfume
+		publicIds = append(publicIds, perfume.PublicId)
+		perfume.Perfumers = make([]*internal.Perfumer, 0)
+		perfume.Notes = make(map[internal.NoteCategory][]*internal.Note)
+		perfume.Rating = internal.NewRating(0, 0, nil)
+		perfume.Votes = internal.NewVoteDistribution()
+		perfume.Accords = make([]internal.AccordStrength, 0)
+		perfume.Relations = make([]internal.RelatedPerfume, 0)
+
+		if perfume.House != nil {
+			houseIds = append(houseIds, perfume.House.PublicId)
+		}
+
+		for _, family := range []*internal.Family{perfume.PrimaryFamily, perfume.SecondaryFamily} {
+			if family != nil {
+				familyIds = append(familyIds, family.PublicId)
+			}
+		}
+	}
+
+	batch := &pgx.Batch{}
+	batch.Queue(
+		fmt.Sprintf(`SELECT %s FROM houses WHERE public_id = ANY($1)`, columns("", houseColumns)),
+		houseIds,
+	)
+	batch.Queue(
+		fmt.Sprintf(`
+			SELECT pp.perfume_id, %s
+			FROM perfumes_perfumers pp
+			JOIN perfumers p ON pp.perfumer_id = p.public_id
+			WHERE pp.perfume_id = ANY($1)
+			ORDER BY p.id
+		`, columns("p", perfumerColumns)),
+		publicIds,
+	)
+	batch.Queue(
+		fmt.Sprintf(`
+			SELECT pn.perfume_id, pn.category, %s
+			FROM perfumes_notes pn
+			JOIN notes n ON pn.note_id = n.public_id
+			WHERE pn.perfume_id = ANY($1)
+			ORDER BY n.id
+		`, columns("n", noteColumns)),
+		publicIds,
+	)
+	batch.Queue(
+		`SELECT perfume_id, count, score_sum, histogram FROM perfume_ratings WHERE perfume_id = ANY($1)`,
+		publicIds,
+	)
+	batch.Queue(
+		`
+		SELECT perfume_id, dimension, value, COUNT(*)
+		FROM perfume_votes
+		WHERE perfume_id = ANY($1)
+		GROUP BY perfume_id, dimension, value
+		`,
+		publicIds,
+	)
+	batch.Queue(
+		fmt.Sprintf(`
+			SELECT pa.perfume_id, pa.strength, %s
+			FROM perfume_accords pa
+			JOIN accords a ON pa.accord_id = a.public_id
+			WHERE pa.perfume_id = ANY($1)
+			ORDER BY pa.strength DESC, a.name
+		`, columns("a", accordColumns)),
+		publicIds,
+	)
+	batch.Queue(familySelectQuery+" WHERE f.public_id = ANY($1)", familyIds)
+	batch.Queue(
+		`
+		SELECT r.perfume_id, r.type, false, p.public_id, p.slug, p.name
+		FROM perfume_relations r
+		JOIN perfumes p ON p.public_id = r.related_id AND p.deleted_at IS NULL
+		WHERE r.perfume_id = ANY($1)
+		UNION ALL
+		SELECT r.related_id, r.type, true, p.public_id, p.slug, p.name
+		FROM perfume_relations r
+		JOIN perfumes p ON p.public_id = r.perfume_id AND p.deleted_at IS NULL
+		WHERE r.related_id = ANY($1)
+		ORDER BY 2, 3, 6
+		`,
+		publicIds,
+	)
+
+	results := loader.db.SendBatch(context.Background(), batch)
+	defer results.Close()
+
+	if err := loader.loadHouses(results, perfumes); err != nil {
+		return fmt.Errorf("load perfume houses error: %w", err)
+	}
+
+	if err := loader.loadPerfumers(results, byPublicId); err != nil {
+		return fmt.Errorf("load perfume perfumers error: %w", err)
+	}
+
+	if err := loader.loadNotes(results, byPublicId); err != nil {
+		return fmt.Errorf("load perfume notes error: %w", err)
+	}
+
+	if err := loader.loadRatings(results, byPublicId); err != nil {
+		return fmt.Errorf("load perfume ratings error: %w", err)
+	}
+
+	if err := loader.loadVotes(results, byPublicId); err != nil {
+		return fmt.Errorf("load perfume votes error: %w", err)
+	}
+
+	if err := loader.loadAccords(results, byPublicId); err != nil {
+		return fmt.Errorf("load perfume accords error: %w", err)
+	}
+
+	if err := loader.loadFamilies(results, perfumes); err != nil {
+		return fmt.Errorf("load perfume families error: %w", err)
+	}
+
+	if err := loader.loadRelations(results, byPublicId); err != nil {
+		return fmt.Errorf("load perfume relations error: %w", err)
+	}
+
+	return results.Close()
+}
+
+func (loader perfumeRelationLoader) loadHouses(results pgx.BatchResults, perfumes []*internal.Perfume) error {
+	rows, err := results.Query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	houses := make(map[string]*internal.House)
+	for rows.Next() {
+		var house internal.House
+		if err := rows.Scan(houseFields(&house)...); err != nil {
+			return err
+		}
+
+		houses[house.PublicId] = &house
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, perfume := range perfumes {
+		if perfume.House == nil {
+			continue
+		}
+
+		if house, ok := houses[perfume.House.PublicId]; ok {
+			perfume.House = house
+		}
+	}
+
+	return nil
+}
+
+func (loader perfumeRelationLoader) loadPerfumers(results pgx.BatchResults, perfumes map[string]*internal.Perfume) error {
+	rows, err := results.Query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var perfumeId string
+		var perfumer internal.Perfumer
+		if err := rows.Scan(append([]any{&perfumeId}, perfumerFields(&perfumer)...)...); err != nil {
+			return err
+		}
+
+		perfume := perfumes[perfumeId]
+		perfume.Perfumers = append(perfume.Perfumers, &perfumer)
+	}
+
+	return rows.Err()
+}
+
+func (loader perfumeRelationLoader) loadNotes(results pgx.BatchResults, perfumes map[string]*internal.Perfume) error {
+	rows, err := results.Query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var perfumeId, category string
+		var note internal.Note
+		if err := rows.Scan(append([]any{&perfumeId, &category}, noteFields(&note)...)...); err != nil {
+			return err
+		}
+
+		noteCategory, err := internal.NoteCategoryFromString(category)
+		if err != nil {
+			return fmt.Errorf("error: invalid note category '%s': %w", category, err)
+		}
+
+		perfume := perfumes[perfumeId]
+		perfume.Notes[noteCategory] = append(perfume.Notes[noteCategory], &note)
+	}
+
+	return rows.Err()
+}
+
+func (loader perfumeRelationLoader) loadRatings(results pgx.BatchResults, perfumes map[string]*internal.Perfume) error {
+	rows, err := results.Query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var perfumeId string
+		var count, sum int
+		var histogram []int
+		if err := rows.Scan(&perfumeId, &count, &sum, &histogram); err != nil {
+			return err
+		}
+
+		perfumes[perfumeId].Rating = internal.NewRating(count, sum, histogram)
+	}
+
+	return rows.Err()
+}
+
+func (loader perfumeRelationLoader) loadVotes(results pgx.BatchResults, perfumes map[string]*internal.Perfume) error {
+	rows, err := results.Query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var perfumeId, dimension, value string
+		var count int
+		if err := rows.Scan(&perfumeId, &dimension, &value, &count); err != nil {
+			return err
+		}
+
+		perfumes[perfumeId].Votes.Add(internal.VoteDimension(dimension), value, count)
+	}
+
+	return rows.Err()
+}
+
+func (loader perfumeRelationLoader) loadAccords(results pgx.BatchResults, perfumes map[string]*internal.Perfume) error {
+	rows, err := results.Query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var perfumeId string
+		var strength int
+		var accord internal.Accord
+		if err := rows.Scan(append([]any{&perfumeId, &strength}, accordFields(&accord)...)...); err != nil {
+			return err
+		}
+
+		perfume := perfumes[perfumeId]
+		perfume.Accords = append(perfume.Accords, internal.AccordStrength{Accord: &accord, Strength: strength})
+	}
+
+	return rows.Err()
+}
+
+func (loader perfumeRelationLoader) loadFamilies(results pgx.BatchResults, perfumes []*internal.Perfume) error {
+	rows, err := results.Query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	families := make(map[string]*internal.Family)
+	for rows.Next() {
+		var family internal.Family
+		if err := rows.Scan(familyFields(&family)...); err != nil {
+			return err
+		}
+
+		families[family.PublicId] = &family
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, perfume := range perfumes {
+		if perfume.PrimaryFamily != nil {
+			perfume.PrimaryFamily = families[perfume.PrimaryFamily.PublicId]
+		}
+
+		if perfume.SecondaryFamily != nil {
+			perfume.SecondaryFamily = families[perfume.SecondaryFamily.PublicId]
+		}
+	}
+
+	return nil
+}
+
+func (loader perfumeRelationLoader) loadRelations(results pgx.BatchResults, perfumes map[string]*internal.Perfume) error {
+	rows, err := results.Query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var perfumeId, relationType string
+		var inverse bool
+		var related internal.RelatedPerfume
+		if err := rows.Scan(&perfumeId, &relationType, &inverse, &related.Perfume.PublicId, &related.Perfume.Slug, &related.Perfume.Name); err != nil {
+			return err
+		}
+
+		related.Relation = relationType
+		if inverse {
+			related.Relation = internal.PerfumeRelationType(relationType).Inverse()
+		}
+
+		perfume := perfumes[perfumeId]
+		perfume.Relations = append(perfume.Relations, related)
+	}
+
+	return rows.Err()
+}
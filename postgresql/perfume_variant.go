@@ -0,0 +1,230 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type PerfumeVariantService struct {
+	db DB
+}
+
+var (
+	ErrPerfumeVariantNotFound = fmt.Errorf("perfume variant not found")
+	ErrBarcodeAlreadyExists   = fmt.Errorf("barcode belongs to another variant")
+	ErrPerfumeVariantInUse    = fmt.Errorf("perfume variant has price observations")
+)
+
+var perfumeVariantColumns = []string{
+	"id",
+	"public_id",
+	"perfume_id",
+	"type",
+	"size_ml",
+	"barcode",
+	"launched_on",
+	"discontinued_on",
+	"created_at",
+	"updated_at",
+}
+
+// perfumeVariantFields returns the scan targets of perfumeVariantColumns. The
+// type is scanned into variantType, to be converted once the row is read.
+func perfumeVariantFields(variant *internal.PerfumeVariant, variantType *string) []any {
+	return []any{
+		&variant.ID,
+		&variant.PublicId,
+		&variant.PerfumeId,
+		variantType,
+		&variant.SizeMl,
+		&variant.Barcode,
+		&variant.LaunchedOn,
+		&variant.DiscontinuedOn,
+		&variant.CreatedAt,
+		&variant.UpdatedAt,
+	}
+}
+
+func scanPerfumeVariant(row pgx.Row) (*internal.PerfumeVariant, error) {
+	var variant internal.PerfumeVariant
+	var variantType string
+
+	if err := row.Scan(perfumeVariantFields(&variant, &variantType)...); err != nil {
+		return nil, err
+	}
+
+	variant.Type = internal.VariantType(variantType)
+
+	return &variant, nil
+}
+
+// Save stores the variant along with the GTIN-14 form of its barcode, which
+// must be unique across all variants.
+func (service PerfumeVariantService) Save(variant *internal.PerfumeVariant) error {
+	var gtin any
+	if variant.Barcode != "" {
+		normalized, err := internal.NormalizeBarcode(variant.Barcode)
+		if err != nil {
+			return err
+		}
+
+		gtin = normalized
+	}
+
+	var err error
+	if variant.ID == 0 {
+		err = service.db.QueryRow(
+			context.Background(),
+			`
+			INSERT INTO perfume_variants (public_id, perfume_id, type, size_ml, barcode, gtin, launched_on, discontinued_on, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id
+			`,
+			variant.PublicId,
+			variant.PerfumeId,
+			variant.Type.String(),
+			variant.SizeMl,
+			variant.Barcode,
+			gtin,
+			variant.LaunchedOn,
+			variant.DiscontinuedOn,
+			variant.CreatedAt,
+			variant.UpdatedAt,
+		).Scan(&variant.ID)
+	} else {
+		variant.UpdatedAt = time.Now()
+		_, err = service.db.Exec(
+			context.Background(),
+			`
+			UPDATE perfume_variants
+			SET type = $2,
+			    size_ml = $3,
+			    barcode = $4,
+			    gtin = $5,
+			    launched_on = $6,
+			    discontinued_on = $7,
+			    updated_at = $8
+			WHERE id = $1
+			`,
+			variant.ID,
+			variant.Type.String(),
+			variant.SizeMl,
+			variant.Barcode,
+			gtin,
+			variant.LaunchedOn,
+			variant.DiscontinuedOn,
+			variant.UpdatedAt,
+		)
+	}
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		ok := errors.As(err, &pgErr)
+		if !ok {
+			return fmt.Errorf("save perfume variant error: %w", err)
+		}
+
+		switch pgErr.Code {
+		case "23505":
+			return fmt.Errorf("database error: %w: %w", ErrBarcodeAlreadyExists, pgErr)
+		case "23503":
+			return fmt.Errorf("database error: %w: %w", ErrPerfumeNotFound, pgErr)
+		default:
+			return fmt.Errorf("save perfume variant error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (service PerfumeVariantService) Find(publicId string) (*internal.PerfumeVariant, error) {
+	q := fmt.Sprintf(`SELECT %s FROM perfume_variants WHERE public_id = $1`, columns("", perfumeVariantColumns))
+
+	variant, err := scanPerfumeVariant(service.db.QueryRow(context.Background(), q, publicId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: perfume variant with public_id '%s' not found", ErrPerfumeVariantNotFound, publicId)
+		}
+
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+// FindByBarcode looks the variant up by any of the GTIN forms of its barcode,
+// so that a UPC-A finds the variant registered with its EAN-13.
+func (service PerfumeVariantService) FindByBarcode(code string) (*internal.PerfumeVariant, error) {
+	gtin, err := internal.NormalizeBarcode(code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPerfumeVariantNotFound, err)
+	}
+
+	q := fmt.Sprintf(`SELECT %s FROM perfume_variants WHERE gtin = $1`, columns("", perfumeVariantColumns))
+
+	variant, err := scanPerfumeVariant(service.db.QueryRow(context.Background(), q, gtin))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: perfume variant with barcode '%s' not found", ErrPerfumeVariantNotFound, code)
+		}
+
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+func (service PerfumeVariantService) List(perfumeId string) ([]internal.PerfumeVariant, error) {
+	q := fmt.Sprintf(
+		`SELECT %s FROM perfume_variants WHERE perfume_id = $1 ORDER BY type, size_ml NULLS LAST, id`,
+		columns("", perfumeVariantColumns),
+	)
+
+	rows, err := service.db.Query(context.Background(), q, perfumeId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	variants := make([]internal.PerfumeVariant, 0)
+	for rows.Next() {
+		variant, err := scanPerfumeVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, *variant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return variants, nil
+}
+
+// Delete removes a variant. Variants with price observations are kept, so
+// that deleting one never erases its price history.
+func (service PerfumeVariantService) Delete(variant *internal.PerfumeVariant) error {
+	tag, err := service.db.Exec(context.Background(), `DELETE FROM perfume_variants WHERE id = $1`, variant.ID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("database error: %w: %w", ErrPerfumeVariantInUse, pgErr)
+		}
+
+		return fmt.Errorf("delete perfume variant error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: perfume variant with public_id '%s' not found", ErrPerfumeVariantNotFound, variant.PublicId)
+	}
+
+	return nil
+}
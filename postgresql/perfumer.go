@@ -5,19 +5,49 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var (
 	ErrPerfumerAlreadyExists = fmt.Errorf("perfumer already exists")
 	ErrPerfumerNotFound      = fmt.Errorf("perfumer not found")
+	ErrPerfumerInUse         = fmt.Errorf("perfumer is referenced by a perfume")
 )
 
 type PerfumerService struct {
-	db *pgxpool.Pool
+	db DB
+}
+
+var perfumerColumns = []string{
+	"id",
+	"public_id",
+	"slug",
+	"name",
+	"nationality",
+	"image_url",
+	"birth_date",
+	"created_at",
+	"updated_at",
+	"version",
+}
+
+func perfumerFields(perfumer *internal.Perfumer) []any {
+	return []any{
+		&perfumer.ID,
+		&perfumer.PublicId,
+		&perfumer.Slug,
+		&perfumer.Name,
+		&perfumer.Nationality,
+		&perfumer.ImageURL,
+		&perfumer.BirthDate,
+		&perfumer.CreatedAt,
+		&perfumer.UpdatedAt,
+		&perfumer.Version,
+	}
 }
 
 func (service PerfumerService) List(cursor, perPage int) ([]internal.Perfumer, error) {
@@ -25,7 +55,7 @@ func (service PerfumerService) List(cursor, perPage int) ([]internal.Perfumer, e
 		cursor = 0
 	}
 
-	q := `SELECT * FROM perfumers WHERE id > $1 ORDER BY id LIMIT $2`
+	q := fmt.Sprintf(`SELECT %s FROM perfumers WHERE id > $1 AND deleted_at IS NULL ORDER BY id LIMIT $2`, columns("", perfumerColumns))
 	rows, err := service.db.Query(context.Background(), q, cursor, perPage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
@@ -36,17 +66,7 @@ func (service PerfumerService) List(cursor, perPage int) ([]internal.Perfumer, e
 
 	for rows.Next() {
 		var perfumer internal.Perfumer
-		if err := rows.Scan(
-			&perfumer.ID,
-			&perfumer.PublicId,
-			&perfumer.Slug,
-			&perfumer.Name,
-			&perfumer.Nationality,
-			&perfumer.ImageURL,
-			&perfumer.BirthDate,
-			&perfumer.CreatedAt,
-			&perfumer.UpdatedAt,
-		); err != nil {
+		if err := rows.Scan(perfumerFields(&perfumer)...); err != nil {
 			return nil, err
 		}
 
@@ -60,20 +80,37 @@ func (service PerfumerService) List(cursor, perPage int) ([]internal.Perfumer, e
 	return perfumers, nil
 }
 
-func (service PerfumerService) Save(perfumer *internal.Perfumer) error {
+func (service PerfumerService) Save(perfumer *internal.Perfumer, meta internal.ChangeMeta) error {
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
 	if perfumer.ID == 0 {
-		return service.saveNewPerfumer(perfumer)
+		err = service.saveNewPerfumer(tx, perfumer)
+	} else {
+		err = service.updatePerfumer(tx, perfumer)
+	}
+
+	if err != nil {
+		return err
 	}
 
-	return service.updatePerfumer(perfumer)
+	if err := recordRevision(tx, internal.PerfumerEntity, perfumer.PublicId, perfumer, meta); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
 }
 
-func (service PerfumerService) saveNewPerfumer(perfumer *internal.Perfumer) error {
+func (service PerfumerService) saveNewPerfumer(tx pgx.Tx, perfumer *internal.Perfumer) error {
 	q := `
 		INSERT INTO perfumers (public_id, slug, name, nationality, image_url, birth_date, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, version
 	`
-	_, err := service.db.Exec(
+	err := tx.QueryRow(
 		context.Background(),
 		q,
 		perfumer.PublicId,
@@ -84,7 +121,7 @@ func (service PerfumerService) saveNewPerfumer(perfumer *internal.Perfumer) erro
 		perfumer.BirthDate,
 		perfumer.CreatedAt,
 		perfumer.UpdatedAt,
-	)
+	).Scan(&perfumer.ID, &perfumer.Version)
 
 	if err == nil {
 		return nil
@@ -103,7 +140,7 @@ func (service PerfumerService) saveNewPerfumer(perfumer *internal.Perfumer) erro
 	return err
 }
 
-func (service PerfumerService) updatePerfumer(perfumer *internal.Perfumer) error {
+func (service PerfumerService) updatePerfumer(tx pgx.Tx, perfumer *internal.Perfumer) error {
 	q := `
 		UPDATE perfumers 
 		SET slug = $2,
@@ -111,11 +148,13 @@ func (service PerfumerService) updatePerfumer(perfumer *internal.Perfumer) error
 		    nationality = $4,
 		    image_url = $5,
 		    birth_date = $6,
-		    updated_at = $7
-		WHERE id = $1
+		    updated_at = $7,
+		    version = version + 1
+		WHERE id = $1 AND version = $8
 	`
 
-	_, err := service.db.Exec(
+	perfumer.UpdatedAt = time.Now()
+	tag, err := tx.Exec(
 		context.Background(),
 		q,
 		perfumer.ID,
@@ -125,32 +164,34 @@ func (service PerfumerService) updatePerfumer(perfumer *internal.Perfumer) error
 		perfumer.ImageURL,
 		perfumer.BirthDate,
 		perfumer.UpdatedAt,
+		perfumer.Version,
 	)
 
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("database error: %w: %w", ErrPerfumerAlreadyExists, pgErr)
+		}
+
 		return fmt.Errorf("update perfumer error: %w", err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: perfumer with public_id '%s'", ErrStaleVersion, perfumer.PublicId)
+	}
+
+	perfumer.Version++
+
 	return nil
 }
 
 func (service PerfumerService) Find(publicId string) (*internal.Perfumer, error) {
 	var perfumer internal.Perfumer
 
-	q := `SELECT * FROM perfumers WHERE public_id = $1`
+	q := fmt.Sprintf(`SELECT %s FROM perfumers WHERE public_id = $1 AND deleted_at IS NULL`, columns("", perfumerColumns))
 
 	if err := service.db.QueryRow(context.Background(), q, publicId).
-		Scan(
-			&perfumer.ID,
-			&perfumer.PublicId,
-			&perfumer.Slug,
-			&perfumer.Name,
-			&perfumer.Nationality,
-			&perfumer.ImageURL,
-			&perfumer.BirthDate,
-			&perfumer.CreatedAt,
-			&perfumer.UpdatedAt,
-		); err != nil {
+		Scan(perfumerFields(&perfumer)...); err != nil {
 		return nil, err
 	}
 
@@ -160,20 +201,10 @@ func (service PerfumerService) Find(publicId string) (*internal.Perfumer, error)
 func (service PerfumerService) FindBySlug(s string) (*internal.Perfumer, error) {
 	var perfumer internal.Perfumer
 
-	q := `SELECT * FROM perfumers WHERE slug = $1`
+	q := fmt.Sprintf(`SELECT %s FROM perfumers WHERE slug = $1 AND deleted_at IS NULL`, columns("", perfumerColumns))
 
 	if err := service.db.QueryRow(context.Background(), q, s).
-		Scan(
-			&perfumer.ID,
-			&perfumer.PublicId,
-			&perfumer.Slug,
-			&perfumer.Name,
-			&perfumer.Nationality,
-			&perfumer.ImageURL,
-			&perfumer.BirthDate,
-			&perfumer.CreatedAt,
-			&perfumer.UpdatedAt,
-		); err != nil {
+		Scan(perfumerFields(&perfumer)...); err != nil {
 		return nil, err
 	}
 
@@ -189,7 +220,7 @@ func (service PerfumerService) FindMany(publicIds ...string) ([]*internal.Perfum
 		placeholders[i] = fmt.Sprintf("$%d", i+1)
 		args[i] = id
 	}
-	q := fmt.Sprintf("SELECT * FROM perfumers WHERE public_id IN (%s)", strings.Join(placeholders, ", "))
+	q := fmt.Sprintf("SELECT %s FROM perfumers WHERE public_id IN (%s) AND deleted_at IS NULL", columns("", perfumerColumns), strings.Join(placeholders, ", "))
 
 	rows, err := service.db.Query(context.Background(), q, args...)
 	if err != nil {
@@ -204,17 +235,7 @@ func (service PerfumerService) FindMany(publicIds ...string) ([]*internal.Perfum
 
 	for rows.Next() {
 		var perfumer internal.Perfumer
-		if err := rows.Scan(
-			&perfumer.ID,
-			&perfumer.PublicId,
-			&perfumer.Slug,
-			&perfumer.Name,
-			&perfumer.Nationality,
-			&perfumer.ImageURL,
-			&perfumer.BirthDate,
-			&perfumer.CreatedAt,
-			&perfumer.UpdatedAt,
-		); err != nil {
+		if err := rows.Scan(perfumerFields(&perfumer)...); err != nil {
 			return nil, err
 		}
 
@@ -233,3 +254,18 @@ func (service PerfumerService) FindMany(publicIds ...string) ([]*internal.Perfum
 
 	return perfumers, nil
 }
+
+func (service PerfumerService) Delete(publicId string) error {
+	return softDelete(service.db, "perfumers", publicId, ErrPerfumerNotFound, guard{
+		query: `
+			SELECT 1 FROM perfumes_perfumers pp
+			JOIN perfumes p ON pp.perfume_id = p.public_id
+			WHERE pp.perfumer_id = $1 AND p.deleted_at IS NULL
+		`,
+		err: ErrPerfumerInUse,
+	})
+}
+
+func (service PerfumerService) Restore(publicId string) error {
+	return restore(service.db, "perfumers", publicId, ErrPerfumerNotFound, ErrPerfumerAlreadyExists)
+}
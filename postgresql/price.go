@@ -0,0 +1,222 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type PriceService struct {
+	db DB
+}
+
+// maxPriceHistory caps how many observations a price history returns.
+const maxPriceHistory = 1000
+
+var priceObservationColumns = []string{
+	"id",
+	"public_id",
+	"variant_id",
+	"retailer_id",
+	"price",
+	"currency",
+	"source",
+	"observed_at",
+	"created_by",
+	"created_at",
+}
+
+func priceObservationFields(observation *internal.PriceObservation) []any {
+	return []any{
+		&observation.ID,
+		&observation.PublicId,
+		&observation.VariantId,
+		&observation.RetailerId,
+		&observation.Price,
+		&observation.Currency,
+		&observation.Source,
+		&observation.ObservedAt,
+		&observation.CreatedBy,
+		&observation.CreatedAt,
+	}
+}
+
+// Record stores the observations in a single transaction, so that an import
+// either lands whole or not at all. Recording a price the retailer already
+// has for the variant at the same time replaces it, which makes re-running an
+// import harmless.
+func (service PriceService) Record(observations []*internal.PriceObservation) error {
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	for i, observation := range observations {
+		var sizeMl *int
+
+		err := tx.QueryRow(
+			context.Background(),
+			`
+			WITH recorded AS (
+				INSERT INTO price_observations (public_id, variant_id, retailer_id, price, currency, source, observed_at, created_by, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				ON CONFLICT (variant_id, retailer_id, observed_at)
+				DO UPDATE SET price = EXCLUDED.price, currency = EXCLUDED.currency, source = EXCLUDED.source, created_by = EXCLUDED.created_by
+				RETURNING id, public_id, variant_id
+			)
+			SELECT r.id, r.public_id, v.size_ml
+			FROM recorded r
+			JOIN perfume_variants v ON v.public_id = r.variant_id
+			`,
+			observation.PublicId,
+			observation.VariantId,
+			observation.RetailerId,
+			observation.Price,
+			observation.Currency,
+			observation.Source,
+			observation.ObservedAt,
+			observation.CreatedBy,
+			observation.CreatedAt,
+		).Scan(&observation.ID, &observation.PublicId, &sizeMl)
+
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				switch pgErr.ConstraintName {
+				case "fk_variant_id":
+					err = fmt.Errorf("%w: %w", ErrPerfumeVariantNotFound, pgErr)
+				case "fk_retailer_id":
+					err = fmt.Errorf("%w: %w", ErrRetailerNotFound, pgErr)
+				}
+			}
+
+			return fmt.Errorf("database error: record price observation %d: %w", i+1, err)
+		}
+
+		observation.PricePerMl = internal.PricePerMl(observation.Price, sizeMl)
+	}
+
+	return tx.Commit(context.Background())
+}
+
+// Lowest returns, for each variant of the perfume and each currency it is
+// sold in, the cheapest of the latest prices retailers observed since the
+// given time. Prices in different currencies are never compared.
+func (service PriceService) Lowest(perfumeId string, since time.Time) ([]internal.PriceOffer, error) {
+	q := fmt.Sprintf(
+		`
+		WITH current_prices AS (
+			SELECT DISTINCT ON (po.variant_id, po.retailer_id) po.variant_id, po.retailer_id, po.price, po.currency, po.observed_at
+			FROM price_observations po
+			JOIN perfume_variants v ON v.public_id = po.variant_id
+			WHERE v.perfume_id = $1 AND po.observed_at >= $2
+			ORDER BY po.variant_id, po.retailer_id, po.observed_at DESC
+		), lowest_prices AS (
+			SELECT DISTINCT ON (variant_id, currency) *
+			FROM current_prices
+			ORDER BY variant_id, currency, price, observed_at DESC
+		)
+		SELECT %s, %s, l.price, l.currency, l.observed_at
+		FROM lowest_prices l
+		JOIN perfume_variants v ON v.public_id = l.variant_id
+		JOIN retailers r ON r.public_id = l.retailer_id
+		ORDER BY l.currency, v.type, v.size_ml NULLS LAST, l.price
+		`,
+		columns("v", perfumeVariantColumns),
+		columns("r", retailerColumns),
+	)
+
+	rows, err := service.db.Query(context.Background(), q, perfumeId, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	offers := make([]internal.PriceOffer, 0)
+	for rows.Next() {
+		var variant internal.PerfumeVariant
+		var variantType string
+		var retailer internal.Retailer
+		var offer internal.PriceOffer
+
+		fields := perfumeVariantFields(&variant, &variantType)
+		fields = append(fields, retailerFields(&retailer)...)
+		fields = append(fields, &offer.Price, &offer.Currency, &offer.ObservedAt)
+
+		if err := rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+
+		variant.Type = internal.VariantType(variantType)
+		offer.Variant = &variant
+		offer.Retailer = &retailer
+		offer.PricePerMl = internal.PricePerMl(offer.Price, variant.SizeMl)
+
+		offers = append(offers, offer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return offers, nil
+}
+
+// History returns the price observations of the perfume's variants, oldest
+// first, up to maxPriceHistory of them.
+func (service PriceService) History(perfumeId string, filter internal.PriceHistoryFilter) ([]internal.PriceObservation, error) {
+	q := fmt.Sprintf(
+		`
+		SELECT %s, v.size_ml
+		FROM price_observations po
+		JOIN perfume_variants v ON v.public_id = po.variant_id
+		WHERE v.perfume_id = $1
+		  AND ($2 = '' OR po.variant_id = $2)
+		  AND ($3 = '' OR po.retailer_id = $3)
+		  AND ($4::date IS NULL OR po.observed_at::date >= $4)
+		  AND ($5::date IS NULL OR po.observed_at::date <= $5)
+		ORDER BY po.observed_at, po.id
+		LIMIT $6
+		`,
+		columns("po", priceObservationColumns),
+	)
+
+	rows, err := service.db.Query(
+		context.Background(),
+		q,
+		perfumeId,
+		filter.VariantId,
+		filter.RetailerId,
+		nullIfZero(filter.From),
+		nullIfZero(filter.To),
+		maxPriceHistory,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	observations := make([]internal.PriceObservation, 0)
+	for rows.Next() {
+		var observation internal.PriceObservation
+		var sizeMl *int
+
+		if err := rows.Scan(append(priceObservationFields(&observation), &sizeMl)...); err != nil {
+			return nil, err
+		}
+
+		observation.PricePerMl = internal.PricePerMl(observation.Price, sizeMl)
+		observations = append(observations, observation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return observations, nil
+}
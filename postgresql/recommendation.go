@@ -0,0 +1,144 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/internal/similarity"
+)
+
+type RecommendationService struct {
+	db DB
+}
+
+var (
+	ErrRecommendationExclusionNotFound = fmt.Errorf("recommendation exclusion not found")
+)
+
+// Interactions returns every perfume the user shelved or reviewed, with the
+// shelves it sits on and the user's review score.
+func (service RecommendationService) Interactions(userId int) ([]similarity.Interaction, error) {
+	rows, err := service.db.Query(
+		context.Background(),
+		`
+		SELECT i.perfume_id,
+		       COALESCE(array_agg(DISTINCT i.shelf) FILTER (WHERE i.shelf IS NOT NULL), '{}'),
+		       COALESCE(MAX(i.score), 0)
+		FROM (
+			SELECT perfume_id, shelf, NULL::int AS score FROM collection_items WHERE user_id = $1
+			UNION ALL
+			SELECT perfume_id, NULL, score FROM reviews WHERE user_id = $1
+		) i
+		GROUP BY i.perfume_id
+		ORDER BY i.perfume_id
+		`,
+		userId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	interactions := make([]similarity.Interaction, 0)
+	for rows.Next() {
+		var interaction similarity.Interaction
+		var shelves []string
+
+		if err := rows.Scan(&interaction.PerfumeID, &shelves, &interaction.ReviewScore); err != nil {
+			return nil, err
+		}
+
+		for _, shelf := range shelves {
+			interaction.Shelves = append(interaction.Shelves, internal.Shelf(shelf))
+		}
+
+		interactions = append(interactions, interaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return interactions, nil
+}
+
+func (service RecommendationService) Exclusions(userId int) ([]internal.RecommendationExclusion, error) {
+	rows, err := service.db.Query(
+		context.Background(),
+		`
+		SELECT entity_type, entity_id
+		FROM recommendation_exclusions
+		WHERE user_id = $1
+		ORDER BY created_at, entity_type, entity_id
+		`,
+		userId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	exclusions := make([]internal.RecommendationExclusion, 0)
+	for rows.Next() {
+		var exclusion internal.RecommendationExclusion
+		var entityType string
+
+		if err := rows.Scan(&entityType, &exclusion.EntityId); err != nil {
+			return nil, err
+		}
+
+		exclusion.Type = internal.EntityType(entityType)
+		exclusions = append(exclusions, exclusion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return exclusions, nil
+}
+
+// Exclude stops the house or note from being recommended to the user.
+// Excluding it twice is not an error.
+func (service RecommendationService) Exclude(userId int, exclusion internal.RecommendationExclusion) error {
+	_, err := service.db.Exec(
+		context.Background(),
+		`
+		INSERT INTO recommendation_exclusions (user_id, entity_type, entity_id, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, entity_type, entity_id) DO NOTHING
+		`,
+		userId,
+		exclusion.Type.String(),
+		exclusion.EntityId,
+		time.Now(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("exclude from recommendations error: %w", err)
+	}
+
+	return nil
+}
+
+func (service RecommendationService) Include(userId int, exclusion internal.RecommendationExclusion) error {
+	tag, err := service.db.Exec(
+		context.Background(),
+		`DELETE FROM recommendation_exclusions WHERE user_id = $1 AND entity_type = $2 AND entity_id = $3`,
+		userId,
+		exclusion.Type.String(),
+		exclusion.EntityId,
+	)
+
+	if err != nil {
+		return fmt.Errorf("include in recommendations error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s with public_id '%s'", ErrRecommendationExclusionNotFound, exclusion.Type, exclusion.EntityId)
+	}
+
+	return nil
+}
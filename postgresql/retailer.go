@@ -0,0 +1,160 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type RetailerService struct {
+	db DB
+}
+
+var (
+	ErrRetailerNotFound      = fmt.Errorf("retailer not found")
+	ErrRetailerAlreadyExists = fmt.Errorf("retailer already exists")
+	ErrRetailerInUse         = fmt.Errorf("retailer has price observations")
+)
+
+var retailerColumns = []string{
+	"id",
+	"public_id",
+	"slug",
+	"name",
+	"website",
+	"created_at",
+	"updated_at",
+}
+
+func retailerFields(retailer *internal.Retailer) []any {
+	return []any{
+		&retailer.ID,
+		&retailer.PublicId,
+		&retailer.Slug,
+		&retailer.Name,
+		&retailer.Website,
+		&retailer.CreatedAt,
+		&retailer.UpdatedAt,
+	}
+}
+
+func (service RetailerService) List(cursor, perPage int) ([]internal.Retailer, error) {
+	if cursor <= 0 {
+		cursor = 0
+	}
+
+	q := fmt.Sprintf(`SELECT %s FROM retailers WHERE id > $1 ORDER BY id LIMIT $2`, columns("", retailerColumns))
+
+	rows, err := service.db.Query(context.Background(), q, cursor, perPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	retailers := make([]internal.Retailer, 0)
+	for rows.Next() {
+		var retailer internal.Retailer
+		if err := rows.Scan(retailerFields(&retailer)...); err != nil {
+			return nil, err
+		}
+
+		retailers = append(retailers, retailer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return retailers, nil
+}
+
+func (service RetailerService) Save(retailer *internal.Retailer) error {
+	var err error
+	if retailer.ID == 0 {
+		err = service.db.QueryRow(
+			context.Background(),
+			`
+			INSERT INTO retailers (public_id, slug, name, website, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+			`,
+			retailer.PublicId,
+			retailer.Slug,
+			retailer.Name,
+			retailer.Website,
+			retailer.CreatedAt,
+			retailer.UpdatedAt,
+		).Scan(&retailer.ID)
+	} else {
+		retailer.UpdatedAt = time.Now()
+		_, err = service.db.Exec(
+			context.Background(),
+			`UPDATE retailers SET slug = $2, name = $3, website = $4, updated_at = $5 WHERE id = $1`,
+			retailer.ID,
+			retailer.Slug,
+			retailer.Name,
+			retailer.Website,
+			retailer.UpdatedAt,
+		)
+	}
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("database error: %w: %w", ErrRetailerAlreadyExists, pgErr)
+		}
+
+		return fmt.Errorf("database error: save retailer error: %w", err)
+	}
+
+	return nil
+}
+
+func (service RetailerService) Find(publicId string) (*internal.Retailer, error) {
+	return service.findBy("public_id", publicId)
+}
+
+func (service RetailerService) FindBySlug(s string) (*internal.Retailer, error) {
+	return service.findBy("slug", s)
+}
+
+func (service RetailerService) findBy(column, value string) (*internal.Retailer, error) {
+	var retailer internal.Retailer
+
+	q := fmt.Sprintf(`SELECT %s FROM retailers WHERE %s = $1`, columns("", retailerColumns), column)
+
+	if err := service.db.QueryRow(context.Background(), q, value).Scan(retailerFields(&retailer)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: retailer with %s '%s' not found", ErrRetailerNotFound, column, value)
+		}
+
+		return nil, err
+	}
+
+	return &retailer, nil
+}
+
+// Delete removes a retailer. Retailers with price observations are kept, so
+// that deleting one never rewrites the price history of a perfume.
+func (service RetailerService) Delete(retailer *internal.Retailer) error {
+	tag, err := service.db.Exec(context.Background(), `DELETE FROM retailers WHERE id = $1`, retailer.ID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("database error: %w: %w", ErrRetailerInUse, pgErr)
+		}
+
+		return fmt.Errorf("database error: delete retailer error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: retailer with public_id '%s' not found", ErrRetailerNotFound, retailer.PublicId)
+	}
+
+	return nil
+}
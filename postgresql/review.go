@@ -0,0 +1,291 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type ReviewService struct {
+	db DB
+}
+
+var (
+	ErrReviewNotFound      = fmt.Errorf("review not found")
+	ErrReviewAlreadyExists = fmt.Errorf("perfume has already been reviewed by the user")
+)
+
+const reviewSelectQuery = `
+	SELECT r.id,
+	       r.public_id,
+	       r.perfume_id,
+	       r.user_id,
+	       u.username,
+	       r.score,
+	       r.text,
+	       r.longevity,
+	       r.sillage,
+	       r.value,
+	       r.created_at,
+	       r.updated_at
+	FROM reviews r
+	JOIN users u ON u.id = r.user_id
+`
+
+func scanReview(row pgx.Row) (*internal.Review, error) {
+	var review internal.Review
+
+	if err := row.Scan(
+		&review.ID,
+		&review.PublicId,
+		&review.PerfumeId,
+		&review.UserId,
+		&review.Author,
+		&review.Score,
+		&review.Text,
+		&review.Longevity,
+		&review.Sillage,
+		&review.Value,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// Save inserts or updates the review and adjusts the rating of the perfume
+// in the same transaction.
+func (service ReviewService) Save(review *internal.Review) error {
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	if review.ID == 0 {
+		err = service.saveNewReview(tx, review)
+	} else {
+		err = service.updateReview(tx, review)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
+
+func (service ReviewService) saveNewReview(tx pgx.Tx, review *internal.Review) error {
+	err := tx.QueryRow(
+		context.Background(),
+		`
+		INSERT INTO reviews (public_id, perfume_id, user_id, score, text, longevity, sillage, value, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+		`,
+		review.PublicId,
+		review.PerfumeId,
+		review.UserId,
+		review.Score,
+		review.Text,
+		review.Longevity,
+		review.Sillage,
+		review.Value,
+		review.CreatedAt,
+		review.UpdatedAt,
+	).Scan(&review.ID)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		ok := errors.As(err, &pgErr)
+		if !ok {
+			return fmt.Errorf("save review error: %w", err)
+		}
+
+		switch pgErr.Code {
+		case "23505":
+			return fmt.Errorf("database error: %w: %w", ErrReviewAlreadyExists, pgErr)
+		case "23503":
+			return fmt.Errorf("database error: %w: %w", ErrPerfumeNotFound, pgErr)
+		default:
+			return fmt.Errorf("save review error: %w", err)
+		}
+	}
+
+	return adjustRating(tx, review.PerfumeId, review.Score, 1)
+}
+
+func (service ReviewService) updateReview(tx pgx.Tx, review *internal.Review) error {
+	var previousScore int
+	err := tx.QueryRow(context.Background(), `SELECT score FROM reviews WHERE id = $1 FOR UPDATE`, review.ID).Scan(&previousScore)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: review with public_id '%s' not found", ErrReviewNotFound, review.PublicId)
+		}
+
+		return fmt.Errorf("update review error: %w", err)
+	}
+
+	review.UpdatedAt = time.Now()
+	_, err = tx.Exec(
+		context.Background(),
+		`
+		UPDATE reviews
+		SET score = $2,
+		    text = $3,
+		    longevity = $4,
+		    sillage = $5,
+		    value = $6,
+		    updated_at = $7
+		WHERE id = $1
+		`,
+		review.ID,
+		review.Score,
+		review.Text,
+		review.Longevity,
+		review.Sillage,
+		review.Value,
+		review.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("update review error: %w", err)
+	}
+
+	if previousScore == review.Score {
+		return nil
+	}
+
+	if err := adjustRating(tx, review.PerfumeId, previousScore, -1); err != nil {
+		return err
+	}
+
+	return adjustRating(tx, review.PerfumeId, review.Score, 1)
+}
+
+// Delete removes the review and takes its score out of the rating of the
+// perfume.
+func (service ReviewService) Delete(review *internal.Review) error {
+	tx, err := service.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	var score int
+	err = tx.QueryRow(context.Background(), `DELETE FROM reviews WHERE id = $1 RETURNING score`, review.ID).Scan(&score)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: review with public_id '%s' not found", ErrReviewNotFound, review.PublicId)
+		}
+
+		return fmt.Errorf("delete review error: %w", err)
+	}
+
+	if err := adjustRating(tx, review.PerfumeId, score, -1); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
+
+// adjustRating adds delta reviews with the given score to the rating of the
+// perfume. Postgres arrays are 1-based, so the lowest score lands in slot 1.
+// The row lock taken by the update serializes concurrent reviews of the same
+// perfume.
+func adjustRating(tx pgx.Tx, perfumeId string, score, delta int) error {
+	_, err := tx.Exec(
+		context.Background(),
+		`INSERT INTO perfume_ratings (perfume_id) VALUES ($1) ON CONFLICT (perfume_id) DO NOTHING`,
+		perfumeId,
+	)
+	if err != nil {
+		return fmt.Errorf("adjust rating error: %w", err)
+	}
+
+	_, err = tx.Exec(
+		context.Background(),
+		`
+		UPDATE perfume_ratings
+		SET count = count + $4,
+		    score_sum = score_sum + $2 * $4,
+		    histogram[$3] = histogram[$3] + $4
+		WHERE perfume_id = $1
+		`,
+		perfumeId,
+		score,
+		score-internal.MinReviewScore+1,
+		delta,
+	)
+	if err != nil {
+		return fmt.Errorf("adjust rating error: %w", err)
+	}
+
+	return nil
+}
+
+func (service ReviewService) Find(publicId string) (*internal.Review, error) {
+	review, err := scanReview(service.db.QueryRow(context.Background(), reviewSelectQuery+" WHERE r.public_id = $1", publicId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: review with public_id '%s' not found", ErrReviewNotFound, publicId)
+		}
+
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (service ReviewService) List(filter internal.ReviewFilter, cursor, perPage int) ([]internal.Review, error) {
+	if cursor <= 0 {
+		cursor = 0
+	}
+
+	args := []any{cursor}
+	conditions := []string{"r.id > $1"}
+
+	if filter.PerfumeId != "" {
+		args = append(args, filter.PerfumeId)
+		conditions = append(conditions, "r.perfume_id = $"+strconv.Itoa(len(args)))
+	}
+
+	if filter.UserId != 0 {
+		args = append(args, filter.UserId)
+		conditions = append(conditions, "r.user_id = $"+strconv.Itoa(len(args)))
+	}
+
+	args = append(args, perPage)
+	q := fmt.Sprintf("%s WHERE %s ORDER BY r.id LIMIT $%d", reviewSelectQuery, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := service.db.Query(context.Background(), q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := make([]internal.Review, 0)
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		reviews = append(reviews, *review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
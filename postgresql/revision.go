@@ -0,0 +1,142 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrRevisionNotFound = fmt.Errorf("revision not found")
+)
+
+type RevisionService struct {
+	db DB
+}
+
+var revisionColumns = []string{
+	"id",
+	"entity_type",
+	"entity_id",
+	"revision",
+	"snapshot",
+	"actor",
+	"reason",
+	"created_at",
+}
+
+func scanRevision(row pgx.Row) (*internal.Revision, error) {
+	var revision internal.Revision
+	var entityType string
+	var snapshot []byte
+	var reason *string
+
+	if err := row.Scan(
+		&revision.ID,
+		&entityType,
+		&revision.EntityId,
+		&revision.Number,
+		&snapshot,
+		&revision.Actor,
+		&reason,
+		&revision.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	revision.EntityType = internal.EntityType(entityType)
+	revision.Snapshot = snapshot
+	if reason != nil {
+		revision.Reason = *reason
+	}
+
+	return &revision, nil
+}
+
+// recordRevision stores a snapshot of the entity as its next revision, as
+// part of the transaction that saved it.
+func recordRevision(tx pgx.Tx, entityType internal.EntityType, entityId string, entity any, meta internal.ChangeMeta) error {
+	snapshot, err := json.Marshal(entity)
+	if err != nil {
+		return fmt.Errorf("record revision error: %w", err)
+	}
+
+	q := `
+		INSERT INTO revisions (entity_type, entity_id, revision, snapshot, actor, reason, created_at)
+		SELECT $1, $2, COALESCE(MAX(revision), 0) + 1, $3, $4, $5, $6
+		FROM revisions
+		WHERE entity_type = $1 AND entity_id = $2
+	`
+
+	_, err = tx.Exec(
+		context.Background(),
+		q,
+		entityType.String(),
+		entityId,
+		snapshot,
+		meta.Actor,
+		meta.Reason,
+		time.Now(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("database error: record revision error: %w", err)
+	}
+
+	return nil
+}
+
+func (service RevisionService) List(entityType internal.EntityType, entityId string, cursor, perPage int) ([]internal.Revision, error) {
+	if cursor <= 0 {
+		cursor = 0
+	}
+
+	q := fmt.Sprintf(
+		`SELECT %s FROM revisions WHERE entity_type = $1 AND entity_id = $2 AND id > $3 ORDER BY id LIMIT $4`,
+		columns("", revisionColumns),
+	)
+
+	rows, err := service.db.Query(context.Background(), q, entityType.String(), entityId, cursor, perPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]internal.Revision, 0)
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, *revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (service RevisionService) Find(entityType internal.EntityType, entityId string, number int) (*internal.Revision, error) {
+	q := fmt.Sprintf(
+		`SELECT %s FROM revisions WHERE entity_type = $1 AND entity_id = $2 AND revision = $3`,
+		columns("", revisionColumns),
+	)
+
+	revision, err := scanRevision(service.db.QueryRow(context.Background(), q, entityType.String(), entityId, number))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s '%s' revision %d", ErrRevisionNotFound, entityType, entityId, number)
+		}
+		return nil, err
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,164 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ej-agas/perfume-db/internal"
+)
+
+type SearchService struct {
+	db DB
+}
+
+type searchHit struct {
+	entityType internal.EntityType
+	publicId   string
+	rank       float64
+}
+
+func (service SearchService) Search(query string, limit int) ([]internal.SearchResult, error) {
+	q := `
+		WITH search AS (SELECT websearch_to_tsquery('english', $1) AS query)
+		SELECT type, public_id, rank FROM (
+			SELECT 'perfume' AS type, public_id, ts_rank(search_vector, search.query) AS rank
+			FROM perfumes, search WHERE search_vector @@ search.query AND deleted_at IS NULL
+			UNION ALL
+			SELECT 'house' AS type, public_id, ts_rank(search_vector, search.query) AS rank
+			FROM houses, search WHERE search_vector @@ search.query AND deleted_at IS NULL
+			UNION ALL
+			SELECT 'perfumer' AS type, public_id, ts_rank(search_vector, search.query) AS rank
+			FROM perfumers, search WHERE search_vector @@ search.query AND deleted_at IS NULL
+			UNION ALL
+			SELECT 'note' AS type, public_id, ts_rank(search_vector, search.query) AS rank
+			FROM notes, search WHERE search_vector @@ search.query AND deleted_at IS NULL
+			UNION ALL
+			SELECT 'note_group' AS type, public_id, ts_rank(search_vector, search.query) AS rank
+			FROM note_groups, search WHERE search_vector @@ search.query AND deleted_at IS NULL
+		) results
+		ORDER BY rank DESC, type, public_id
+		LIMIT $2
+	`
+
+	rows, err := service.db.Query(context.Background(), q, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []searchHit
+	publicIds := make(map[internal.EntityType][]string)
+
+	for rows.Next() {
+		var hit searchHit
+		var entityType string
+		var rank float32
+
+		if err := rows.Scan(&entityType, &hit.publicId, &rank); err != nil {
+			return nil, err
+		}
+
+		hit.entityType = internal.EntityType(entityType)
+		hit.rank = float64(rank)
+		hits = append(hits, hit)
+		publicIds[hit.entityType] = append(publicIds[hit.entityType], hit.publicId)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	models, err := service.loadModels(publicIds)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]internal.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		model, ok := models[hit.entityType][hit.publicId]
+		if !ok {
+			continue
+		}
+
+		results = append(results, internal.SearchResult{Type: hit.entityType, Rank: hit.rank, Data: model})
+	}
+
+	return results, nil
+}
+
+// loadModels fetches the matched models of every entity type, keyed by type
+// and public ID. Models deleted since the search ran are left out.
+func (service SearchService) loadModels(publicIds map[internal.EntityType][]string) (map[internal.EntityType]map[string]any, error) {
+	models := make(map[internal.EntityType]map[string]any)
+	for entityType := range publicIds {
+		models[entityType] = make(map[string]any)
+	}
+
+	if ids := publicIds[internal.PerfumeEntity]; len(ids) > 0 {
+		perfumes, err := PerfumeService{db: service.db}.FindAvailable(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, perfume := range perfumes {
+			models[internal.PerfumeEntity][perfume.PublicId] = perfume
+		}
+	}
+
+	err := loadLive(service.db, "houses", houseColumns, houseFields, publicIds[internal.HouseEntity], func(house *internal.House) {
+		models[internal.HouseEntity][house.PublicId] = house
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = loadLive(service.db, "perfumers", perfumerColumns, perfumerFields, publicIds[internal.PerfumerEntity], func(perfumer *internal.Perfumer) {
+		models[internal.PerfumerEntity][perfumer.PublicId] = perfumer
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = loadLive(service.db, "notes", noteColumns, noteFields, publicIds[internal.NoteEntity], func(note *internal.Note) {
+		models[internal.NoteEntity][note.PublicId] = note
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = loadLive(service.db, "note_groups", noteGroupColumns, noteGroupFields, publicIds[internal.NoteGroupEntity], func(noteGroup *internal.NoteGroup) {
+		models[internal.NoteGroupEntity][noteGroup.PublicId] = noteGroup
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// loadLive scans the rows of the table with the given public IDs that are
+// not soft-deleted, passing each of them to add. Missing rows are not an
+// error, since a result may be deleted before it is loaded.
+func loadLive[T any](db DB, table string, names []string, fields func(*T) []any, publicIds []string, add func(*T)) error {
+	if len(publicIds) == 0 {
+		return nil
+	}
+
+	q := fmt.Sprintf(`SELECT %s FROM %s WHERE public_id = ANY($1) AND deleted_at IS NULL`, columns("", names), table)
+
+	rows, err := db.Query(context.Background(), q, publicIds)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		model := new(T)
+		if err := rows.Scan(fields(model)...); err != nil {
+			return err
+		}
+
+		add(model)
+	}
+
+	return rows.Err()
+}
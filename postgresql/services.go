@@ -1,23 +1,88 @@
 package postgresql
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Services struct {
-	House     *HouseService
-	Note      *NoteService
-	NoteGroup *NoteGroupService
-	Perfumer  *PerfumerService
-	Perfume   *PerfumeService
+	db              DB
+	House           *HouseService
+	Note            *NoteService
+	NoteGroup       *NoteGroupService
+	Perfumer        *PerfumerService
+	Perfume         *PerfumeService
+	Search          *SearchService
+	Autocomplete    *AutocompleteService
+	Revision        *RevisionService
+	User            *UserService
+	Token           *TokenService
+	Submission      *SubmissionService
+	ChangeRequest   *ChangeRequestService
+	Review          *ReviewService
+	Vote            *VoteService
+	Collection      *CollectionService
+	Wear            *WearService
+	Similarity      *SimilarityService
+	Recommendation  *RecommendationService
+	Family          *FamilyService
+	Accord          *AccordService
+	PerfumeRelation *PerfumeRelationService
+	Inspiration     *InspirationService
+	PerfumeVariant  *PerfumeVariantService
+	Retailer        *RetailerService
+	Price           *PriceService
 }
 
 func NewServices(db *pgxpool.Pool) *Services {
+	return newServices(db)
+}
+
+func newServices(db DB) *Services {
 	return &Services{
-		House:     &HouseService{db: db},
-		Note:      &NoteService{db: db},
-		NoteGroup: &NoteGroupService{db: db},
-		Perfumer:  &PerfumerService{db: db},
-		Perfume:   &PerfumeService{db: db},
+		db:              db,
+		House:           &HouseService{db: db},
+		Note:            &NoteService{db: db},
+		NoteGroup:       &NoteGroupService{db: db},
+		Perfumer:        &PerfumerService{db: db},
+		Perfume:         &PerfumeService{db: db},
+		Search:          &SearchService{db: db},
+		Autocomplete:    &AutocompleteService{db: db},
+		Revision:        &RevisionService{db: db},
+		User:            &UserService{db: db},
+		Token:           &TokenService{db: db},
+		Submission:      &SubmissionService{db: db},
+		ChangeRequest:   &ChangeRequestService{db: db},
+		Review:          &ReviewService{db: db},
+		Vote:            &VoteService{db: db},
+		Collection:      &CollectionService{db: db},
+		Wear:            &WearService{db: db},
+		Similarity:      &SimilarityService{db: db},
+		Recommendation:  &RecommendationService{db: db},
+		Family:          &FamilyService{db: db},
+		Accord:          &AccordService{db: db},
+		PerfumeRelation: &PerfumeRelationService{db: db},
+		Inspiration:     &InspirationService{db: db},
+		PerfumeVariant:  &PerfumeVariantService{db: db},
+		Retailer:        &RetailerService{db: db},
+		Price:           &PriceService{db: db},
 	}
 }
+
+// Transaction runs fn with services bound to a single database transaction,
+// which is committed when fn returns nil and rolled back otherwise.
+func (services *Services) Transaction(fn func(tx *Services) error) error {
+	tx, err := services.db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	if err := fn(newServices(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
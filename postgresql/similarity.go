@@ -0,0 +1,73 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/ej-agas/perfume-db/internal/similarity"
+)
+
+type SimilarityService struct {
+	db DB
+}
+
+// Perfumes returns the notes of every live perfume in the shape the
+// similarity index is built from, in a single query.
+func (service SimilarityService) Perfumes() ([]similarity.Perfume, error) {
+	return similarityPerfumes(service.db, nil)
+}
+
+// similarityPerfumes loads the given perfumes, or every perfume when publicIds
+// is nil. Perfumes without notes are left out.
+func similarityPerfumes(db DB, publicIds []string) ([]similarity.Perfume, error) {
+	rows, err := db.Query(
+		context.Background(),
+		`
+		SELECT p.public_id, p.house_id, p.concentration, pn.note_id, n.note_group_id, pn.category
+		FROM perfumes p
+		JOIN perfumes_notes pn ON pn.perfume_id = p.public_id
+		JOIN notes n ON n.public_id = pn.note_id
+		WHERE p.deleted_at IS NULL
+		  AND ($1::varchar[] IS NULL OR p.public_id = ANY($1))
+		ORDER BY p.id
+		`,
+		publicIds,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	perfumes := make([]similarity.Perfume, 0)
+	for rows.Next() {
+		var perfumeId, houseId string
+		var concentration internal.Concentration
+		var note similarity.Note
+		var category *string
+
+		if err := rows.Scan(&perfumeId, &houseId, &concentration, &note.ID, &note.GroupID, &category); err != nil {
+			return nil, err
+		}
+
+		note.Category = internal.UncategorizedNote
+		if category != nil {
+			if parsed, err := internal.NoteCategoryFromString(*category); err == nil {
+				note.Category = parsed
+			}
+		}
+
+		if len(perfumes) == 0 || perfumes[len(perfumes)-1].ID != perfumeId {
+			perfumes = append(perfumes, similarity.Perfume{ID: perfumeId, HouseID: houseId, Concentration: concentration})
+		}
+
+		last := &perfumes[len(perfumes)-1]
+		last.Notes = append(last.Notes, note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return perfumes, nil
+}
@@ -0,0 +1,100 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// guard is an EXISTS sub-query taking the public ID as $1. When it matches a
+// row the guarded operation is aborted with err.
+type guard struct {
+	query string
+	err   error
+}
+
+func checkGuards(tx pgx.Tx, publicId string, guards []guard) error {
+	for _, g := range guards {
+		var matched bool
+		q := fmt.Sprintf("SELECT EXISTS (%s)", g.query)
+
+		if err := tx.QueryRow(context.Background(), q, publicId).Scan(&matched); err != nil {
+			return err
+		}
+
+		if matched {
+			return g.err
+		}
+	}
+
+	return nil
+}
+
+// softDelete marks a live row of the table as deleted.
+func softDelete(db DB, table, publicId string, errNotFound error, guards ...guard) error {
+	tx, err := db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	var id int
+	q := fmt.Sprintf("SELECT id FROM %s WHERE public_id = $1 AND deleted_at IS NULL FOR UPDATE", table)
+
+	if err := tx.QueryRow(context.Background(), q, publicId).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: public_id '%s'", errNotFound, publicId)
+		}
+		return err
+	}
+
+	if err := checkGuards(tx, publicId, guards); err != nil {
+		return err
+	}
+
+	q = fmt.Sprintf("UPDATE %s SET deleted_at = $2 WHERE id = $1", table)
+	if _, err := tx.Exec(context.Background(), q, id, time.Now()); err != nil {
+		return fmt.Errorf("database error: delete from %s error: %w", table, err)
+	}
+
+	return tx.Commit(context.Background())
+}
+
+// restore brings a soft-deleted row of the table back to life.
+func restore(db DB, table, publicId string, errNotFound, errAlreadyExists error, guards ...guard) error {
+	tx, err := db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStartingDBTx, err)
+	}
+	defer tx.Rollback(context.Background())
+
+	var id int
+	q := fmt.Sprintf("SELECT id FROM %s WHERE public_id = $1 AND deleted_at IS NOT NULL FOR UPDATE", table)
+
+	if err := tx.QueryRow(context.Background(), q, publicId).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: deleted public_id '%s'", errNotFound, publicId)
+		}
+		return err
+	}
+
+	if err := checkGuards(tx, publicId, guards); err != nil {
+		return err
+	}
+
+	q = fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE id = $1", table)
+	if _, err := tx.Exec(context.Background(), q, id); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("database error: %w: %w", errAlreadyExists, pgErr)
+		}
+
+		return fmt.Errorf("database error: restore %s error: %w", table, err)
+	}
+
+	return tx.Commit(context.Background())
+}
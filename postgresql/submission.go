@@ -0,0 +1,208 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
+)
+
+type SubmissionService struct {
+	db DB
+}
+
+var (
+	ErrSubmissionNotFound = fmt.Errorf("submission not found")
+)
+
+const submissionSelectQuery = `
+	SELECT s.id,
+	       s.public_id,
+	       s.entity_type,
+	       s.payload,
+	       s.status,
+	       s.submitter_id,
+	       submitter.username,
+	       s.reviewer_id,
+	       reviewer.username,
+	       s.comment,
+	       s.entity_id,
+	       s.created_at,
+	       s.reviewed_at
+	FROM submissions s
+	JOIN users submitter ON submitter.id = s.submitter_id
+	LEFT JOIN users reviewer ON reviewer.id = s.reviewer_id
+`
+
+func scanSubmission(row pgx.Row) (*internal.Submission, error) {
+	var submission internal.Submission
+	var entityType, status string
+	var payload []byte
+	var reviewerId *int
+	var reviewer, comment, entityId *string
+
+	if err := row.Scan(
+		&submission.ID,
+		&submission.PublicId,
+		&entityType,
+		&payload,
+		&status,
+		&submission.SubmitterId,
+		&submission.Submitter,
+		&reviewerId,
+		&reviewer,
+		&comment,
+		&entityId,
+		&submission.CreatedAt,
+		&submission.ReviewedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	submission.EntityType = internal.EntityType(entityType)
+	submission.Status = internal.SubmissionStatus(status)
+	submission.Payload = payload
+
+	if reviewerId != nil {
+		submission.ReviewerId = *reviewerId
+	}
+	if reviewer != nil {
+		submission.Reviewer = *reviewer
+	}
+	if comment != nil {
+		submission.Comment = *comment
+	}
+	if entityId != nil {
+		submission.EntityId = *entityId
+	}
+
+	return &submission, nil
+}
+
+func (service SubmissionService) Save(submission *internal.Submission) error {
+	err := service.db.QueryRow(
+		context.Background(),
+		`
+		INSERT INTO submissions (public_id, entity_type, payload, status, submitter_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+		`,
+		submission.PublicId,
+		submission.EntityType.String(),
+		[]byte(submission.Payload),
+		submission.Status.String(),
+		submission.SubmitterId,
+		submission.CreatedAt,
+	).Scan(&submission.ID)
+
+	if err != nil {
+		return fmt.Errorf("save submission error: %w", err)
+	}
+
+	return nil
+}
+
+// Review stores the outcome of a review. Only pending submissions can be
+// reviewed, so that two moderators cannot both act on the same submission.
+func (service SubmissionService) Review(submission *internal.Submission) error {
+	var reviewerId, comment, entityId any
+	if submission.ReviewerId != 0 {
+		reviewerId = submission.ReviewerId
+	}
+	if submission.Comment != "" {
+		comment = submission.Comment
+	}
+	if submission.EntityId != "" {
+		entityId = submission.EntityId
+	}
+
+	tag, err := service.db.Exec(
+		context.Background(),
+		`
+		UPDATE submissions
+		SET status = $2,
+		    reviewer_id = $3,
+		    comment = $4,
+		    entity_id = $5,
+		    reviewed_at = $6
+		WHERE id = $1 AND status = 'pending'
+		`,
+		submission.ID,
+		submission.Status.String(),
+		reviewerId,
+		comment,
+		entityId,
+		submission.ReviewedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("review submission error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: submission with public_id '%s'", internal.ErrSubmissionNotPending, submission.PublicId)
+	}
+
+	return nil
+}
+
+func (service SubmissionService) Find(publicId string) (*internal.Submission, error) {
+	submission, err := scanSubmission(service.db.QueryRow(context.Background(), submissionSelectQuery+" WHERE s.public_id = $1", publicId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: submission with public_id '%s' not found", ErrSubmissionNotFound, publicId)
+		}
+
+		return nil, err
+	}
+
+	return submission, nil
+}
+
+func (service SubmissionService) List(filter internal.SubmissionFilter, cursor, perPage int) ([]internal.Submission, error) {
+	if cursor <= 0 {
+		cursor = 0
+	}
+
+	args := []any{cursor}
+	conditions := []string{"s.id > $1"}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status.String())
+		conditions = append(conditions, "s.status = $"+strconv.Itoa(len(args)))
+	}
+
+	if filter.SubmitterId != 0 {
+		args = append(args, filter.SubmitterId)
+		conditions = append(conditions, "s.submitter_id = $"+strconv.Itoa(len(args)))
+	}
+
+	args = append(args, perPage)
+	q := fmt.Sprintf("%s WHERE %s ORDER BY s.id LIMIT $%d", submissionSelectQuery, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := service.db.Query(context.Background(), q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	submissions := make([]internal.Submission, 0)
+	for rows.Next() {
+		submission, err := scanSubmission(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		submissions = append(submissions, *submission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return submissions, nil
+}
@@ -0,0 +1,103 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ej-agas/perfume-db/internal"
+)
+
+type TokenService struct {
+	db DB
+}
+
+var (
+	ErrTokenNotFound = fmt.Errorf("token not found")
+)
+
+func (service TokenService) Save(token *internal.Token) error {
+	err := service.db.QueryRow(
+		context.Background(),
+		`
+		INSERT INTO tokens (public_id, user_id, name, hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+		`,
+		token.PublicId,
+		token.UserId,
+		token.Name,
+		token.Hash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.ID)
+
+	if err != nil {
+		return fmt.Errorf("save token error: %w", err)
+	}
+
+	return nil
+}
+
+// ListForUser returns the live tokens of the user, newest first.
+func (service TokenService) ListForUser(userId int) ([]internal.Token, error) {
+	q := `
+		SELECT id, public_id, user_id, name, expires_at, last_used_at, created_at
+		FROM tokens
+		WHERE user_id = $1
+		  AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > now())
+		ORDER BY id DESC
+	`
+
+	rows, err := service.db.Query(context.Background(), q, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make([]internal.Token, 0)
+	for rows.Next() {
+		var token internal.Token
+
+		if err := rows.Scan(
+			&token.ID,
+			&token.PublicId,
+			&token.UserId,
+			&token.Name,
+			&token.ExpiresAt,
+			&token.LastUsedAt,
+			&token.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Revoke revokes one of the user's tokens. Tokens of other users are reported
+// as not found.
+func (service TokenService) Revoke(userId int, publicId string) error {
+	tag, err := service.db.Exec(
+		context.Background(),
+		`UPDATE tokens SET revoked_at = now() WHERE user_id = $1 AND public_id = $2 AND revoked_at IS NULL`,
+		userId,
+		publicId,
+	)
+
+	if err != nil {
+		return fmt.Errorf("revoke token error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: token with public_id '%s' not found", ErrTokenNotFound, publicId)
+	}
+
+	return nil
+}
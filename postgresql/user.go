@@ -0,0 +1,204 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type UserService struct {
+	db DB
+}
+
+var (
+	ErrUserNotFound      = fmt.Errorf("user not found")
+	ErrDuplicateUsername = fmt.Errorf("username is already taken")
+	ErrDuplicateEmail    = fmt.Errorf("email is already registered")
+)
+
+var userColumns = []string{
+	"id",
+	"public_id",
+	"username",
+	"email",
+	"password_hash",
+	"role",
+	"created_at",
+	"updated_at",
+}
+
+func userFields(user *internal.User) []any {
+	return []any{
+		&user.ID,
+		&user.PublicId,
+		&user.Username,
+		&user.Email,
+		&user.PasswordHash,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	}
+}
+
+// Save inserts or updates the user.
+func (service UserService) Save(user *internal.User) error {
+	var err error
+
+	if user.ID == 0 {
+		err = service.db.QueryRow(
+			context.Background(),
+			`
+			INSERT INTO users (public_id, username, email, password_hash, role, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id
+			`,
+			user.PublicId,
+			user.Username,
+			user.Email,
+			user.PasswordHash,
+			user.Role.String(),
+			user.CreatedAt,
+			user.UpdatedAt,
+		).Scan(&user.ID)
+	} else {
+		user.UpdatedAt = time.Now()
+		_, err = service.db.Exec(
+			context.Background(),
+			`
+			UPDATE users
+			SET username = $2,
+			    email = $3,
+			    password_hash = $4,
+			    role = $5,
+			    updated_at = $6
+			WHERE id = $1
+			`,
+			user.ID,
+			user.Username,
+			user.Email,
+			user.PasswordHash,
+			user.Role.String(),
+			user.UpdatedAt,
+		)
+	}
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			switch pgErr.ConstraintName {
+			case "users_unique_username__idx":
+				return fmt.Errorf("database error: %w: %w", ErrDuplicateUsername, pgErr)
+			case "users_unique_email__idx":
+				return fmt.Errorf("database error: %w: %w", ErrDuplicateEmail, pgErr)
+			}
+		}
+
+		return fmt.Errorf("save user error: %w", err)
+	}
+
+	return nil
+}
+
+func (service UserService) findBy(condition string, arg any) (*internal.User, error) {
+	q := fmt.Sprintf(`SELECT %s FROM users WHERE %s`, columns("", userColumns), condition)
+
+	var user internal.User
+	if err := service.db.QueryRow(context.Background(), q, arg).Scan(userFields(&user)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (service UserService) Find(publicId string) (*internal.User, error) {
+	user, err := service.findBy("public_id = $1", publicId)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, fmt.Errorf("%w: user with public_id '%s' not found", ErrUserNotFound, publicId)
+	}
+
+	return user, err
+}
+
+func (service UserService) FindByUsername(username string) (*internal.User, error) {
+	user, err := service.findBy("lower(username) = lower($1)", username)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, fmt.Errorf("%w: user with username '%s' not found", ErrUserNotFound, username)
+	}
+
+	return user, err
+}
+
+func (service UserService) FindByEmail(email string) (*internal.User, error) {
+	user, err := service.findBy("lower(email) = lower($1)", email)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, fmt.Errorf("%w: user with email '%s' not found", ErrUserNotFound, email)
+	}
+
+	return user, err
+}
+
+// FindByToken returns the owner of a live (unrevoked, unexpired) token and
+// records that the token has been used.
+func (service UserService) FindByToken(plaintext string) (*internal.User, error) {
+	q := fmt.Sprintf(`
+		WITH used AS (
+			UPDATE tokens
+			SET last_used_at = now()
+			WHERE hash = $1
+			  AND revoked_at IS NULL
+			  AND (expires_at IS NULL OR expires_at > now())
+			RETURNING user_id
+		)
+		SELECT %s FROM users u JOIN used ON used.user_id = u.id
+	`, columns("u", userColumns))
+
+	var user internal.User
+	if err := service.db.QueryRow(context.Background(), q, internal.HashToken(plaintext)).Scan(userFields(&user)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: no user for the given token", ErrUserNotFound)
+		}
+
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (service UserService) List(cursor, perPage int) ([]internal.User, error) {
+	if cursor <= 0 {
+		cursor = 0
+	}
+
+	q := fmt.Sprintf(`SELECT %s FROM users WHERE id > $1 ORDER BY id LIMIT $2`, columns("", userColumns))
+
+	rows, err := service.db.Query(context.Background(), q, cursor, perPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]internal.User, 0)
+	for rows.Next() {
+		var user internal.User
+		if err := rows.Scan(userFields(&user)...); err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
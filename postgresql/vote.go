@@ -0,0 +1,70 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type VoteService struct {
+	db DB
+}
+
+var (
+	ErrVoteNotFound = fmt.Errorf("vote not found")
+)
+
+// Cast records the vote, replacing any earlier vote of the user on the same
+// perfume and dimension.
+func (service VoteService) Cast(vote *internal.Vote) error {
+	err := service.db.QueryRow(
+		context.Background(),
+		`
+		INSERT INTO perfume_votes (perfume_id, user_id, dimension, value, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (perfume_id, user_id, dimension)
+		DO UPDATE SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at
+		RETURNING created_at
+		`,
+		vote.PerfumeId,
+		vote.UserId,
+		vote.Dimension.String(),
+		vote.Value,
+		vote.CreatedAt,
+		vote.UpdatedAt,
+	).Scan(&vote.CreatedAt)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("database error: %w: %w", ErrPerfumeNotFound, pgErr)
+		}
+
+		return fmt.Errorf("cast vote error: %w", err)
+	}
+
+	return nil
+}
+
+func (service VoteService) Retract(perfumeId string, userId int, dimension internal.VoteDimension) error {
+	tag, err := service.db.Exec(
+		context.Background(),
+		`DELETE FROM perfume_votes WHERE perfume_id = $1 AND user_id = $2 AND dimension = $3`,
+		perfumeId,
+		userId,
+		dimension.String(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("retract vote error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s vote on perfume with public_id '%s'", ErrVoteNotFound, dimension, perfumeId)
+	}
+
+	return nil
+}
@@ -0,0 +1,334 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ej-agas/perfume-db/internal"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type WearService struct {
+	db DB
+}
+
+var (
+	ErrWearNotFound = fmt.Errorf("wear not found")
+)
+
+// mostWornLimit is how many perfumes the most worn ranking of the stats holds.
+const mostWornLimit = 10
+
+var wearColumns = []string{
+	"id",
+	"public_id",
+	"user_id",
+	"perfume_id",
+	"worn_on",
+	"occasion",
+	"weather",
+	"sprays",
+	"created_at",
+}
+
+func wearFields(wear *internal.Wear) []any {
+	return []any{
+		&wear.ID,
+		&wear.PublicId,
+		&wear.UserId,
+		&wear.PerfumeId,
+		&wear.WornOn,
+		&wear.Occasion,
+		&wear.Weather,
+		&wear.Sprays,
+		&wear.CreatedAt,
+	}
+}
+
+func (service WearService) Save(wear *internal.Wear) error {
+	err := service.db.QueryRow(
+		context.Background(),
+		`
+		INSERT INTO wears (public_id, user_id, perfume_id, worn_on, occasion, weather, sprays, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+		`,
+		wear.PublicId,
+		wear.UserId,
+		wear.PerfumeId,
+		wear.WornOn,
+		wear.Occasion,
+		wear.Weather,
+		wear.Sprays,
+		wear.CreatedAt,
+	).Scan(&wear.ID)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("database error: %w: %w", ErrPerfumeNotFound, pgErr)
+		}
+
+		return fmt.Errorf("save wear error: %w", err)
+	}
+
+	return nil
+}
+
+func (service WearService) Find(userId int, publicId string) (*internal.Wear, error) {
+	var wear internal.Wear
+
+	q := fmt.Sprintf(`SELECT %s FROM wears WHERE user_id = $1 AND public_id = $2`, columns("", wearColumns))
+
+	if err := service.db.QueryRow(context.Background(), q, userId, publicId).Scan(wearFields(&wear)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: wear with public_id '%s' not found", ErrWearNotFound, publicId)
+		}
+
+		return nil, err
+	}
+
+	return &wear, nil
+}
+
+func (service WearService) List(userId int, cursor, perPage int) ([]internal.Wear, error) {
+	if cursor <= 0 {
+		cursor = 0
+	}
+
+	q := fmt.Sprintf(
+		`SELECT %s FROM wears WHERE user_id = $1 AND id > $2 ORDER BY id LIMIT $3`,
+		columns("", wearColumns),
+	)
+
+	rows, err := service.db.Query(context.Background(), q, userId, cursor, perPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	wears := make([]internal.Wear, 0)
+	perfumeIds := make([]string, 0)
+	for rows.Next() {
+		var wear internal.Wear
+		if err := rows.Scan(wearFields(&wear)...); err != nil {
+			return nil, err
+		}
+
+		wears = append(wears, wear)
+		perfumeIds = append(perfumeIds, wear.PerfumeId)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	perfumes, err := PerfumeService{db: service.db}.byPublicId(perfumeIds)
+	if err != nil {
+		return nil, fmt.Errorf("load wear perfumes error: %w", err)
+	}
+
+	for i := range wears {
+		wears[i].Perfume = perfumes[wears[i].PerfumeId]
+	}
+
+	return wears, nil
+}
+
+func (service WearService) Delete(wear *internal.Wear) error {
+	tag, err := service.db.Exec(context.Background(), `DELETE FROM wears WHERE id = $1`, wear.ID)
+	if err != nil {
+		return fmt.Errorf("delete wear error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: wear with public_id '%s' not found", ErrWearNotFound, wear.PublicId)
+	}
+
+	return nil
+}
+
+// Stats computes the rotation statistics of the user from their wear log in
+// a single batched round trip. Unworn perfumes are the ones on the owned
+// shelf without any wear in the range.
+func (service WearService) Stats(userId int, filter internal.WearStatsFilter) (*internal.WearStats, error) {
+	args := []any{userId, nullIfZero(filter.From), nullIfZero(filter.To)}
+	inRange := `w.user_id = $1 AND ($2::date IS NULL OR w.worn_on >= $2) AND ($3::date IS NULL OR w.worn_on <= $3)`
+
+	batch := &pgx.Batch{}
+	batch.Queue(`SELECT COUNT(*) FROM wears w WHERE `+inRange, args...)
+	batch.Queue(
+		fmt.Sprintf(`
+			SELECT w.perfume_id, COUNT(*)
+			FROM wears w
+			WHERE %s
+			GROUP BY w.perfume_id
+			ORDER BY COUNT(*) DESC, MAX(w.worn_on) DESC
+			LIMIT %d
+		`, inRange, mostWornLimit),
+		args...,
+	)
+	batch.Queue(
+		fmt.Sprintf(`
+			SELECT DISTINCT c.perfume_id
+			FROM collection_items c
+			WHERE c.user_id = $1 AND c.shelf = 'owned'
+			AND NOT EXISTS (SELECT 1 FROM wears w WHERE w.perfume_id = c.perfume_id AND %s)
+			ORDER BY c.perfume_id
+		`, inRange),
+		args...,
+	)
+	batch.Queue(
+		fmt.Sprintf(`
+			SELECT to_char(w.worn_on, 'YYYY-MM'), COUNT(*)
+			FROM wears w
+			WHERE %s
+			GROUP BY 1
+			ORDER BY 1
+		`, inRange),
+		args...,
+	)
+	batch.Queue(
+		fmt.Sprintf(`
+			SELECT pn.category, COUNT(*)
+			FROM wears w
+			JOIN perfumes_notes pn ON pn.perfume_id = w.perfume_id
+			WHERE %s
+			GROUP BY pn.category
+		`, inRange),
+		args...,
+	)
+
+	results := service.db.SendBatch(context.Background(), batch)
+	defer results.Close()
+
+	stats := &internal.WearStats{
+		MostWorn: make([]internal.WearCount, 0),
+		Unworn:   make([]*internal.Perfume, 0),
+		ByMonth:  make([]internal.MonthlyWearCount, 0),
+	}
+
+	if err := results.QueryRow().Scan(&stats.TotalWears); err != nil {
+		return nil, fmt.Errorf("wear stats error: total wears: %w", err)
+	}
+
+	mostWornIds := make([]string, 0, mostWornLimit)
+	mostWornCounts := make(map[string]int, mostWornLimit)
+	err := scanEach(results, func(rows pgx.Rows) error {
+		var perfumeId string
+		var count int
+		if err := rows.Scan(&perfumeId, &count); err != nil {
+			return err
+		}
+
+		mostWornIds = append(mostWornIds, perfumeId)
+		mostWornCounts[perfumeId] = count
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wear stats error: most worn: %w", err)
+	}
+
+	unwornIds := make([]string, 0)
+	err = scanEach(results, func(rows pgx.Rows) error {
+		var perfumeId string
+		if err := rows.Scan(&perfumeId); err != nil {
+			return err
+		}
+
+		unwornIds = append(unwornIds, perfumeId)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wear stats error: unworn: %w", err)
+	}
+
+	err = scanEach(results, func(rows pgx.Rows) error {
+		var month internal.MonthlyWearCount
+		if err := rows.Scan(&month.Month, &month.Count); err != nil {
+			return err
+		}
+
+		stats.ByMonth = append(stats.ByMonth, month)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wear stats error: by month: %w", err)
+	}
+
+	counts := make(map[internal.NoteCategory]int)
+	err = scanEach(results, func(rows pgx.Rows) error {
+		var category *string
+		var count int
+		if err := rows.Scan(&category, &count); err != nil {
+			return err
+		}
+
+		noteCategory := internal.UncategorizedNote
+		if category != nil {
+			if parsed, err := internal.NoteCategoryFromString(*category); err == nil {
+				noteCategory = parsed
+			}
+		}
+
+		counts[noteCategory] += count
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wear stats error: note categories: %w", err)
+	}
+
+	if err := results.Close(); err != nil {
+		return nil, err
+	}
+
+	stats.NoteCategories = internal.NoteCategoryDistribution(counts)
+
+	perfumes, err := PerfumeService{db: service.db}.byPublicId(append(mostWornIds, unwornIds...))
+	if err != nil {
+		return nil, fmt.Errorf("wear stats error: %w", err)
+	}
+
+	for _, perfumeId := range mostWornIds {
+		if perfume, ok := perfumes[perfumeId]; ok {
+			stats.MostWorn = append(stats.MostWorn, internal.WearCount{Perfume: perfume, Count: mostWornCounts[perfumeId]})
+		}
+	}
+
+	for _, perfumeId := range unwornIds {
+		if perfume, ok := perfumes[perfumeId]; ok {
+			stats.Unworn = append(stats.Unworn, perfume)
+		}
+	}
+
+	return stats, nil
+}
+
+// scanEach reads the next result of a batch, calling scan for every row.
+func scanEach(results pgx.BatchResults, scan func(rows pgx.Rows) error) error {
+	rows, err := results.Query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func nullIfZero(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{Valid: false}
+	}
+
+	return sql.NullTime{Time: t, Valid: true}
+}